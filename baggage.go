@@ -0,0 +1,263 @@
+package opentracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// ErrInvalidBaggageKey 由 ValidateBaggageKey 在携带数据(baggage)的键不合法时返回（被包装的）的错误。
+var ErrInvalidBaggageKey = errors.New("opentracing: invalid baggage key")
+
+type baggageContextKey struct{}
+
+var baggageSnapshotKey = baggageContextKey{}
+
+// BaggageKeyNormalizer 把携带数据(baggage)的键转换为规范的形式。
+type BaggageKeyNormalizer func(key string) string
+
+var baggageKeyNormalizer atomic.Value // BaggageKeyNormalizer
+
+// SetBaggageKeyNormalizer 设置包级的携带数据(baggage)键的规范器，传播相关的帮助函数
+// （ContextWithBaggage、BaggageValue，以及 mocktracer 的 TextMap/HTTPHeaders 传播器）
+// 在读写携带数据的键时会统一通过 NormalizeBaggageKey 应用它。
+// 默认（或者 fn 为nil时）是恒等函数，即保持键的大小写不变。
+//
+// HTTP 头会丢失键的大小写（例如`UserID`经过 HTTPHeaders 传播之后可能变成`userid`），
+// 所以为了使跨格式传播的行为可预测，建议使用 strings.ToLower 把键统一为小写：
+//
+//    opentracing.SetBaggageKeyNormalizer(strings.ToLower)
+//
+func SetBaggageKeyNormalizer(fn BaggageKeyNormalizer) {
+	if fn == nil {
+		fn = func(key string) string { return key }
+	}
+	baggageKeyNormalizer.Store(fn)
+}
+
+// NormalizeBaggageKey 使用 SetBaggageKeyNormalizer 设置的规范器转换 key。
+// Tracer 的实现应该在传播携带数据时调用它。
+func NormalizeBaggageKey(key string) string {
+	if fn, ok := baggageKeyNormalizer.Load().(BaggageKeyNormalizer); ok {
+		return fn(key)
+	}
+	return key
+}
+
+// ContextWithBaggage 返回一个新的`context.Context`，它包含`sc`的携带数据(baggage)的一份快照。
+// 不依赖 opentracing Span 的代码（例如日志、限流中间件）可以通过 BaggageValue 读取其中的值。
+//
+// 快照是只读的：之后对 Span 的携带数据的修改不会反映到快照中。
+// 如果`sc`为空(nil)，快照中没有任何携带数据。
+func ContextWithBaggage(ctx context.Context, sc SpanContext) context.Context {
+	snapshot := make(map[string]string)
+	for k, v := range baggageMap(sc) {
+		snapshot[NormalizeBaggageKey(k)] = v
+	}
+	return context.WithValue(ctx, baggageSnapshotKey, snapshot)
+}
+
+// BaggageValue 返回之前通过 ContextWithBaggage 放入`ctx`中的携带数据中`key`对应的值，`key`会先经过 NormalizeBaggageKey 的转换。
+// 如果`ctx`中没有快照或快照中没有`key`，第二个返回值为 false。
+func BaggageValue(ctx context.Context, key string) (string, bool) {
+	m, _ := ctx.Value(baggageSnapshotKey).(map[string]string)
+	v, ok := m[NormalizeBaggageKey(key)]
+	return v, ok
+}
+
+// ExtractBaggageToMap 返回`ctx`中 Span 的携带数据(baggage)的一份拷贝，修改返回的 map 不会影响 Span。
+// 如果`ctx`中没有 Span，则返回通过 ContextWithBaggage 或 ContextWithBaggageMap 放入的快照的拷贝。
+// 返回值不会是nil。
+//
+// 它和 ContextWithBaggageMap 一起用于跨越 context 的边界传递携带数据，例如：
+//
+//     baggage := opentracing.ExtractBaggageToMap(ctx)
+//     go func() {
+//         span := opentracing.StartSpan("background")
+//         defer span.Finish()
+//         ctx := opentracing.ContextWithBaggageMap(
+//             opentracing.ContextWithSpan(context.Background(), span), baggage)
+//         ...
+//     }()
+//
+func ExtractBaggageToMap(ctx context.Context) map[string]string {
+	if span := SpanFromContext(ctx); span != nil {
+		return baggageMap(span.Context())
+	}
+	m := make(map[string]string)
+	snapshot, _ := ctx.Value(baggageSnapshotKey).(map[string]string)
+	for k, v := range snapshot {
+		m[k] = v
+	}
+	return m
+}
+
+// ContextWithBaggageMap 把`baggage`中的键值对重新附加到`ctx`上：如果`ctx`中有 Span，
+// 会对它调用 SetBaggageItem；同时还会把它们合并到`ctx`中的快照里，使 BaggageValue 也能读取到，
+// 即使`ctx`中没有 Span（例如 context.Background()）。
+func ContextWithBaggageMap(ctx context.Context, baggage map[string]string) context.Context {
+	if span := SpanFromContext(ctx); span != nil {
+		for k, v := range baggage {
+			span.SetBaggageItem(k, v)
+		}
+	}
+	old, _ := ctx.Value(baggageSnapshotKey).(map[string]string)
+	snapshot := make(map[string]string, len(old)+len(baggage))
+	for k, v := range old {
+		snapshot[k] = v
+	}
+	for k, v := range baggage {
+		snapshot[NormalizeBaggageKey(k)] = v
+	}
+	return context.WithValue(ctx, baggageSnapshotKey, snapshot)
+}
+
+// BaggageDiff 比较两个 SpanContext 的携带数据(baggage)，例如进入和离开某个服务时的 SpanContext。
+//
+// added 包含 after 中有而 before 中没有的键值对，removed 包含 before 中有而 after 中没有的键值对，
+// changed 包含两边都有但值不同的键，其值为 after 中的值。
+// 空(nil)的 SpanContext 被视为没有携带数据，返回的三个 map 都不会是nil。
+func BaggageDiff(before, after SpanContext) (added, removed, changed map[string]string) {
+	b, a := baggageMap(before), baggageMap(after)
+	added = make(map[string]string)
+	removed = make(map[string]string)
+	changed = make(map[string]string)
+	for k, av := range a {
+		bv, ok := b[k]
+		switch {
+		case !ok:
+			added[k] = av
+		case av != bv:
+			changed[k] = av
+		}
+	}
+	for k, bv := range b {
+		if _, ok := a[k]; !ok {
+			removed[k] = bv
+		}
+	}
+	return added, removed, changed
+}
+
+// baggageMap 通过 ForeachBaggageItem 把 sc 的携带数据收集到一个新的 map 中。
+func baggageMap(sc SpanContext) map[string]string {
+	m := make(map[string]string)
+	if sc == nil {
+		return m
+	}
+	sc.ForeachBaggageItem(func(k, v string) bool {
+		m[k] = v
+		return true
+	})
+	return m
+}
+
+// ValidateBaggageKey 按 HTTP header token（RFC 7230）的规则检查携带数据(baggage)的键，
+// 即只允许 ASCII 字母、数字和`!#$%&'*+-.^_|~`等少量符号，并且不能为空。
+// 不合法的键（例如含有空格或非 ASCII 字符）在某些传播格式下可能会被悄悄损坏。
+//
+// Tracer 的实现可以在 SetBaggageItem 中调用它，返回的错误包装了 ErrInvalidBaggageKey。
+func ValidateBaggageKey(key string) error {
+	if !isHTTPToken(key) {
+		return fmt.Errorf("%w: %q", ErrInvalidBaggageKey, key)
+	}
+	return nil
+}
+
+// SanitizeBaggageKey 把 key 中每一个不合法的字符（见 ValidateBaggageKey）替换为`-`。
+func SanitizeBaggageKey(key string) string {
+	return SanitizeBaggageKeyWith(key, '-')
+}
+
+// SanitizeBaggageKeyWith 把 key 中每一个不合法的字符（见 ValidateBaggageKey）替换为 replacement，
+// 多字节的字符（例如中文）只会被替换为一个 replacement。replacement 本身应该是一个合法的字符。
+// 空的 key 会原样返回。
+func SanitizeBaggageKeyWith(key string, replacement byte) string {
+	var b strings.Builder
+	b.Grow(len(key))
+	for _, r := range key {
+		if r < 0x80 && isTokenChar(byte(r)) {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte(replacement)
+		}
+	}
+	return b.String()
+}
+
+// Baggage 是 SpanContext 的携带数据(baggage)的一份不可变的只读快照，见 NewBaggage。
+// 它的零值表示没有携带数据。
+type Baggage struct {
+	items map[string]string
+}
+
+// NewBaggage 一次性地通过 ForeachBaggageItem 收集 sc 的携带数据并返回它的只读快照。
+// 空(nil)的 SpanContext 被视为没有携带数据。
+func NewBaggage(sc SpanContext) Baggage {
+	return Baggage{items: baggageMap(sc)}
+}
+
+// Get 返回`key`对应的值，如果不存在，第二个返回值为 false。
+func (b Baggage) Get(key string) (string, bool) {
+	v, ok := b.items[key]
+	return v, ok
+}
+
+// Len 返回携带数据的条数。
+func (b Baggage) Len() int {
+	return len(b.items)
+}
+
+// Range 以不确定的顺序对每一条携带数据调用`handler`，如果`handler`返回 false 则停止遍历。
+func (b Baggage) Range(handler func(k, v string) bool) {
+	for k, v := range b.items {
+		if !handler(k, v) {
+			return
+		}
+	}
+}
+
+// InjectFilteredBaggage 把 sc 中`allow(key)`为 true 的携带数据以`prefix+key`为键写入 carrier，
+// 其余的携带数据会被跳过。它可以配合 Tracer 自己的 Inject 使用，以强制只有允许列表中的键会被传播出去。
+// 空(nil)的 sc 不会写入任何内容。
+func InjectFilteredBaggage(carrier TextMapWriter, prefix string, sc SpanContext, allow func(key string) bool) {
+	if sc == nil {
+		return
+	}
+	sc.ForeachBaggageItem(func(k, v string) bool {
+		if allow(k) {
+			carrier.Set(prefix+k, v)
+		}
+		return true
+	})
+}
+
+// RestrictBaggageSpan 返回一个包装了 sp 的 Span，它的 SetBaggageItem 会静默地丢弃`allow(key)`为 false 的键，
+// 其他操作都委托给 sp。
+func RestrictBaggageSpan(sp Span, allow func(key string) bool) Span {
+	return &restrictedBaggageSpan{Span: sp, allow: allow}
+}
+
+type restrictedBaggageSpan struct {
+	Span
+	allow func(key string) bool
+}
+
+func (s *restrictedBaggageSpan) SetBaggageItem(restrictedKey, value string) Span {
+	if s.allow(restrictedKey) {
+		s.Span.SetBaggageItem(restrictedKey, value)
+	}
+	return s
+}
+
+func (s *restrictedBaggageSpan) SetOperationName(operationName string) Span {
+	s.Span.SetOperationName(operationName)
+	return s
+}
+
+func (s *restrictedBaggageSpan) SetTag(key string, value interface{}) Span {
+	s.Span.SetTag(key, value)
+	return s
+}
@@ -0,0 +1,208 @@
+package opentracing
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// baggageSpanContext 是一个只包含携带数据的 SpanContext 实现。
+type baggageSpanContext map[string]string
+
+func (c baggageSpanContext) ForeachBaggageItem(handler func(k, v string) bool) {
+	for k, v := range c {
+		if !handler(k, v) {
+			return
+		}
+	}
+}
+
+func TestBaggageDiff(t *testing.T) {
+	before := baggageSpanContext{"user_id": "1", "tenant": "a", "dropped": "x"}
+	after := baggageSpanContext{"user_id": "1", "tenant": "b", "region": "eu"}
+
+	added, removed, changed := BaggageDiff(before, after)
+	assert.Equal(t, map[string]string{"region": "eu"}, added)
+	assert.Equal(t, map[string]string{"dropped": "x"}, removed)
+	assert.Equal(t, map[string]string{"tenant": "b"}, changed)
+}
+
+func TestBaggageDiffEmpty(t *testing.T) {
+	items := baggageSpanContext{"user_id": "1"}
+	empty := map[string]string{}
+
+	added, removed, changed := BaggageDiff(nil, items)
+	assert.Equal(t, map[string]string{"user_id": "1"}, added)
+	assert.Equal(t, empty, removed)
+	assert.Equal(t, empty, changed)
+
+	added, removed, changed = BaggageDiff(items, noopSpanContext{})
+	assert.Equal(t, empty, added)
+	assert.Equal(t, map[string]string{"user_id": "1"}, removed)
+	assert.Equal(t, empty, changed)
+
+	added, removed, changed = BaggageDiff(nil, nil)
+	assert.Equal(t, empty, added)
+	assert.Equal(t, empty, removed)
+	assert.Equal(t, empty, changed)
+}
+
+func TestContextWithBaggage(t *testing.T) {
+	sc := baggageSpanContext{"user_id": "42"}
+	ctx := ContextWithBaggage(context.Background(), sc)
+
+	v, ok := BaggageValue(ctx, "user_id")
+	assert.True(t, ok)
+	assert.Equal(t, "42", v)
+
+	_, ok = BaggageValue(ctx, "tenant")
+	assert.False(t, ok)
+
+	// 快照不受之后的修改影响
+	sc["user_id"] = "43"
+	sc["tenant"] = "a"
+	v, _ = BaggageValue(ctx, "user_id")
+	assert.Equal(t, "42", v)
+	_, ok = BaggageValue(ctx, "tenant")
+	assert.False(t, ok)
+
+	_, ok = BaggageValue(context.Background(), "user_id")
+	assert.False(t, ok)
+	_, ok = BaggageValue(ContextWithBaggage(context.Background(), nil), "user_id")
+	assert.False(t, ok)
+}
+
+func TestExtractBaggageToMap(t *testing.T) {
+	span := &baggageRecordingSpan{baggage: map[string]string{"user_id": "42"}}
+	m := ExtractBaggageToMap(ContextWithSpan(context.Background(), span))
+	assert.Equal(t, map[string]string{"user_id": "42"}, m)
+	m["user_id"] = "43"
+	assert.Equal(t, "42", span.baggage["user_id"])
+
+	assert.Empty(t, ExtractBaggageToMap(context.Background()))
+	bg := ContextWithBaggage(context.Background(), baggageSpanContext{"tenant": "a"})
+	assert.Equal(t, map[string]string{"tenant": "a"}, ExtractBaggageToMap(bg))
+}
+
+func TestContextWithBaggageMap(t *testing.T) {
+	span := &baggageRecordingSpan{baggage: map[string]string{}}
+	ctx := ContextWithBaggage(ContextWithSpan(context.Background(), span), baggageSpanContext{"tenant": "a"})
+	ctx = ContextWithBaggageMap(ctx, map[string]string{"user_id": "42"})
+	assert.Equal(t, map[string]string{"user_id": "42"}, span.baggage)
+	for k, want := range map[string]string{"tenant": "a", "user_id": "42"} {
+		v, ok := BaggageValue(ctx, k)
+		assert.True(t, ok, k)
+		assert.Equal(t, want, v, k)
+	}
+
+	// 没有 Span 时只放入快照
+	ctx = ContextWithBaggageMap(context.Background(), map[string]string{"user_id": "42"})
+	v, _ := BaggageValue(ctx, "user_id")
+	assert.Equal(t, "42", v)
+	assert.Equal(t, map[string]string{"user_id": "42"}, ExtractBaggageToMap(ctx))
+}
+
+func TestValidateBaggageKey(t *testing.T) {
+	assert.NoError(t, ValidateBaggageKey("user-id_2.v~1"))
+
+	err := ValidateBaggageKey("user id")
+	assert.ErrorIs(t, err, ErrInvalidBaggageKey)
+	assert.Contains(t, err.Error(), `"user id"`)
+
+	assert.ErrorIs(t, ValidateBaggageKey("用户"), ErrInvalidBaggageKey)
+	assert.ErrorIs(t, ValidateBaggageKey(""), ErrInvalidBaggageKey)
+}
+
+func TestSanitizeBaggageKey(t *testing.T) {
+	assert.Equal(t, "user-id_2.v~1", SanitizeBaggageKey("user-id_2.v~1"))
+	assert.Equal(t, "user-id", SanitizeBaggageKey("user id"))
+	assert.Equal(t, "user--", SanitizeBaggageKey("user用户"))
+	assert.Equal(t, "a_b", SanitizeBaggageKeyWith("a b", '_'))
+	assert.NoError(t, ValidateBaggageKey(SanitizeBaggageKey("含 空格")))
+}
+
+func TestBaggageKeyNormalizer(t *testing.T) {
+	assert.Equal(t, "UserID", NormalizeBaggageKey("UserID"))
+
+	SetBaggageKeyNormalizer(strings.ToLower)
+	defer SetBaggageKeyNormalizer(nil)
+	assert.Equal(t, "userid", NormalizeBaggageKey("UserID"))
+
+	ctx := ContextWithBaggage(context.Background(), baggageSpanContext{"UserID": "42"})
+	for _, key := range []string{"UserID", "userid", "USERID"} {
+		v, ok := BaggageValue(ctx, key)
+		assert.True(t, ok, key)
+		assert.Equal(t, "42", v, key)
+	}
+}
+
+func TestNewBaggage(t *testing.T) {
+	sc := baggageSpanContext{"user_id": "42", "tenant": "a", "region": "eu"}
+	b := NewBaggage(sc)
+	assert.Equal(t, 3, b.Len())
+
+	v, ok := b.Get("tenant")
+	assert.True(t, ok)
+	assert.Equal(t, "a", v)
+	_, ok = b.Get("missing")
+	assert.False(t, ok)
+
+	seen := map[string]string{}
+	b.Range(func(k, v string) bool {
+		seen[k] = v
+		return true
+	})
+	assert.Equal(t, map[string]string(sc), seen)
+
+	calls := 0
+	b.Range(func(k, v string) bool {
+		calls++
+		return false
+	})
+	assert.Equal(t, 1, calls)
+
+	// 快照不受 SpanContext 之后的修改影响
+	sc["tenant"] = "b"
+	v, _ = b.Get("tenant")
+	assert.Equal(t, "a", v)
+
+	assert.Equal(t, 0, NewBaggage(nil).Len())
+	assert.Equal(t, 0, Baggage{}.Len())
+}
+
+func TestInjectFilteredBaggage(t *testing.T) {
+	allow := func(key string) bool { return key == "tenant" }
+	sc := baggageSpanContext{"tenant": "a", "secret": "s"}
+
+	carrier := TextMapCarrier{}
+	InjectFilteredBaggage(carrier, "ot-baggage-", sc, allow)
+	assert.Equal(t, TextMapCarrier{"ot-baggage-tenant": "a"}, carrier)
+
+	InjectFilteredBaggage(carrier, "ot-baggage-", nil, allow)
+	assert.Len(t, carrier, 1)
+}
+
+// baggageRecordingSpan 记录 SetBaggageItem 设置的携带数据
+type baggageRecordingSpan struct {
+	noopSpan
+	baggage map[string]string
+}
+
+func (s *baggageRecordingSpan) SetBaggageItem(key, val string) Span {
+	s.baggage[key] = val
+	return s
+}
+
+func (s *baggageRecordingSpan) Context() SpanContext {
+	return baggageSpanContext(s.baggage)
+}
+
+func TestRestrictBaggageSpan(t *testing.T) {
+	inner := &baggageRecordingSpan{baggage: map[string]string{}}
+	span := RestrictBaggageSpan(inner, func(key string) bool { return key == "tenant" })
+	assert.Equal(t, span, span.SetBaggageItem("tenant", "a").SetBaggageItem("secret", "s"))
+	assert.Equal(t, span, span.SetTag("k", "v").SetOperationName("op"))
+	assert.Equal(t, map[string]string{"tenant": "a"}, inner.baggage)
+}
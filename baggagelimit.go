@@ -0,0 +1,205 @@
+package opentracing
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/opentracing/opentracing-go/log"
+)
+
+var (
+	// ErrBaggageKeyNotAllowed 是 BaggageLimits 在携带数据(baggage)的键不在 AllowedKeys 中时报告的（被包装的）错误。
+	ErrBaggageKeyNotAllowed = errors.New("opentracing: baggage key not allowed")
+
+	// ErrBaggageTooLarge 是 BaggageLimits 在携带数据的键、值或总大小超出限制时报告的（被包装的）错误。
+	ErrBaggageTooLarge = errors.New("opentracing: baggage too large")
+)
+
+// BaggageRejectedEvent 是 BaggageLimits 默认在 Span 上记录的日志的 event 字段的值。
+const BaggageRejectedEvent = "baggage.rejected"
+
+// BaggageLimits 描述了对 SetBaggageItem 的约束，值为零的字段表示不做限制。
+//
+// 携带数据会被传播到下游的每一个请求中，BaggageLimits 可以防止它无限膨胀。
+type BaggageLimits struct {
+	// AllowedKeys 是允许设置的键的列表，为 nil 时允许所有的键。
+	AllowedKeys []string
+
+	// MaxKeyLength 是键的最大字节数。
+	MaxKeyLength int
+
+	// MaxValueLength 是值的最大字节数。
+	MaxValueLength int
+
+	// MaxTotalSize 是 Span 中所有携带数据的键和值的字节数之和的最大值。
+	MaxTotalSize int
+
+	// OnViolation 在 SetBaggageItem 被拒绝时调用，err 包装了 ErrBaggageKeyNotAllowed 或 ErrBaggageTooLarge。
+	// 为 nil 时会在 span 上记录一条日志：`event=baggage.rejected`、`baggage.key=<key>` 和 `error.object=<err>`。
+	OnViolation func(span Span, key, value string, err error)
+}
+
+// check 返回在 sc 的基础上设置 key=value 违反的限制，没有违反时返回 nil。
+func (l *BaggageLimits) check(sc SpanContext, key, value string) error {
+	if l.AllowedKeys != nil {
+		allowed := false
+		for _, k := range l.AllowedKeys {
+			if k == key {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("%w: %q", ErrBaggageKeyNotAllowed, key)
+		}
+	}
+	if l.MaxKeyLength > 0 && len(key) > l.MaxKeyLength {
+		return fmt.Errorf("%w: key %q is longer than %d bytes", ErrBaggageTooLarge, key, l.MaxKeyLength)
+	}
+	if l.MaxValueLength > 0 && len(value) > l.MaxValueLength {
+		return fmt.Errorf("%w: value of %q is longer than %d bytes", ErrBaggageTooLarge, key, l.MaxValueLength)
+	}
+	if l.MaxTotalSize > 0 {
+		total := len(key) + len(value)
+		if sc != nil {
+			sc.ForeachBaggageItem(func(k, v string) bool {
+				if k != key {
+					total += len(k) + len(v)
+				}
+				return true
+			})
+		}
+		if total > l.MaxTotalSize {
+			return fmt.Errorf("%w: total size %d exceeds %d bytes", ErrBaggageTooLarge, total, l.MaxTotalSize)
+		}
+	}
+	return nil
+}
+
+func (l *BaggageLimits) reject(sp Span, key, value string, err error) {
+	if l.OnViolation != nil {
+		l.OnViolation(sp, key, value, err)
+		return
+	}
+	sp.LogFields(log.String("event", BaggageRejectedEvent), log.String("baggage.key", key), log.Error(err))
+}
+
+// LimitBaggageSpan 返回一个包装了 sp 的 Span，它的 SetBaggageItem 在违反 limits 时不会设置携带数据，
+// 而是调用 limits.OnViolation（默认在 sp 上记录一条日志）。其他操作都委托给 sp。
+func LimitBaggageSpan(sp Span, limits BaggageLimits) Span {
+	return &limitedBaggageSpan{Span: sp, limits: &limits}
+}
+
+// WrapTracerWithBaggageLimits 返回一个包装了 tracer 的 Tracer，它创建的每一个 Span 都像 LimitBaggageSpan 一样
+// 受 limits 约束。从父 Span 继承或通过 Extract 得到的携带数据不受影响。
+//
+// StartSpan 选项中的携带数据（见 WithBaggage）会按键的顺序逐项检查，违反限制的项不会被传递给 tracer，
+// 而是像 SetBaggageItem 一样在新的 Span 上报告。
+//
+// Inject 和 Extract 会直接委托给 tracer。
+func WrapTracerWithBaggageLimits(tracer Tracer, limits BaggageLimits) Tracer {
+	return &baggageLimitTracer{tracer: tracer, limits: &limits}
+}
+
+type baggageLimitTracer struct {
+	tracer Tracer
+	limits *BaggageLimits
+}
+
+func (t *baggageLimitTracer) StartSpan(operationName string, opts ...StartSpanOption) Span {
+	sso := NewStartSpanOptions(opts...)
+	if sso.Baggage == nil {
+		return &limitedBaggageSpan{Span: t.tracer.StartSpan(operationName, appliedStartSpanOptions(sso)), limits: t.limits, tracer: t}
+	}
+
+	// 与 SetBaggageItem 一样，总大小包括从父级继承的携带数据
+	current := baggageItems{}
+	for _, ref := range sso.References {
+		if ref.ReferencedContext != nil {
+			ref.ReferencedContext.ForeachBaggageItem(func(k, v string) bool {
+				current[k] = v
+				return true
+			})
+		}
+	}
+	keys := make([]string, 0, len(sso.Baggage))
+	for k := range sso.Baggage {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	accepted := make(map[string]string, len(sso.Baggage))
+	var rejected []rejectedBaggageItem
+	for _, k := range keys {
+		v := sso.Baggage[k]
+		if err := t.limits.check(current, k, v); err != nil {
+			rejected = append(rejected, rejectedBaggageItem{key: k, value: v, err: err})
+			continue
+		}
+		current[k] = v
+		accepted[k] = v
+	}
+	sso.Baggage = accepted
+
+	span := &limitedBaggageSpan{Span: t.tracer.StartSpan(operationName, appliedStartSpanOptions(sso)), limits: t.limits, tracer: t}
+	for _, r := range rejected {
+		t.limits.reject(span, r.key, r.value, r.err)
+	}
+	return span
+}
+
+type rejectedBaggageItem struct {
+	key, value string
+	err        error
+}
+
+// baggageItems 是只包含携带数据的 SpanContext，用于在创建 Span 之前检查 StartSpan 选项中的携带数据。
+type baggageItems map[string]string
+
+func (b baggageItems) ForeachBaggageItem(handler func(k, v string) bool) {
+	for k, v := range b {
+		if !handler(k, v) {
+			return
+		}
+	}
+}
+
+func (t *baggageLimitTracer) Inject(sc SpanContext, format interface{}, carrier interface{}) error {
+	return t.tracer.Inject(sc, format, carrier)
+}
+
+func (t *baggageLimitTracer) Extract(format interface{}, carrier interface{}) (SpanContext, error) {
+	return t.tracer.Extract(format, carrier)
+}
+
+type limitedBaggageSpan struct {
+	Span
+	limits *BaggageLimits
+	tracer Tracer // 为 nil 时委托给 Span
+}
+
+func (s *limitedBaggageSpan) Tracer() Tracer {
+	if s.tracer != nil {
+		return s.tracer
+	}
+	return s.Span.Tracer()
+}
+
+func (s *limitedBaggageSpan) SetBaggageItem(restrictedKey, value string) Span {
+	if err := s.limits.check(s.Span.Context(), restrictedKey, value); err != nil {
+		s.limits.reject(s, restrictedKey, value, err)
+		return s
+	}
+	s.Span.SetBaggageItem(restrictedKey, value)
+	return s
+}
+
+func (s *limitedBaggageSpan) SetOperationName(operationName string) Span {
+	s.Span.SetOperationName(operationName)
+	return s
+}
+
+func (s *limitedBaggageSpan) SetTag(key string, value interface{}) Span {
+	s.Span.SetTag(key, value)
+	return s
+}
@@ -0,0 +1,92 @@
+package opentracing_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+func TestWrapTracerWithBaggageLimits(t *testing.T) {
+	inner := mocktracer.New()
+	tracer := opentracing.WrapTracerWithBaggageLimits(inner, opentracing.BaggageLimits{
+		AllowedKeys:    []string{"tenant", "user", "region"},
+		MaxValueLength: 8,
+		MaxTotalSize:   20,
+	})
+
+	span := tracer.StartSpan("op")
+	assert.Equal(t, tracer, span.Tracer())
+	assert.Equal(t, span, span.SetBaggageItem("tenant", "acme"))
+	span.SetBaggageItem("secret", "x")
+	span.SetBaggageItem("user", strings.Repeat("a", 9))
+	span.SetBaggageItem("user", "bob")
+	span.SetBaggageItem("region", "eu")
+	span.SetBaggageItem("tenant", "acme2")
+	span.Finish()
+
+	sp := inner.FinishedSpans()[0]
+	assert.Equal(t, map[string]string{"tenant": "acme2", "user": "bob"}, sp.SpanContext.Baggage)
+
+	logs := sp.Logs()
+	require.Len(t, logs, 3)
+	for i, key := range []string{"secret", "user", "region"} {
+		values := logValues(logs[i])
+		assert.Equal(t, opentracing.BaggageRejectedEvent, values["event"])
+		assert.Equal(t, key, values["baggage.key"])
+	}
+	assert.Contains(t, logValues(logs[0])["error.object"], "not allowed")
+}
+
+func TestLimitBaggageSpanOnViolation(t *testing.T) {
+	var errs []error
+	inner := mocktracer.New().StartSpan("op").(*mocktracer.MockSpan)
+	span := opentracing.LimitBaggageSpan(inner, opentracing.BaggageLimits{
+		MaxKeyLength: 3,
+		OnViolation: func(_ opentracing.Span, _, _ string, err error) {
+			errs = append(errs, err)
+		},
+	})
+
+	span.SetBaggageItem("abc", "v").SetBaggageItem("abcd", "v")
+	assert.Equal(t, "v", span.BaggageItem("abc"))
+	assert.Empty(t, span.BaggageItem("abcd"))
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], opentracing.ErrBaggageTooLarge)
+	assert.Empty(t, inner.Logs())
+}
+
+func TestWrapTracerWithBaggageLimitsStartBaggage(t *testing.T) {
+	inner := mocktracer.New()
+	tracer := opentracing.WrapTracerWithBaggageLimits(inner, opentracing.BaggageLimits{
+		AllowedKeys:  []string{"tenant", "user", "region"},
+		MaxTotalSize: 20,
+	})
+
+	parent := tracer.StartSpan("parent", opentracing.WithBaggage(map[string]string{"tenant": "acme"}))
+	span := tracer.StartSpan("op", opentracing.ChildOf(parent.Context()), opentracing.WithBaggage(map[string]string{
+		"secret": "x",
+		"region": "eu",
+		"user":   "bob",
+	}))
+	span.Finish()
+	parent.Finish()
+
+	sp := inner.FinishedSpans()[0]
+	assert.Equal(t, map[string]string{"tenant": "acme", "region": "eu"}, sp.SpanContext.Baggage)
+
+	logs := sp.Logs()
+	require.Len(t, logs, 2)
+	for i, key := range []string{"secret", "user"} {
+		values := logValues(logs[i])
+		assert.Equal(t, opentracing.BaggageRejectedEvent, values["event"])
+		assert.Equal(t, key, values["baggage.key"])
+	}
+	assert.Contains(t, logValues(logs[0])["error.object"], "not allowed")
+	assert.Contains(t, logValues(logs[1])["error.object"], "total size")
+	assert.Empty(t, inner.FinishedSpans()[1].Logs())
+}
@@ -0,0 +1,247 @@
+package basictracer
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+const (
+	fieldTraceID  = "ot-tracer-traceid"
+	fieldSpanID   = "ot-tracer-spanid"
+	fieldSampled  = "ot-tracer-sampled"
+	prefixBaggage = "ot-baggage-"
+)
+
+// injectTextMap writes the trace ID, span ID and sampling flag as hex and
+// boolean strings, and each baggage item under prefixBaggage. Baggage values
+// are URL-escaped in HTTP headers.
+func injectTextMap(ctx opentracing.BasicSpanContext, carrier interface{}, httpHeaders bool) error {
+	writer, ok := carrier.(opentracing.TextMapWriter)
+	if !ok {
+		return fmt.Errorf("%w: %T is not a TextMapWriter", opentracing.ErrInvalidCarrier, carrier)
+	}
+	writer.Set(fieldTraceID, ctx.TraceIDString())
+	writer.Set(fieldSpanID, ctx.SpanIDString())
+	writer.Set(fieldSampled, strconv.FormatBool(ctx.Sampled))
+	for k, v := range ctx.Baggage {
+		if httpHeaders {
+			v = url.QueryEscape(v)
+		}
+		writer.Set(prefixBaggage+opentracing.NormalizeBaggageKey(k), v)
+	}
+	return nil
+}
+
+func extractTextMap(carrier interface{}, httpHeaders bool) (opentracing.BasicSpanContext, error) {
+	reader, ok := carrier.(opentracing.TextMapReader)
+	if !ok {
+		return opentracing.BasicSpanContext{}, fmt.Errorf("%w: %T is not a TextMapReader", opentracing.ErrInvalidCarrier, carrier)
+	}
+	ctx := opentracing.BasicSpanContext{Sampled: true}
+	var fields int
+	err := reader.ForeachKey(func(key, val string) error {
+		lowerKey := strings.ToLower(key)
+		var err error
+		switch {
+		case lowerKey == fieldTraceID:
+			ctx.TraceIDHigh, ctx.TraceID, err = parseTraceID(val)
+			fields++
+		case lowerKey == fieldSpanID:
+			ctx.SpanID, err = strconv.ParseUint(val, 16, 64)
+			fields++
+		case lowerKey == fieldSampled:
+			ctx.Sampled, err = strconv.ParseBool(val)
+		case strings.HasPrefix(lowerKey, prefixBaggage):
+			baggageKey := key[len(prefixBaggage):]
+			if httpHeaders {
+				// HTTP headers do not preserve the case of the key
+				baggageKey = lowerKey[len(prefixBaggage):]
+				if val, err = url.QueryUnescape(val); err != nil {
+					break
+				}
+			}
+			if ctx.Baggage == nil {
+				ctx.Baggage = make(map[string]string)
+			}
+			ctx.Baggage[opentracing.NormalizeBaggageKey(baggageKey)] = val
+		}
+		if err != nil {
+			return fmt.Errorf("%w: key %q: %v", opentracing.ErrSpanContextCorrupted, key, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return opentracing.BasicSpanContext{}, err
+	}
+	switch fields {
+	case 0:
+		return opentracing.BasicSpanContext{}, opentracing.ErrSpanContextNotFound
+	case 1:
+		return opentracing.BasicSpanContext{}, fmt.Errorf("%w: both %s and %s are required", opentracing.ErrSpanContextCorrupted, fieldTraceID, fieldSpanID)
+	}
+	return ctx, nil
+}
+
+// parseTraceID parses a trace ID of up to 16 (64-bit) or 32 (128-bit) hex
+// digits.
+func parseTraceID(s string) (high, low uint64, err error) {
+	if len(s) > 16 {
+		if len(s) > 32 {
+			return 0, 0, errors.New("trace ID longer than 128 bits")
+		}
+		if high, err = strconv.ParseUint(s[:len(s)-16], 16, 64); err != nil {
+			return 0, 0, err
+		}
+		s = s[len(s)-16:]
+	}
+	low, err = strconv.ParseUint(s, 16, 64)
+	return high, low, err
+}
+
+// The Binary format is, in big endian byte order:
+//
+//     version (1 byte, binaryVersion)
+//     flags (1 byte, binaryFlagSampled | binaryFlag128Bit)
+//     trace ID high (8 bytes, only with binaryFlag128Bit)
+//     trace ID (8 bytes)
+//     span ID (8 bytes)
+//     number of baggage items (4 bytes)
+//     for each item: key length (4 bytes), key, value length (4 bytes), value
+//
+const (
+	binaryVersion     = 1
+	binaryFlagSampled = 1 << 0
+	binaryFlag128Bit  = 1 << 1
+
+	// maxBinaryBaggageLen bounds the lengths read from the carrier, so that a
+	// corrupted carrier cannot make Extract allocate huge buffers.
+	maxBinaryBaggageLen = 1 << 20
+)
+
+func injectBinary(ctx opentracing.BasicSpanContext, carrier interface{}) error {
+	w, ok := carrier.(io.Writer)
+	if !ok {
+		return fmt.Errorf("%w: %T is not an io.Writer", opentracing.ErrInvalidCarrier, carrier)
+	}
+	buf := []byte{binaryVersion, 0}
+	if ctx.Sampled {
+		buf[1] |= binaryFlagSampled
+	}
+	if ctx.TraceIDHigh != 0 {
+		buf[1] |= binaryFlag128Bit
+		buf = appendUint64(buf, ctx.TraceIDHigh)
+	}
+	buf = appendUint64(buf, ctx.TraceID)
+	buf = appendUint64(buf, ctx.SpanID)
+	buf = appendUint32(buf, uint32(len(ctx.Baggage)))
+	for k, v := range ctx.Baggage {
+		k = opentracing.NormalizeBaggageKey(k)
+		buf = append(appendUint32(buf, uint32(len(k))), k...)
+		buf = append(appendUint32(buf, uint32(len(v))), v...)
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+func extractBinary(carrier interface{}) (opentracing.BasicSpanContext, error) {
+	r, ok := carrier.(io.Reader)
+	if !ok {
+		return opentracing.BasicSpanContext{}, fmt.Errorf("%w: %T is not an io.Reader", opentracing.ErrInvalidCarrier, carrier)
+	}
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if err == io.EOF {
+			return opentracing.BasicSpanContext{}, opentracing.ErrSpanContextNotFound
+		}
+		return opentracing.BasicSpanContext{}, corrupted(err)
+	}
+	if header[0] != binaryVersion {
+		return opentracing.BasicSpanContext{}, corrupted(fmt.Errorf("unknown version %d", header[0]))
+	}
+	ctx := opentracing.BasicSpanContext{Sampled: header[1]&binaryFlagSampled != 0}
+	var err error
+	if header[1]&binaryFlag128Bit != 0 {
+		if ctx.TraceIDHigh, err = readUint64(r); err != nil {
+			return opentracing.BasicSpanContext{}, corrupted(err)
+		}
+	}
+	if ctx.TraceID, err = readUint64(r); err != nil {
+		return opentracing.BasicSpanContext{}, corrupted(err)
+	}
+	if ctx.SpanID, err = readUint64(r); err != nil {
+		return opentracing.BasicSpanContext{}, corrupted(err)
+	}
+	n, err := readUint32(r)
+	if err != nil {
+		return opentracing.BasicSpanContext{}, corrupted(err)
+	}
+	for i := uint32(0); i < n; i++ {
+		k, err := readString(r)
+		if err != nil {
+			return opentracing.BasicSpanContext{}, corrupted(err)
+		}
+		v, err := readString(r)
+		if err != nil {
+			return opentracing.BasicSpanContext{}, corrupted(err)
+		}
+		if ctx.Baggage == nil {
+			ctx.Baggage = make(map[string]string)
+		}
+		ctx.Baggage[opentracing.NormalizeBaggageKey(k)] = v
+	}
+	return ctx, nil
+}
+
+func corrupted(err error) error {
+	return fmt.Errorf("%w: %v", opentracing.ErrSpanContextCorrupted, err)
+}
+
+func appendUint64(buf []byte, v uint64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	return append(buf, b[:]...)
+}
+
+func appendUint32(buf []byte, v uint32) []byte {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], v)
+	return append(buf, b[:]...)
+}
+
+func readUint64(r io.Reader) (uint64, error) {
+	var b [8]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(b[:]), nil
+}
+
+func readUint32(r io.Reader) (uint32, error) {
+	var b [4]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(b[:]), nil
+}
+
+func readString(r io.Reader) (string, error) {
+	n, err := readUint32(r)
+	if err != nil {
+		return "", err
+	}
+	if n > maxBinaryBaggageLen {
+		return "", fmt.Errorf("baggage item of %d bytes is too long", n)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
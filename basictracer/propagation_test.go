@@ -0,0 +1,89 @@
+package basictracer
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+func TestPropagationRoundTrip(t *testing.T) {
+	tracer := NewWithOptions(Options{TraceID128Bit: true})
+	span := tracer.StartSpan("op", opentracing.SamplingPriority(0))
+	span.SetBaggageItem("tenant", "acme corp")
+	want := span.Context().(opentracing.BasicSpanContext)
+
+	for _, tc := range []struct {
+		format  opentracing.BuiltinFormat
+		carrier interface{}
+	}{
+		{opentracing.TextMap, opentracing.TextMapCarrier{}},
+		{opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(http.Header{})},
+		{opentracing.Binary, new(bytes.Buffer)},
+	} {
+		require.NoError(t, tracer.Inject(want, tc.format, tc.carrier), tc.format)
+		got, err := tracer.Extract(tc.format, tc.carrier)
+		require.NoError(t, err, tc.format)
+		assert.Equal(t, want, got, tc.format)
+	}
+}
+
+func TestPropagationErrors(t *testing.T) {
+	tracer := New(nil)
+	sc := tracer.StartSpan("op").Context()
+
+	assert.ErrorIs(t, tracer.Inject(opentracing.BasicSpanContext{TraceID: 1}, opentracing.TextMap, opentracing.TextMapCarrier{}), opentracing.ErrInvalidSpanContext)
+	assert.ErrorIs(t, tracer.Inject(otherSpanContext{}, opentracing.TextMap, opentracing.TextMapCarrier{}), opentracing.ErrInvalidSpanContext)
+	assert.False(t, opentracing.CanInject(tracer, opentracing.BasicSpanContext{}))
+	assert.False(t, opentracing.CanInject(tracer, otherSpanContext{}))
+	assert.True(t, opentracing.CanInject(tracer, sc))
+	assert.ErrorIs(t, tracer.Inject(sc, opentracing.TextMap, "carrier"), opentracing.ErrInvalidCarrier)
+	assert.ErrorIs(t, tracer.Inject(sc, opentracing.Binary, "carrier"), opentracing.ErrInvalidCarrier)
+	assert.Equal(t, opentracing.ErrUnsupportedFormat, tracer.Inject(sc, "custom", nil))
+
+	_, err := tracer.Extract(opentracing.TextMap, opentracing.TextMapCarrier{})
+	assert.Equal(t, opentracing.ErrSpanContextNotFound, err)
+	_, err = tracer.Extract(opentracing.Binary, new(bytes.Buffer))
+	assert.Equal(t, opentracing.ErrSpanContextNotFound, err)
+
+	for name, carrier := range map[string]opentracing.TextMapCarrier{
+		"bad trace id": {fieldTraceID: "xyz", fieldSpanID: "1"},
+		"missing span": {fieldTraceID: "1"},
+		"too long":     {fieldTraceID: "1234567890abcdef1234567890abcdef0", fieldSpanID: "1"},
+		"bad sampled":  {fieldTraceID: "1", fieldSpanID: "1", fieldSampled: "maybe"},
+	} {
+		_, err := tracer.Extract(opentracing.TextMap, carrier)
+		assert.ErrorIs(t, err, opentracing.ErrSpanContextCorrupted, name)
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, tracer.Inject(sc, opentracing.Binary, &buf))
+	_, err = tracer.Extract(opentracing.Binary, bytes.NewReader(buf.Bytes()[:buf.Len()-1]))
+	assert.ErrorIs(t, err, opentracing.ErrSpanContextCorrupted)
+	_, err = tracer.Extract(opentracing.Binary, bytes.NewReader([]byte{9, 0}))
+	assert.ErrorIs(t, err, opentracing.ErrSpanContextCorrupted)
+}
+
+type otherSpanContext struct{}
+
+func (otherSpanContext) ForeachBaggageItem(func(k, v string) bool) {}
+
+func TestPropagationBasicSpanContext(t *testing.T) {
+	// contexts decoded by other codecs, e.g. b3.Context.ToBasic, continue the trace
+	tracer := New(nil)
+	upstream := opentracing.BasicSpanContext{TraceID: 1, TraceIDHigh: 2, SpanID: 3, Sampled: true}.WithBaggageItem("k", "v")
+	carrier := opentracing.TextMapCarrier{}
+	require.NoError(t, tracer.Inject(upstream, opentracing.TextMap, carrier))
+	got, err := tracer.Extract(opentracing.TextMap, carrier)
+	require.NoError(t, err)
+	assert.Equal(t, upstream, got)
+
+	child := tracer.StartSpan("child", opentracing.ChildOf(upstream)).Context().(opentracing.BasicSpanContext)
+	assert.Equal(t, upstream.TraceID, child.TraceID)
+	assert.Equal(t, upstream.TraceIDHigh, child.TraceIDHigh)
+	assert.Equal(t, "v", child.Baggage["k"])
+}
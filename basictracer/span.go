@@ -0,0 +1,185 @@
+package basictracer
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/opentracing/opentracing-go/recorder"
+)
+
+// spanImpl is the opentracing.Span of basictracer. Tags and logs of
+// unsampled spans are not kept, and unsampled spans are not recorded.
+type spanImpl struct {
+	tracer      *tracerImpl
+	resolveName func(current string) string
+
+	mu          sync.Mutex
+	ctx         opentracing.BasicSpanContext
+	raw         recorder.RawSpan
+	droppedLogs int64
+	finished    bool
+}
+
+// Context implements opentracing.Span.
+func (s *spanImpl) Context() opentracing.SpanContext {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ctx
+}
+
+// Tracer implements opentracing.Span.
+func (s *spanImpl) Tracer() opentracing.Tracer {
+	return s.tracer
+}
+
+// SetOperationName implements opentracing.Span.
+func (s *spanImpl) SetOperationName(operationName string) opentracing.Span {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.raw.Operation = operationName
+	return s
+}
+
+// SetTag implements opentracing.Span. Setting the sampling.priority tag to
+// a uint16 changes the sampling decision of the span instead.
+func (s *spanImpl) SetTag(key string, value interface{}) opentracing.Span {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if key == string(ext.SamplingPriority) {
+		if priority, ok := value.(uint16); ok {
+			s.ctx.Sampled = priority > 0
+			return s
+		}
+	}
+	if !s.ctx.Sampled {
+		return s
+	}
+	if s.raw.Tags == nil {
+		s.raw.Tags = opentracing.Tags{}
+	}
+	s.raw.Tags[key] = value
+	return s
+}
+
+// SetBaggageItem implements opentracing.Span.
+func (s *spanImpl) SetBaggageItem(restrictedKey, value string) opentracing.Span {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ctx = s.ctx.WithBaggageItem(restrictedKey, value)
+	return s
+}
+
+// BaggageItem implements opentracing.Span.
+func (s *spanImpl) BaggageItem(restrictedKey string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ctx.Baggage[restrictedKey]
+}
+
+// LogFields implements opentracing.Span.
+func (s *spanImpl) LogFields(fields ...log.Field) {
+	s.appendLog(opentracing.LogRecord{Timestamp: time.Now(), Fields: fields})
+}
+
+// LogKV implements opentracing.Span.
+func (s *spanImpl) LogKV(alternatingKeyValues ...interface{}) {
+	fields, err := log.InterleavedKVToFields(alternatingKeyValues...)
+	if err != nil {
+		s.LogFields(log.Error(err), log.String("function", "LogKV"))
+		return
+	}
+	s.LogFields(fields...)
+}
+
+// LogEvent implements the deprecated opentracing.Span method.
+func (s *spanImpl) LogEvent(event string) {
+	s.Log(opentracing.LogData{Event: event})
+}
+
+// LogEventWithPayload implements the deprecated opentracing.Span method.
+func (s *spanImpl) LogEventWithPayload(event string, payload interface{}) {
+	s.Log(opentracing.LogData{Event: event, Payload: payload})
+}
+
+// Log implements the deprecated opentracing.Span method.
+func (s *spanImpl) Log(data opentracing.LogData) {
+	s.appendLog(data.ToLogRecord())
+}
+
+func (s *spanImpl) appendLog(lr opentracing.LogRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.appendLogLocked(lr)
+}
+
+// appendLogLocked must be called with s.mu held.
+func (s *spanImpl) appendLogLocked(lr opentracing.LogRecord) {
+	if !s.ctx.Sampled {
+		return
+	}
+	if max := s.tracer.options.MaxLogsPerSpan; max > 0 && len(s.raw.Logs) >= max {
+		s.droppedLogs++
+		return
+	}
+	s.raw.Logs = append(s.raw.Logs, lr)
+}
+
+// Finish implements opentracing.Span.
+func (s *spanImpl) Finish() {
+	s.FinishWithOptions(opentracing.FinishOptions{})
+}
+
+// FinishWithOptions implements opentracing.Span. Only the first call
+// records the span.
+func (s *spanImpl) FinishWithOptions(opts opentracing.FinishOptions) {
+	finishTime := opts.FinishTime
+	if finishTime.IsZero() {
+		finishTime = time.Now()
+	}
+
+	s.mu.Lock()
+	if s.finished {
+		s.mu.Unlock()
+		return
+	}
+	s.finished = true
+	if s.resolveName != nil {
+		// The callback is user code, so call it without holding the lock.
+		// finished is already set, so no other Finish gets past this point.
+		name := s.raw.Operation
+		s.mu.Unlock()
+		name = s.resolveName(name)
+		s.mu.Lock()
+		s.raw.Operation = name
+	}
+	opts.MigrateBulkLogData()
+	for _, lr := range opts.LogRecords {
+		s.appendLogLocked(lr)
+	}
+	if s.droppedLogs > 0 {
+		if s.raw.Tags == nil {
+			s.raw.Tags = opentracing.Tags{}
+		}
+		s.raw.Tags[opentracing.LogsDroppedTagKey] = s.droppedLogs
+	}
+	s.raw.Duration = finishTime.Sub(s.raw.Start)
+	s.raw.Context = s.ctx
+	raw := s.raw
+	s.mu.Unlock()
+
+	if raw.Context.Sampled && s.tracer.options.Recorder != nil {
+		s.tracer.options.Recorder.RecordSpan(raw)
+	}
+}
+
+// String returns the IDs and operation name of the span, for debugging.
+func (s *spanImpl) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fmt.Sprintf("traceId=%s, spanId=%s, parentId=%016x, sampled=%t, name=%s",
+		s.ctx.TraceIDString(), s.ctx.SpanIDString(), s.raw.ParentSpanID, s.ctx.Sampled, s.raw.Operation)
+}
@@ -0,0 +1,248 @@
+// Package basictracer is a complete reference implementation of the
+// opentracing.Tracer interface. It records the finished sampled spans as
+// recorder.RawSpan values to a pluggable recorder.SpanRecorder, which does
+// the actual exporting:
+//
+//     rec := recorder.NewChannelRecorder(1024)
+//     tracer := basictracer.NewWithOptions(basictracer.Options{
+//         Recorder:     rec,
+//         ShouldSample: basictracer.ProbabilisticSampler(0.1),
+//     })
+//     opentracing.SetGlobalTracer(tracer)
+//
+// It supports 64-bit and 128-bit trace IDs, sampling (including the
+// sampling.priority tag), baggage, and the TextMap, HTTPHeaders and Binary
+// propagation formats. It is meant to be read as much as used: every part of
+// the opentracing API a tracer has to implement is in here.
+package basictracer
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/recorder"
+)
+
+// Options configures a tracer created by NewWithOptions. The zero value
+// samples every trace with 64-bit trace IDs and discards the spans.
+type Options struct {
+	// Recorder receives every finished sampled span. If nil, spans are
+	// discarded.
+	Recorder recorder.SpanRecorder
+
+	// ShouldSample decides whether a new trace is sampled, given its (low
+	// 64-bit) trace ID. Spans continuing a trace inherit the decision of
+	// their parent. If nil, every trace is sampled. See ProbabilisticSampler.
+	ShouldSample func(traceID uint64) bool
+
+	// TraceID128Bit makes new traces use 128-bit trace IDs.
+	TraceID128Bit bool
+
+	// MaxLogsPerSpan, if positive, limits the number of log records kept per
+	// span. Further records are dropped and counted in the logs.dropped tag
+	// (opentracing.LogsDroppedTagKey).
+	MaxLogsPerSpan int
+}
+
+// ProbabilisticSampler returns an Options.ShouldSample function sampling
+// about rate (between 0 and 1) of all traces. The decision only depends on
+// the trace ID, so all tracers using the same rate agree on it.
+func ProbabilisticSampler(rate float64) func(traceID uint64) bool {
+	switch {
+	case rate >= 1:
+		return func(uint64) bool { return true }
+	case rate <= 0:
+		return func(uint64) bool { return false }
+	}
+	boundary := uint64(rate * math.Exp2(64))
+	return func(traceID uint64) bool {
+		return traceID < boundary
+	}
+}
+
+// New returns a tracer sampling every trace and recording the spans to rec.
+func New(rec recorder.SpanRecorder) opentracing.Tracer {
+	return NewWithOptions(Options{Recorder: rec})
+}
+
+// NewWithOptions returns a tracer configured by opts.
+func NewWithOptions(opts Options) opentracing.Tracer {
+	return &tracerImpl{options: opts, ids: newIDGenerator()}
+}
+
+type tracerImpl struct {
+	options Options
+	ids     *idGenerator
+}
+
+// StartSpan implements opentracing.Tracer.
+func (t *tracerImpl) StartSpan(operationName string, opts ...opentracing.StartSpanOption) opentracing.Span {
+	sso := opentracing.NewStartSpanOptions(opts...)
+
+	var ctx opentracing.BasicSpanContext
+	var parentSpanID uint64
+	if parent, ok := parentContext(sso.References); ok {
+		ctx.TraceIDHigh, ctx.TraceID = parent.TraceIDHigh, parent.TraceID
+		ctx.Sampled = parent.Sampled
+		parentSpanID = parent.SpanID
+	} else {
+		if t.options.TraceID128Bit {
+			ctx.TraceIDHigh = t.ids.next()
+		}
+		ctx.TraceID = t.ids.next()
+		ctx.Sampled = t.options.ShouldSample == nil || t.options.ShouldSample(ctx.TraceID)
+	}
+	ctx.SpanID = t.ids.next()
+	if priority, ok := sso.SamplingPriority(); ok {
+		ctx.Sampled = priority > 0
+		delete(sso.TagsMap(), opentracing.SamplingPriorityTagKey)
+	}
+	ctx.Baggage = startBaggage(sso)
+
+	startTime := sso.StartTime
+	if startTime.IsZero() {
+		startTime = time.Now()
+	}
+	sp := &spanImpl{
+		tracer:      t,
+		ctx:         ctx,
+		resolveName: sso.OperationNameCallback,
+		raw: recorder.RawSpan{
+			ParentSpanID: parentSpanID,
+			Operation:    operationName,
+			Start:        startTime,
+			References:   sso.References,
+		},
+	}
+	if ctx.Sampled {
+		sp.raw.Tags = sso.TagsMap()
+	}
+	return sp
+}
+
+// parentContext returns the context the new span continues the trace of:
+// the first ChildOf reference or, failing that, the first FollowsFrom
+// reference. References to other tracers' contexts, and BasicSpanContexts
+// without IDs, are ignored.
+func parentContext(refs []opentracing.SpanReference) (opentracing.BasicSpanContext, bool) {
+	var followsFrom *opentracing.BasicSpanContext
+	for _, ref := range refs {
+		sc, ok := ref.ReferencedContext.(opentracing.BasicSpanContext)
+		if !ok || !hasIDs(sc) {
+			continue
+		}
+		if ref.Type == opentracing.ChildOfRef {
+			return sc, true
+		}
+		if followsFrom == nil {
+			followsFrom = &sc
+		}
+	}
+	if followsFrom != nil {
+		return *followsFrom, true
+	}
+	return opentracing.BasicSpanContext{}, false
+}
+
+// startBaggage merges the baggage of all referenced contexts and the
+// initial baggage of the options, or returns nil if there is none.
+func startBaggage(sso opentracing.StartSpanOptions) map[string]string {
+	var baggage map[string]string
+	set := func(k, v string) bool {
+		if baggage == nil {
+			baggage = make(map[string]string)
+		}
+		baggage[k] = v
+		return true
+	}
+	for _, ref := range sso.References {
+		ref.ReferencedContext.ForeachBaggageItem(set)
+	}
+	for k, v := range sso.Baggage {
+		set(k, v)
+	}
+	return baggage
+}
+
+// Inject implements opentracing.Tracer.
+func (t *tracerImpl) Inject(sc opentracing.SpanContext, format interface{}, carrier interface{}) error {
+	ctx, ok := sc.(opentracing.BasicSpanContext)
+	if !ok {
+		return fmt.Errorf("%w: %T is not an opentracing.BasicSpanContext", opentracing.ErrInvalidSpanContext, sc)
+	}
+	if !hasIDs(ctx) {
+		return fmt.Errorf("%w: trace id and span id must not be zero", opentracing.ErrInvalidSpanContext)
+	}
+	switch format {
+	case opentracing.TextMap:
+		return injectTextMap(ctx, carrier, false)
+	case opentracing.HTTPHeaders:
+		return injectTextMap(ctx, carrier, true)
+	case opentracing.Binary:
+		return injectBinary(ctx, carrier)
+	}
+	return opentracing.ErrUnsupportedFormat
+}
+
+// Extract implements opentracing.Tracer.
+func (t *tracerImpl) Extract(format interface{}, carrier interface{}) (opentracing.SpanContext, error) {
+	var ctx opentracing.BasicSpanContext
+	var err error
+	switch format {
+	case opentracing.TextMap:
+		ctx, err = extractTextMap(carrier, false)
+	case opentracing.HTTPHeaders:
+		ctx, err = extractTextMap(carrier, true)
+	case opentracing.Binary:
+		ctx, err = extractBinary(carrier)
+	default:
+		return nil, opentracing.ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ctx, nil
+}
+
+// ValidSpanContext implements opentracing.SpanContextValidator: only
+// opentracing.BasicSpanContexts with a trace ID and a span ID can be
+// injected.
+func (t *tracerImpl) ValidSpanContext(sc opentracing.SpanContext) bool {
+	ctx, ok := sc.(opentracing.BasicSpanContext)
+	return ok && hasIDs(ctx)
+}
+
+func hasIDs(ctx opentracing.BasicSpanContext) bool {
+	return (ctx.TraceIDHigh != 0 || ctx.TraceID != 0) && ctx.SpanID != 0
+}
+
+// idGenerator generates random non-zero IDs. math/rand sources are not safe
+// for concurrent use, hence the mutex.
+type idGenerator struct {
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+func newIDGenerator() *idGenerator {
+	var seed int64
+	if err := binary.Read(crand.Reader, binary.LittleEndian, &seed); err != nil {
+		seed = time.Now().UnixNano()
+	}
+	return &idGenerator{rnd: rand.New(rand.NewSource(seed))}
+}
+
+func (g *idGenerator) next() uint64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for {
+		if id := g.rnd.Uint64(); id != 0 {
+			return id
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package basictracer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/harness"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/opentracing/opentracing-go/recorder"
+)
+
+type probe struct{}
+
+func (probe) SameTrace(first, second opentracing.Span) bool {
+	a, b := first.Context().(opentracing.BasicSpanContext), second.Context().(opentracing.BasicSpanContext)
+	return a.TraceIDHigh == b.TraceIDHigh && a.TraceID == b.TraceID
+}
+
+func (probe) SameSpanContext(span opentracing.Span, sc opentracing.SpanContext) bool {
+	a, b := span.Context().(opentracing.BasicSpanContext), sc.(opentracing.BasicSpanContext)
+	return a.TraceIDHigh == b.TraceIDHigh && a.TraceID == b.TraceID && a.SpanID == b.SpanID
+}
+
+func TestAPIChecks(t *testing.T) {
+	harness.RunAPIChecks(t, func() (opentracing.Tracer, func()) {
+		return New(recorder.NewInMemoryRecorder()), nil
+	},
+		harness.CheckEverything(),
+		harness.UseProbe(probe{}),
+	)
+}
+
+func TestPropagationHarness(t *testing.T) {
+	harness.RunPropagationTest(t, New(nil), harness.UseProbe(probe{}))
+	harness.RunPropagationTest(t, NewWithOptions(Options{TraceID128Bit: true}), harness.UseProbe(probe{}))
+}
+
+func TestRecordedSpan(t *testing.T) {
+	rec := recorder.NewInMemoryRecorder()
+	tracer := New(rec)
+	start := time.Now().Add(-time.Second)
+
+	parent := tracer.StartSpan("parent", opentracing.WithBaggage(map[string]string{"tenant": "acme"}))
+	child := tracer.StartSpan("child",
+		opentracing.ChildOf(parent.Context()),
+		opentracing.StartTime(start),
+		opentracing.Tag{Key: "k", Value: "v"})
+	child.SetTag("n", 1)
+	child.LogKV("event", "work")
+	child.SetOperationName("renamed")
+	child.FinishWithOptions(opentracing.FinishOptions{FinishTime: start.Add(time.Millisecond)})
+	child.Finish() // only the first Finish records the span
+	parent.Finish()
+
+	spans := rec.GetSpans()
+	require.Len(t, spans, 2)
+	raw := spans[0]
+	parentCtx := parent.Context().(opentracing.BasicSpanContext)
+	assert.Equal(t, "renamed", raw.Operation)
+	assert.Equal(t, parentCtx.TraceID, raw.Context.TraceID)
+	assert.Equal(t, parentCtx.SpanID, raw.ParentSpanID)
+	assert.Equal(t, start, raw.Start)
+	assert.Equal(t, time.Millisecond, raw.Duration)
+	assert.Equal(t, opentracing.Tags{"k": "v", "n": 1}, raw.Tags)
+	assert.Equal(t, map[string]string{"tenant": "acme"}, raw.Context.Baggage)
+	if assert.Len(t, raw.Logs, 1) {
+		assert.Equal(t, log.String("event", "work"), raw.Logs[0].Fields[0])
+	}
+	if assert.Len(t, raw.References, 1) {
+		assert.Equal(t, opentracing.ChildOfRef, raw.References[0].Type)
+	}
+	assert.Equal(t, uint64(0), spans[1].ParentSpanID)
+}
+
+func TestSampling(t *testing.T) {
+	rec := recorder.NewInMemoryRecorder()
+	tracer := NewWithOptions(Options{Recorder: rec, ShouldSample: ProbabilisticSampler(0)})
+
+	unsampled := tracer.StartSpan("unsampled")
+	unsampled.SetTag("k", "v")
+	child := tracer.StartSpan("child", opentracing.ChildOf(unsampled.Context()))
+	sampled, known := opentracing.IsSampled(child.Context())
+	assert.False(t, sampled)
+	assert.True(t, known)
+	child.Finish()
+	unsampled.Finish()
+	assert.Empty(t, rec.GetSpans())
+
+	debug := tracer.StartSpan("debug", opentracing.SamplingPriority(1))
+	late := tracer.StartSpan("late")
+	ext.SamplingPriority.Set(late, 1)
+	debug.Finish()
+	late.Finish()
+	spans := rec.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Empty(t, spans[0].Tags, "sampling.priority is a sampling decision, not a tag")
+
+	assert.True(t, ProbabilisticSampler(1)(^uint64(0)))
+	half := ProbabilisticSampler(0.5)
+	assert.True(t, half(1<<62))
+	assert.False(t, half(3<<62))
+}
+
+func TestTraceID128Bit(t *testing.T) {
+	tracer := NewWithOptions(Options{TraceID128Bit: true})
+	span := tracer.StartSpan("op")
+	ctx := span.Context().(opentracing.BasicSpanContext)
+	assert.NotZero(t, ctx.TraceIDHigh)
+	assert.Len(t, ctx.TraceIDString(), 32)
+	assert.Len(t, ctx.SpanIDString(), 16)
+
+	child := tracer.StartSpan("child", opentracing.ChildOf(ctx)).Context().(opentracing.BasicSpanContext)
+	assert.Equal(t, ctx.TraceIDHigh, child.TraceIDHigh)
+	assert.Equal(t, ctx.TraceID, child.TraceID)
+
+	short := New(nil).StartSpan("op").Context().(opentracing.BasicSpanContext)
+	assert.Zero(t, short.TraceIDHigh)
+	assert.Len(t, short.TraceIDString(), 16)
+}
+
+func TestMaxLogsPerSpan(t *testing.T) {
+	rec := recorder.NewInMemoryRecorder()
+	tracer := NewWithOptions(Options{Recorder: rec, MaxLogsPerSpan: 2})
+	span := tracer.StartSpan("op")
+	for i := 0; i < 5; i++ {
+		span.LogFields(log.Int("i", i))
+	}
+	span.Finish()
+
+	raw := rec.GetSpans()[0]
+	assert.Len(t, raw.Logs, 2)
+	assert.Equal(t, int64(3), raw.Tags[opentracing.LogsDroppedTagKey])
+}
+
+func TestOperationNameCallbackOnce(t *testing.T) {
+	rec := recorder.NewInMemoryRecorder()
+	tracer := New(rec)
+	calls := 0
+	span := tracer.StartSpan("GET", opentracing.WithOperationNameCallback(func(current string) string {
+		calls++
+		return current + "!"
+	}))
+	span.Finish()
+	span.Finish()
+
+	assert.Equal(t, 1, calls)
+	spans := rec.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "GET!", spans[0].Operation)
+}
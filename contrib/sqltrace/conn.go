@@ -0,0 +1,349 @@
+package sqltrace
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+var errNamedArgs = errors.New("sqltrace: driver does not support named arguments")
+
+type tracedConn struct {
+	conn   driver.Conn
+	config *driverConfig
+}
+
+// Prepare implements driver.Conn.
+func (c *tracedConn) Prepare(query string) (driver.Stmt, error) {
+	stmt, err := c.conn.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	return wrapStmt(stmt, query, c), nil
+}
+
+// PrepareContext implements driver.ConnPrepareContext.
+func (c *tracedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	pc, ok := c.conn.(driver.ConnPrepareContext)
+	if !ok {
+		return c.Prepare(query)
+	}
+	stmt, err := pc.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return wrapStmt(stmt, query, c), nil
+}
+
+// Close implements driver.Conn.
+func (c *tracedConn) Close() error {
+	return c.conn.Close()
+}
+
+// Begin implements driver.Conn.
+func (c *tracedConn) Begin() (driver.Tx, error) {
+	return c.conn.Begin()
+}
+
+// BeginTx implements driver.ConnBeginTx.
+func (c *tracedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if bt, ok := c.conn.(driver.ConnBeginTx); ok {
+		return bt.BeginTx(ctx, opts)
+	}
+	if opts.Isolation != 0 || opts.ReadOnly {
+		return nil, errors.New("sqltrace: driver does not support non-default transaction options")
+	}
+	return c.conn.Begin()
+}
+
+// ExecContext implements driver.ExecerContext. It returns driver.ErrSkip,
+// without starting a span, if the wrapped connection cannot execute
+// statements directly; database/sql then prepares the statement instead.
+func (c *tracedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.conn.(driver.ExecerContext)
+	legacy, legacyOK := c.conn.(driver.Execer)
+	if !ok && !legacyOK {
+		return nil, driver.ErrSkip
+	}
+	span := c.config.startSpan(ctx, query)
+	var res driver.Result
+	var err error
+	if ok {
+		res, err = execer.ExecContext(ctx, query, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValuesToValues(args); err == nil {
+			res, err = legacy.Exec(query, values)
+		}
+	}
+	tagRowsAffected(span, res, err)
+	finishSpan(span, err)
+	return res, err
+}
+
+// QueryContext implements driver.QueryerContext. Like ExecContext, it
+// returns driver.ErrSkip if the wrapped connection cannot query directly.
+func (c *tracedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.conn.(driver.QueryerContext)
+	legacy, legacyOK := c.conn.(driver.Queryer)
+	if !ok && !legacyOK {
+		return nil, driver.ErrSkip
+	}
+	span := c.config.startSpan(ctx, query)
+	var rows driver.Rows
+	var err error
+	if ok {
+		rows, err = queryer.QueryContext(ctx, query, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValuesToValues(args); err == nil {
+			rows, err = legacy.Query(query, values)
+		}
+	}
+	return traceRows(span, rows, err)
+}
+
+// Ping implements driver.Pinger.
+func (c *tracedConn) Ping(ctx context.Context) error {
+	if p, ok := c.conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession implements driver.SessionResetter.
+func (c *tracedConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+// CheckNamedValue implements driver.NamedValueChecker.
+func (c *tracedConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := c.conn.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+type tracedStmt struct {
+	stmt  driver.Stmt
+	query string
+	conn  *tracedConn
+}
+
+// Close implements driver.Stmt.
+func (s *tracedStmt) Close() error {
+	return s.stmt.Close()
+}
+
+// NumInput implements driver.Stmt.
+func (s *tracedStmt) NumInput() int {
+	return s.stmt.NumInput()
+}
+
+// Exec implements driver.Stmt. Only the context variants are traced.
+func (s *tracedStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.stmt.Exec(args)
+}
+
+// Query implements driver.Stmt. Only the context variants are traced.
+func (s *tracedStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.stmt.Query(args)
+}
+
+// ExecContext implements driver.StmtExecContext.
+func (s *tracedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	span := s.conn.config.startSpan(ctx, s.query)
+	var res driver.Result
+	var err error
+	if execer, ok := s.stmt.(driver.StmtExecContext); ok {
+		res, err = execer.ExecContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValuesToValues(args); err == nil {
+			res, err = s.stmt.Exec(values)
+		}
+	}
+	tagRowsAffected(span, res, err)
+	finishSpan(span, err)
+	return res, err
+}
+
+// QueryContext implements driver.StmtQueryContext.
+func (s *tracedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	span := s.conn.config.startSpan(ctx, s.query)
+	var rows driver.Rows
+	var err error
+	if queryer, ok := s.stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValuesToValues(args); err == nil {
+			rows, err = s.stmt.Query(values)
+		}
+	}
+	return traceRows(span, rows, err)
+}
+
+// wrapStmt wraps stmt in a tracedStmt that implements the same argument
+// conversion interfaces as stmt, since database/sql checks for them on the
+// statement it is given.
+func wrapStmt(stmt driver.Stmt, query string, conn *tracedConn) driver.Stmt {
+	s := &tracedStmt{stmt: stmt, query: query, conn: conn}
+	nvc, isChecker := stmt.(driver.NamedValueChecker)
+	cc, isConverter := stmt.(driver.ColumnConverter)
+	switch {
+	case isChecker && isConverter:
+		return &checkerConverterStmt{&converterStmt{s, cc}, nvc}
+	case isChecker:
+		return &checkerStmt{s, nvc}
+	case isConverter:
+		return &converterStmt{s, cc}
+	}
+	return s
+}
+
+type checkerStmt struct {
+	*tracedStmt
+	driver.NamedValueChecker
+}
+
+type converterStmt struct {
+	*tracedStmt
+	cc driver.ColumnConverter
+}
+
+// ColumnConverter implements driver.ColumnConverter.
+func (s *converterStmt) ColumnConverter(idx int) driver.ValueConverter {
+	return s.cc.ColumnConverter(idx)
+}
+
+type checkerConverterStmt struct {
+	*converterStmt
+	driver.NamedValueChecker
+}
+
+// traceRows finishes span if the query failed and otherwise returns rows
+// that finish it when they are closed.
+func traceRows(span opentracing.Span, rows driver.Rows, err error) (driver.Rows, error) {
+	if err != nil {
+		finishSpan(span, err)
+		return nil, err
+	}
+	return &tracedRows{rows: rows, span: span}, nil
+}
+
+// tracedRows implements the optional column type interfaces of
+// driver.Rows with the same defaults database/sql uses when the wrapped
+// rows do not implement them.
+type tracedRows struct {
+	rows driver.Rows
+	span opentracing.Span
+}
+
+// Columns implements driver.Rows.
+func (r *tracedRows) Columns() []string {
+	return r.rows.Columns()
+}
+
+// Close implements driver.Rows. It finishes the span of the query.
+func (r *tracedRows) Close() error {
+	err := r.rows.Close()
+	finishSpan(r.span, err)
+	return err
+}
+
+// Next implements driver.Rows. Errors other than io.EOF are logged on the
+// span.
+func (r *tracedRows) Next(dest []driver.Value) error {
+	err := r.rows.Next(dest)
+	if err != nil && err != io.EOF {
+		ext.LogError(r.span, err)
+	}
+	return err
+}
+
+// HasNextResultSet implements driver.RowsNextResultSet.
+func (r *tracedRows) HasNextResultSet() bool {
+	if nrs, ok := r.rows.(driver.RowsNextResultSet); ok {
+		return nrs.HasNextResultSet()
+	}
+	return false
+}
+
+// NextResultSet implements driver.RowsNextResultSet.
+func (r *tracedRows) NextResultSet() error {
+	if nrs, ok := r.rows.(driver.RowsNextResultSet); ok {
+		return nrs.NextResultSet()
+	}
+	return io.EOF
+}
+
+// ColumnTypeScanType implements driver.RowsColumnTypeScanType.
+func (r *tracedRows) ColumnTypeScanType(index int) reflect.Type {
+	if ct, ok := r.rows.(driver.RowsColumnTypeScanType); ok {
+		return ct.ColumnTypeScanType(index)
+	}
+	return reflect.TypeOf(new(interface{})).Elem()
+}
+
+// ColumnTypeDatabaseTypeName implements driver.RowsColumnTypeDatabaseTypeName.
+func (r *tracedRows) ColumnTypeDatabaseTypeName(index int) string {
+	if ct, ok := r.rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return ct.ColumnTypeDatabaseTypeName(index)
+	}
+	return ""
+}
+
+// ColumnTypeLength implements driver.RowsColumnTypeLength.
+func (r *tracedRows) ColumnTypeLength(index int) (length int64, ok bool) {
+	if ct, ok := r.rows.(driver.RowsColumnTypeLength); ok {
+		return ct.ColumnTypeLength(index)
+	}
+	return 0, false
+}
+
+// ColumnTypeNullable implements driver.RowsColumnTypeNullable.
+func (r *tracedRows) ColumnTypeNullable(index int) (nullable, ok bool) {
+	if ct, ok := r.rows.(driver.RowsColumnTypeNullable); ok {
+		return ct.ColumnTypeNullable(index)
+	}
+	return false, false
+}
+
+// ColumnTypePrecisionScale implements driver.RowsColumnTypePrecisionScale.
+func (r *tracedRows) ColumnTypePrecisionScale(index int) (precision, scale int64, ok bool) {
+	if ct, ok := r.rows.(driver.RowsColumnTypePrecisionScale); ok {
+		return ct.ColumnTypePrecisionScale(index)
+	}
+	return 0, 0, false
+}
+
+func tagRowsAffected(span opentracing.Span, res driver.Result, err error) {
+	if err != nil || res == nil {
+		return
+	}
+	if n, err := res.RowsAffected(); err == nil {
+		span.SetTag(RowsAffectedTagKey, n)
+	}
+}
+
+// namedValuesToValues converts the arguments for the methods of drivers
+// that predate the context variants, which do not support named arguments.
+func namedValuesToValues(named []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(named))
+	for i, nv := range named {
+		if nv.Name != "" {
+			return nil, errNamedArgs
+		}
+		values[i] = nv.Value
+	}
+	return values, nil
+}
@@ -0,0 +1,130 @@
+package sqltrace
+
+import (
+	"context"
+	"database/sql/driver"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+// RowsAffectedTagKey is the tag set on Exec spans of wrapped drivers to the
+// number of rows affected by the statement, when the driver reports it.
+const RowsAffectedTagKey = "db.rows_affected"
+
+// Wrap is Config{}.Wrap.
+func Wrap(d driver.Driver, dbType string) driver.Driver {
+	return Config{}.Wrap(d, dbType)
+}
+
+// WrapConnector is Config{}.WrapConnector.
+func WrapConnector(conn driver.Connector, dbType string) driver.Connector {
+	return Config{}.WrapConnector(conn, dbType)
+}
+
+// Wrap returns a driver.Driver that behaves like d but starts a span for
+// every statement executed with a context, as a child of the span in that
+// context:
+//
+//     sql.Register("postgres-traced", sqltrace.Wrap(&pq.Driver{}, "postgresql"))
+//     db, err := sql.Open("postgres-traced", dsn)
+//     rows, err := db.QueryContext(ctx, "SELECT ...")
+//
+// The spans are named and tagged like the spans of TraceQuery and, for
+// Exec, with db.rows_affected. Query spans finish when the rows are closed.
+// Errors returned by the driver set error=true and are logged on the span.
+func (c Config) Wrap(d driver.Driver, dbType string) driver.Driver {
+	return &tracedDriver{driver: d, config: &driverConfig{Config: c, dbType: dbType}}
+}
+
+// WrapConnector is like Wrap for a driver.Connector, for use with
+// sql.OpenDB.
+func (c Config) WrapConnector(conn driver.Connector, dbType string) driver.Connector {
+	return &tracedConnector{connector: conn, config: &driverConfig{Config: c, dbType: dbType}}
+}
+
+type driverConfig struct {
+	Config
+	dbType string
+}
+
+func (c *driverConfig) startSpan(ctx context.Context, query string) opentracing.Span {
+	span, _ := c.Config.startSpan(ctx, c.dbType, query)
+	return span
+}
+
+// finishSpan marks the span as failed if err is a real error and finishes
+// it. driver.ErrSkip only asks database/sql to take another code path and
+// is not recorded.
+func finishSpan(span opentracing.Span, err error) {
+	if err != nil && err != driver.ErrSkip {
+		ext.LogError(span, err)
+	}
+	span.Finish()
+}
+
+type tracedDriver struct {
+	driver driver.Driver
+	config *driverConfig
+}
+
+// Open implements driver.Driver.
+func (d *tracedDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedConn{conn: conn, config: d.config}, nil
+}
+
+// OpenConnector implements driver.DriverContext.
+func (d *tracedDriver) OpenConnector(name string) (driver.Connector, error) {
+	if dc, ok := d.driver.(driver.DriverContext); ok {
+		c, err := dc.OpenConnector(name)
+		if err != nil {
+			return nil, err
+		}
+		return &tracedConnector{connector: c, config: d.config, driver: d}, nil
+	}
+	return &dsnConnector{name: name, driver: d}, nil
+}
+
+type tracedConnector struct {
+	connector driver.Connector
+	config    *driverConfig
+	driver    driver.Driver // the driver returned by Driver, nil if not known yet
+}
+
+// Connect implements driver.Connector.
+func (c *tracedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedConn{conn: conn, config: c.config}, nil
+}
+
+// Driver implements driver.Connector.
+func (c *tracedConnector) Driver() driver.Driver {
+	if c.driver != nil {
+		return c.driver
+	}
+	return &tracedDriver{driver: c.connector.Driver(), config: c.config}
+}
+
+// dsnConnector is the driver.Connector of drivers that do not implement
+// driver.DriverContext, like the one database/sql uses internally.
+type dsnConnector struct {
+	name   string
+	driver *tracedDriver
+}
+
+// Connect implements driver.Connector.
+func (c *dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.name)
+}
+
+// Driver implements driver.Connector.
+func (c *dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
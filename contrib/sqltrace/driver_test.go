@@ -0,0 +1,179 @@
+package sqltrace
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+var errQuery = errors.New("syntax error")
+
+// fakeDriver returns fakeConns. A query containing "fail" fails.
+type fakeDriver struct {
+	direct bool // whether connections implement ExecerContext and QueryerContext
+}
+
+func (d fakeDriver) Open(string) (driver.Conn, error) {
+	if d.direct {
+		return directConn{}, nil
+	}
+	return fakeConn{}, nil
+}
+
+type fakeConnector struct{ fakeDriver }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return c.Open("") }
+func (c fakeConnector) Driver() driver.Driver                        { return c.fakeDriver }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{query}, nil }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+type directConn struct{ fakeConn }
+
+func (directConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return fakeStmt{query}.Exec(nil)
+}
+
+func (directConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return fakeStmt{query}.Query(nil)
+}
+
+type fakeStmt struct{ query string }
+
+func (fakeStmt) Close() error  { return nil }
+func (fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	if strings.Contains(s.query, "fail") {
+		return nil, errQuery
+	}
+	return driver.RowsAffected(3), nil
+}
+
+func (s fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	if strings.Contains(s.query, "fail") {
+		return nil, errQuery
+	}
+	return emptyRows{}, nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string         { return []string{"id"} }
+func (emptyRows) Close() error              { return nil }
+func (emptyRows) Next([]driver.Value) error { return io.EOF }
+
+func TestWrapConnector(t *testing.T) {
+	for _, direct := range []bool{true, false} {
+		tracer := mocktracer.New()
+		db := sql.OpenDB(Config{Tracer: tracer}.WrapConnector(fakeConnector{fakeDriver{direct: direct}}, "sql"))
+
+		parent := tracer.StartSpan("parent")
+		ctx := opentracing.ContextWithSpan(context.Background(), parent)
+		res, err := db.ExecContext(ctx, "UPDATE users SET name = ?", "x")
+		require.NoError(t, err)
+		n, err := res.RowsAffected()
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+
+		rows, err := db.QueryContext(ctx, "SELECT id FROM users")
+		require.NoError(t, err)
+		assert.Len(t, tracer.FinishedSpans(), 1, "the query span finishes when the rows are closed")
+		require.NoError(t, rows.Close())
+
+		_, err = db.ExecContext(ctx, "fail")
+		assert.Equal(t, errQuery, err)
+		require.NoError(t, db.Close())
+
+		spans := tracer.FinishedSpans()
+		require.Len(t, spans, 3, "direct=%t", direct)
+		for _, span := range spans {
+			assert.Equal(t, "sql.query", span.OperationName)
+			assert.Equal(t, parent.Context().(mocktracer.MockSpanContext).SpanID, span.ParentID)
+			assert.Equal(t, "sql", span.Tag(string(ext.DBType)))
+			assert.Equal(t, ext.SpanKindRPCClientEnum, span.Tag(string(ext.SpanKind)))
+		}
+		assert.Equal(t, "UPDATE users SET name = ?", spans[0].Tag(string(ext.DBStatement)))
+		assert.Equal(t, int64(3), spans[0].Tag(RowsAffectedTagKey))
+		assert.Nil(t, spans[0].Tag("error"))
+		assert.Equal(t, "SELECT id FROM users", spans[1].Tag(string(ext.DBStatement)))
+		assert.Nil(t, spans[1].Tag(RowsAffectedTagKey))
+		assert.Equal(t, true, spans[2].Tag("error"))
+	}
+}
+
+func TestWrap(t *testing.T) {
+	tracer := mocktracer.New()
+	sql.Register("sqltrace-test", Config{Tracer: tracer, MaxStatementLength: 6}.Wrap(fakeDriver{direct: true}, "postgresql"))
+	db, err := sql.Open("sqltrace-test", "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.ExecContext(context.Background(), "DELETE FROM users")
+	require.NoError(t, err)
+	require.NoError(t, db.PingContext(context.Background()))
+
+	spans := tracer.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "postgresql.query", spans[0].OperationName)
+	assert.Equal(t, 0, spans[0].ParentID)
+	assert.Equal(t, "DELETE", spans[0].Tag(string(ext.DBStatement)))
+}
+
+// convertingStmt implements the argument conversion interfaces of
+// driver.Stmt: it rejects named arguments and converts int parameters to
+// strings.
+type convertingStmt struct{ fakeStmt }
+
+func (convertingStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if nv.Name != "" {
+		return errors.New("no named arguments")
+	}
+	return driver.ErrSkip
+}
+
+func (convertingStmt) ColumnConverter(int) driver.ValueConverter { return stringConverter{} }
+
+type stringConverter struct{}
+
+func (stringConverter) ConvertValue(v interface{}) (driver.Value, error) {
+	return fmt.Sprint(v), nil
+}
+
+type convertingConn struct{ fakeConn }
+
+func (convertingConn) Prepare(query string) (driver.Stmt, error) {
+	return convertingStmt{fakeStmt{query}}, nil
+}
+
+func TestWrapForwardsArgumentConversion(t *testing.T) {
+	stmt, err := (&tracedConn{conn: convertingConn{}}).Prepare("SELECT ?")
+	require.NoError(t, err)
+	require.Implements(t, (*driver.NamedValueChecker)(nil), stmt)
+	require.Implements(t, (*driver.ColumnConverter)(nil), stmt)
+	v, err := stmt.(driver.ColumnConverter).ColumnConverter(0).ConvertValue(1)
+	require.NoError(t, err)
+	assert.Equal(t, "1", v)
+
+	stmt, err = (&tracedConn{conn: fakeConn{}}).Prepare("SELECT ?")
+	require.NoError(t, err)
+	_, ok := stmt.(driver.NamedValueChecker)
+	assert.False(t, ok)
+	_, ok = stmt.(driver.ColumnConverter)
+	assert.False(t, ok)
+}
@@ -0,0 +1,179 @@
+// Package sqltrace provides helpers for instrumenting database calls with
+// OpenTracing spans using the standard ext tags: TraceQuery and Tx for any
+// client library, and Wrap and WrapConnector for database/sql drivers.
+package sqltrace
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+	"unicode/utf8"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+// Config controls how database calls are recorded. The zero value records
+// the full statement and sets no peer tags.
+type Config struct {
+	// Tracer is the tracer of the spans. Defaults to the tracer in the
+	// context (see opentracing.ContextWithTracer), then to the global
+	// tracer.
+	Tracer opentracing.Tracer
+
+	// OperationName is the operation name of query spans. Defaults to
+	// "<dbType>.query".
+	OperationName string
+
+	// MaxStatementLength, if positive, truncates the db.statement tag to at
+	// most that many bytes (on a UTF-8 boundary).
+	MaxStatementLength int
+
+	// HashStatement replaces the db.statement tag with "sha256:<hex>" of the
+	// statement, to bound cardinality and keep literals out of the trace.
+	// It takes precedence over MaxStatementLength.
+	HashStatement bool
+
+	// Optional peer and database tags set on every span when non-empty.
+	PeerService  string
+	PeerAddress  string
+	PeerHostname string
+	PeerPort     uint16
+	DBInstance   string
+	DBUser       string
+}
+
+// TraceQuery is Config{}.TraceQuery.
+func TraceQuery(ctx context.Context, dbType, statement string, f func(ctx context.Context) error) error {
+	return Config{}.TraceQuery(ctx, dbType, statement, f)
+}
+
+// TraceQuery starts a child span of the span in ctx (see
+// opentracing.StartSpanFromContext), runs f with a context containing the
+// new span and finishes the span when f returns.
+//
+// The span is tagged with db.type, db.statement, span.kind=client and the
+// peer tags from c. If f returns an error, the span is marked with error=true
+// and the error is logged. The error returned by f is returned unchanged.
+func (c Config) TraceQuery(ctx context.Context, dbType, statement string, f func(ctx context.Context) error) error {
+	span, ctx := c.startSpan(ctx, dbType, statement)
+	return c.run(ctx, span, f)
+}
+
+func (c Config) startSpan(ctx context.Context, dbType, statement string) (opentracing.Span, context.Context) {
+	if c.Tracer != nil {
+		return opentracing.StartSpanFromContextWithTracer(ctx, c.Tracer, c.operationName(dbType), c.startOptions(dbType, statement)...)
+	}
+	return opentracing.StartSpanFromContext(ctx, c.operationName(dbType), c.startOptions(dbType, statement)...)
+}
+
+// Statement returns the value used for the db.statement tag of statement.
+func (c Config) Statement(statement string) string {
+	if c.HashStatement {
+		sum := sha256.Sum256([]byte(statement))
+		return "sha256:" + hex.EncodeToString(sum[:])
+	}
+	if c.MaxStatementLength <= 0 || len(statement) <= c.MaxStatementLength {
+		return statement
+	}
+	n := c.MaxStatementLength
+	for n > 0 && !utf8.RuneStart(statement[n]) {
+		n--
+	}
+	return statement[:n]
+}
+
+func (c Config) operationName(dbType string) string {
+	if c.OperationName != "" {
+		return c.OperationName
+	}
+	return dbType + ".query"
+}
+
+func (c Config) startOptions(dbType, statement string, opts ...opentracing.StartSpanOption) []opentracing.StartSpanOption {
+	tags := opentracing.Tags{
+		string(ext.DBType):      dbType,
+		string(ext.DBStatement): c.Statement(statement),
+	}
+	c.peerTags(tags)
+	return append(opts, ext.SpanKindRPCClient, tags, opentracing.StartTime(time.Now()))
+}
+
+func (c Config) peerTags(tags opentracing.Tags) {
+	for key, value := range map[string]string{
+		string(ext.PeerService):  c.PeerService,
+		string(ext.PeerAddress):  c.PeerAddress,
+		string(ext.PeerHostname): c.PeerHostname,
+		string(ext.DBInstance):   c.DBInstance,
+		string(ext.DBUser):       c.DBUser,
+	} {
+		if value != "" {
+			tags[key] = value
+		}
+	}
+	if c.PeerPort != 0 {
+		tags[string(ext.PeerPort)] = c.PeerPort
+	}
+}
+
+func (c Config) run(ctx context.Context, span opentracing.Span, f func(ctx context.Context) error) error {
+	err := f(ctx)
+	if err != nil {
+		ext.LogError(span, err)
+	}
+	span.FinishWithOptions(opentracing.FinishOptions{FinishTime: time.Now()})
+	return err
+}
+
+// Tx traces a database transaction: a parent span for the transaction and
+// a span for each statement executed in it referencing the transaction span
+// with FollowsFrom. Tx is driver-agnostic; the statements are run by the
+// functions passed to TraceQuery.
+type Tx struct {
+	config Config
+	dbType string
+	span   opentracing.Span
+	ctx    context.Context
+}
+
+// BeginTx is Config{}.BeginTx.
+func BeginTx(ctx context.Context, dbType, operationName string) (*Tx, context.Context) {
+	return Config{}.BeginTx(ctx, dbType, operationName)
+}
+
+// BeginTx starts the transaction span as a child of the span in ctx and
+// returns the Tx and a context containing the transaction span.
+func (c Config) BeginTx(ctx context.Context, dbType, operationName string) (*Tx, context.Context) {
+	tags := opentracing.Tags{string(ext.DBType): dbType}
+	c.peerTags(tags)
+	var span opentracing.Span
+	if c.Tracer != nil {
+		span, ctx = opentracing.StartSpanFromContextWithTracer(ctx, c.Tracer, operationName, ext.SpanKindRPCClient, tags)
+	} else {
+		span, ctx = opentracing.StartSpanFromContext(ctx, operationName, ext.SpanKindRPCClient, tags)
+	}
+	return &Tx{config: c, dbType: dbType, span: span, ctx: ctx}, ctx
+}
+
+// Span returns the transaction span.
+func (tx *Tx) Span() opentracing.Span {
+	return tx.span
+}
+
+// TraceQuery is like Config.TraceQuery, but the statement span follows from
+// the transaction span instead of being a child of the span in a context.
+func (tx *Tx) TraceQuery(statement string, f func(ctx context.Context) error) error {
+	opts := tx.config.startOptions(tx.dbType, statement, opentracing.FollowsFrom(tx.span.Context()))
+	span := tx.span.Tracer().StartSpan(tx.config.operationName(tx.dbType), opts...)
+	return tx.config.run(opentracing.ContextWithSpan(tx.ctx, span), span, f)
+}
+
+// Finish finishes the transaction span. If err is not nil, for example the
+// error of the commit or rollback, the span is marked as failed.
+func (tx *Tx) Finish(err error) {
+	if err != nil {
+		ext.LogError(tx.span, err)
+	}
+	tx.span.Finish()
+}
@@ -0,0 +1,94 @@
+package sqltrace
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+func TestTraceQuery(t *testing.T) {
+	tracer := mocktracer.New()
+	parent := tracer.StartSpan("parent")
+	ctx := opentracing.ContextWithTracer(context.Background(), tracer)
+	ctx = opentracing.ContextWithSpan(ctx, parent)
+
+	cfg := Config{PeerService: "users-db", PeerPort: 5432}
+	err := cfg.TraceQuery(ctx, "sql", "SELECT 1", func(ctx context.Context) error {
+		assert.NotEqual(t, parent, opentracing.SpanFromContext(ctx))
+		return nil
+	})
+	require.NoError(t, err)
+
+	spans := tracer.FinishedSpans()
+	require.Len(t, spans, 1)
+	sp := spans[0]
+	assert.Equal(t, "sql.query", sp.OperationName)
+	assert.Equal(t, parent.Context().(mocktracer.MockSpanContext).SpanID, sp.ParentID)
+	assert.Equal(t, map[string]interface{}{
+		"db.type":      "sql",
+		"db.statement": "SELECT 1",
+		"span.kind":    ext.SpanKindRPCClientEnum,
+		"peer.service": "users-db",
+		"peer.port":    uint16(5432),
+	}, sp.Tags())
+	assert.False(t, sp.FinishTime.Before(sp.StartTime))
+}
+
+func TestTraceQueryError(t *testing.T) {
+	tracer := mocktracer.New()
+	ctx := opentracing.ContextWithTracer(context.Background(), tracer)
+
+	boom := errors.New("boom")
+	err := TraceQuery(ctx, "redis", "GET k", func(ctx context.Context) error { return boom })
+	assert.Equal(t, boom, err)
+
+	sp := tracer.FinishedSpans()[0]
+	assert.Equal(t, "redis.query", sp.OperationName)
+	assert.Equal(t, true, sp.Tag("error"))
+	require.Len(t, sp.Logs(), 1)
+	assert.Equal(t, "boom", sp.Logs()[0].Fields[1].ValueString)
+}
+
+func TestStatement(t *testing.T) {
+	assert.Equal(t, "SELECT", Config{MaxStatementLength: 6}.Statement("SELECT * FROM users"))
+	assert.Equal(t, "short", Config{MaxStatementLength: 6}.Statement("short"))
+	// never cut a multi-byte rune in half
+	assert.Equal(t, "a", Config{MaxStatementLength: 2}.Statement("aé"[:1]+"éé"))
+
+	hashed := Config{HashStatement: true, MaxStatementLength: 3}.Statement("SELECT 1")
+	assert.True(t, strings.HasPrefix(hashed, "sha256:"))
+	assert.Len(t, hashed, len("sha256:")+64)
+	assert.Equal(t, hashed, Config{HashStatement: true}.Statement("SELECT 1"))
+}
+
+func TestTx(t *testing.T) {
+	tracer := mocktracer.New()
+	ctx := opentracing.ContextWithTracer(context.Background(), tracer)
+
+	tx, _ := Config{MaxStatementLength: 6}.BeginTx(ctx, "sql", "sql.transaction")
+	require.NoError(t, tx.TraceQuery("INSERT INTO t VALUES (1)", func(ctx context.Context) error { return nil }))
+	require.Error(t, tx.TraceQuery("UPDATE t SET x = 1", func(ctx context.Context) error { return errors.New("conflict") }))
+	tx.Finish(errors.New("rollback"))
+
+	spans := tracer.FinishedSpans()
+	require.Len(t, spans, 3)
+	txSpan := spans[2]
+	assert.Equal(t, "sql.transaction", txSpan.OperationName)
+	assert.Equal(t, true, txSpan.Tag("error"))
+	txID := txSpan.Context().(mocktracer.MockSpanContext).SpanID
+	for _, sp := range spans[:2] {
+		assert.Equal(t, txID, sp.ParentID)
+	}
+	assert.Equal(t, "INSERT", spans[0].Tag("db.statement"))
+	assert.Nil(t, spans[0].Tag("error"))
+	assert.Equal(t, "UPDATE", spans[1].Tag("db.statement"))
+	assert.Equal(t, true, spans[1].Tag("error"))
+}
@@ -0,0 +1,29 @@
+package opentracing
+
+// TracerWithDefaultTags 返回一个包装了 inner 的 Tracer，它创建的每个 Span 都会带上 tags 中的默认tag，
+// 例如`service.name`、`service.version`和`host`。
+//
+// 默认tag会在调用者的选项之前应用，因此调用者显式设置的同名tag会覆盖默认值。
+// tags 会在调用时被拷贝，之后对它的修改不会影响返回的 Tracer。Inject 和 Extract 会直接委托给 inner。
+func TracerWithDefaultTags(inner Tracer, tags map[string]interface{}) Tracer {
+	defaults := make(Tags, len(tags))
+	for k, v := range tags {
+		defaults[k] = v
+	}
+	return &defaultTagsTracer{Tracer: inner, defaults: defaults}
+}
+
+type defaultTagsTracer struct {
+	Tracer
+	defaults Tags
+}
+
+func (t *defaultTagsTracer) StartSpan(operationName string, opts ...StartSpanOption) Span {
+	if len(t.defaults) > 0 {
+		all := make([]StartSpanOption, 0, len(opts)+1)
+		all = append(all, t.defaults)
+		opts = append(all, opts...)
+	}
+	// 使 span.Tracer() 返回 t，这样通过它创建的子Span（例如 StartChildSpan）也会带上默认tag
+	return &tracerOverrideSpan{Span: t.Tracer.StartSpan(operationName, opts...), tracer: t}
+}
@@ -0,0 +1,45 @@
+package opentracing_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+func TestTracerWithDefaultTags(t *testing.T) {
+	inner := mocktracer.New()
+	defaults := map[string]interface{}{"service.name": "svc", "host": "h1"}
+	tracer := opentracing.TracerWithDefaultTags(inner, defaults)
+
+	// 之后修改 defaults 不应该影响 tracer
+	defaults["host"] = "changed"
+
+	tracer.StartSpan("a").Finish()
+	tracer.StartSpan("b", opentracing.Tag{Key: "host", Value: "h2"}, opentracing.Tags{"extra": 1}).Finish()
+
+	spans := inner.FinishedSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, map[string]interface{}{"service.name": "svc", "host": "h1"}, spans[0].Tags())
+	assert.Equal(t, map[string]interface{}{"service.name": "svc", "host": "h2", "extra": 1}, spans[1].Tags())
+}
+
+func TestTracerWithDefaultTagsChildSpan(t *testing.T) {
+	inner := mocktracer.New()
+	tracer := opentracing.TracerWithDefaultTags(inner, map[string]interface{}{"service.name": "svc"})
+
+	parent := tracer.StartSpan("parent")
+	assert.Equal(t, tracer, parent.Tracer())
+	assert.Equal(t, parent, parent.SetTag("k", "v"))
+	opentracing.StartChildSpan(parent, "child").Finish()
+	parent.Finish()
+
+	spans := inner.FinishedSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "child", spans[0].OperationName)
+	assert.Equal(t, "svc", spans[0].Tag("service.name"))
+	assert.Equal(t, spans[1].SpanContext.SpanID, spans[0].ParentID)
+}
@@ -0,0 +1,88 @@
+package opentracing
+
+import "sync"
+
+// DeferredOperationName 返回一个包装了 span 的 Span，它推迟 SetOperationName：
+// 在 Finish 之前第一次调用 SetOperationName 设置的名字是最终的操作名，之后的调用会被忽略。
+// 该名字会在 Span 结束前才被设置到 span 上，因此被包装的 span 最多只会被改名一次。
+//
+// 如果需要以最后一次调用为准，请使用 DeferredOperationNameLastWins。
+func DeferredOperationName(span Span) Span {
+	return &deferredNameSpan{Span: span}
+}
+
+// DeferredOperationNameLastWins 与 DeferredOperationName 相同，
+// 但在 Finish 之前最后一次调用 SetOperationName 设置的名字是最终的操作名。
+func DeferredOperationNameLastWins(span Span) Span {
+	return &deferredNameSpan{Span: span, lastWins: true}
+}
+
+type deferredNameSpan struct {
+	Span
+	lastWins bool
+
+	mu       sync.Mutex
+	name     string
+	named    bool
+	finished bool
+}
+
+func (s *deferredNameSpan) SetOperationName(operationName string) Span {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.finished && (!s.named || s.lastWins) {
+		s.name, s.named = operationName, true
+	}
+	return s
+}
+
+func (s *deferredNameSpan) SetTag(key string, value interface{}) Span {
+	s.Span.SetTag(key, value)
+	return s
+}
+
+func (s *deferredNameSpan) SetBaggageItem(restrictedKey, value string) Span {
+	s.Span.SetBaggageItem(restrictedKey, value)
+	return s
+}
+
+func (s *deferredNameSpan) Finish() {
+	s.applyName()
+	s.Span.Finish()
+}
+
+func (s *deferredNameSpan) FinishWithOptions(opts FinishOptions) {
+	s.applyName()
+	s.Span.FinishWithOptions(opts)
+}
+
+func (s *deferredNameSpan) applyName() {
+	s.mu.Lock()
+	name, named := s.name, s.named && !s.finished
+	s.finished = true
+	s.mu.Unlock()
+	if named {
+		s.Span.SetOperationName(name)
+	}
+}
+
+// StartSpanDeferred 以空的操作名开始一个 Span，并返回一个设置其操作名的函数。
+// 该函数只有第一次调用会生效（内部调用 SetOperationName），之后的调用会被忽略，
+// 这使得在路由确定之前就必须开始 Span 的框架可以统一处理延迟命名。
+//
+// 样例:
+//
+//    span, setName := opentracing.StartSpanDeferred(tracer, ext.SpanKindRPCServer)
+//    defer span.Finish()
+//    ...
+//    setName(route.Name)
+//
+func StartSpanDeferred(tracer Tracer, opts ...StartSpanOption) (Span, func(name string)) {
+	span := tracer.StartSpan("", opts...)
+	var once sync.Once
+	return span, func(name string) {
+		once.Do(func() {
+			span.SetOperationName(name)
+		})
+	}
+}
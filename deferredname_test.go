@@ -0,0 +1,100 @@
+package opentracing_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+func TestWithOperationNameCallback(t *testing.T) {
+	tracer := mocktracer.New()
+
+	span := tracer.StartSpan("GET", opentracing.WithOperationNameCallback(func(current string) string {
+		return current + " /users/{id}"
+	}))
+	assert.Equal(t, "GET", span.(*mocktracer.MockSpan).OperationName)
+	span.Finish()
+
+	// 回调收到的是 SetOperationName 之后的名字
+	span = tracer.StartSpan("GET", opentracing.WithOperationNameCallback(strings.ToLower))
+	span.SetOperationName("POST")
+	span.Finish()
+
+	spans := tracer.FinishedSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "GET /users/{id}", spans[0].OperationName)
+	assert.Equal(t, "post", spans[1].OperationName)
+
+	// 回调只在第一次 Finish 时调用，再次 Finish 不会改变已经记录的名字
+	calls := 0
+	span = tracer.StartSpan("GET", opentracing.WithOperationNameCallback(func(current string) string {
+		calls++
+		return current + "!"
+	}))
+	span.Finish()
+	span.FinishWithOptions(opentracing.FinishOptions{})
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "GET!", span.(*mocktracer.MockSpan).OperationName)
+
+	// noop tracer 忽略该选项
+	opentracing.NoopTracer{}.StartSpan("op", opentracing.WithOperationNameCallback(func(string) string {
+		t.Fatal("noop tracer must not call the callback")
+		return ""
+	})).Finish()
+}
+
+func TestDeferredOperationName(t *testing.T) {
+	tracer := mocktracer.New()
+
+	inner := tracer.StartSpan("pending")
+	span := opentracing.DeferredOperationName(inner)
+	assert.Equal(t, span, span.SetOperationName("GET /users/{id}"))
+	span.SetOperationName("ignored")
+	assert.Equal(t, "pending", inner.(*mocktracer.MockSpan).OperationName)
+	span.Finish()
+	span.SetOperationName("after finish")
+
+	span = opentracing.DeferredOperationNameLastWins(tracer.StartSpan("pending"))
+	span.SetOperationName("first")
+	span.SetOperationName("last")
+	span.FinishWithOptions(opentracing.FinishOptions{})
+
+	span = opentracing.DeferredOperationName(tracer.StartSpan("unnamed"))
+	span.Finish()
+
+	spans := tracer.FinishedSpans()
+	require.Len(t, spans, 3)
+	assert.Equal(t, "GET /users/{id}", spans[0].OperationName)
+	assert.Equal(t, "last", spans[1].OperationName)
+	assert.Equal(t, "unnamed", spans[2].OperationName)
+
+	// 与回调一起使用时，回调收到的是被推迟的名字
+	span = opentracing.DeferredOperationName(tracer.StartSpan("GET", opentracing.WithOperationNameCallback(strings.ToUpper)))
+	span.SetOperationName("get /x")
+	span.Finish()
+	assert.Equal(t, "GET /X", tracer.FinishedSpans()[3].OperationName)
+
+	// noop
+	span = opentracing.DeferredOperationName(opentracing.NoopTracer{}.StartSpan("op"))
+	span.SetOperationName("x").Finish()
+}
+
+func TestStartSpanDeferred(t *testing.T) {
+	tracer := mocktracer.New()
+	span, setName := opentracing.StartSpanDeferred(tracer, opentracing.Tag{Key: "k", Value: "v"})
+	assert.Equal(t, "", span.(*mocktracer.MockSpan).OperationName)
+
+	setName("GET /users/{id}")
+	assert.Equal(t, "GET /users/{id}", span.(*mocktracer.MockSpan).OperationName)
+	setName("ignored")
+	span.Finish()
+
+	sp := tracer.FinishedSpans()[0]
+	assert.Equal(t, "GET /users/{id}", sp.OperationName)
+	assert.Equal(t, "v", sp.Tag("k"))
+}
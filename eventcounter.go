@@ -0,0 +1,119 @@
+package opentracing
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/opentracing/opentracing-go/log"
+)
+
+// WithEventCounters 返回一个包装了 span 的 Span，它统计日志中键为`event`、值为 names 之一的字段出现的次数，
+// 并在 Finish 或 FinishWithOptions 时（在委托给 span 之前）为每一个出现过的事件设置一次 tag `event.<name>.count`。
+// 例如记录了三次 log.Event("retry") 的 Span 会带有 tag `event.retry.count=3`。
+//
+// 统计的范围包括 LogFields、LogKV、已废弃的 LogEvent、LogEventWithPayload、Log，
+// 以及 FinishWithOptions 中的 LogRecords 和 BulkLogData。其他事件会被忽略。计数可以在多个goroutine中并发进行。
+func WithEventCounters(span Span, names ...string) Span {
+	counters := make(map[string]*int64, len(names))
+	for _, name := range names {
+		counters[name] = new(int64)
+	}
+	return &eventCounterSpan{Span: span, names: names, counters: counters}
+}
+
+type eventCounterSpan struct {
+	Span
+	names    []string
+	counters map[string]*int64 // 创建之后只读，计数使用 atomic 访问
+	once     sync.Once
+}
+
+func (s *eventCounterSpan) count(event string) {
+	if c, ok := s.counters[event]; ok {
+		atomic.AddInt64(c, 1)
+	}
+}
+
+func (s *eventCounterSpan) countFields(fields []log.Field) {
+	for _, f := range fields {
+		if f.Key() != log.EventKey {
+			continue
+		}
+		if event, ok := f.Value().(string); ok {
+			s.count(event)
+		}
+	}
+}
+
+func (s *eventCounterSpan) LogFields(fields ...log.Field) {
+	s.countFields(fields)
+	s.Span.LogFields(fields...)
+}
+
+func (s *eventCounterSpan) LogKV(alternatingKeyValues ...interface{}) {
+	for i := 0; i+1 < len(alternatingKeyValues); i += 2 {
+		if key, ok := alternatingKeyValues[i].(string); ok && key == log.EventKey {
+			if event, ok := alternatingKeyValues[i+1].(string); ok {
+				s.count(event)
+			}
+		}
+	}
+	s.Span.LogKV(alternatingKeyValues...)
+}
+
+func (s *eventCounterSpan) LogEvent(event string) {
+	s.count(event)
+	s.Span.LogEvent(event)
+}
+
+func (s *eventCounterSpan) LogEventWithPayload(event string, payload interface{}) {
+	s.count(event)
+	s.Span.LogEventWithPayload(event, payload)
+}
+
+func (s *eventCounterSpan) Log(data LogData) {
+	s.count(data.Event)
+	s.Span.Log(data)
+}
+
+// setCountTags 只在第一次调用时设置计数的 tag
+func (s *eventCounterSpan) setCountTags() {
+	s.once.Do(func() {
+		for _, name := range s.names {
+			if n := atomic.LoadInt64(s.counters[name]); n > 0 {
+				s.Span.SetTag("event."+name+".count", n)
+			}
+		}
+	})
+}
+
+func (s *eventCounterSpan) Finish() {
+	s.setCountTags()
+	s.Span.Finish()
+}
+
+func (s *eventCounterSpan) FinishWithOptions(opts FinishOptions) {
+	for _, lr := range opts.LogRecords {
+		s.countFields(lr.Fields)
+	}
+	for _, ld := range opts.BulkLogData {
+		s.count(ld.Event)
+	}
+	s.setCountTags()
+	s.Span.FinishWithOptions(opts)
+}
+
+func (s *eventCounterSpan) SetOperationName(operationName string) Span {
+	s.Span.SetOperationName(operationName)
+	return s
+}
+
+func (s *eventCounterSpan) SetTag(key string, value interface{}) Span {
+	s.Span.SetTag(key, value)
+	return s
+}
+
+func (s *eventCounterSpan) SetBaggageItem(restrictedKey, value string) Span {
+	s.Span.SetBaggageItem(restrictedKey, value)
+	return s
+}
@@ -0,0 +1,71 @@
+package opentracing_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+// tagCountingSpan 统计 SetTag 的调用次数
+type tagCountingSpan struct {
+	opentracing.Span
+	setTags map[string]int
+}
+
+func (s *tagCountingSpan) SetTag(key string, value interface{}) opentracing.Span {
+	s.setTags[key]++
+	return s.Span.SetTag(key, value)
+}
+
+func TestWithEventCounters(t *testing.T) {
+	tracer := mocktracer.New()
+	inner := &tagCountingSpan{Span: tracer.StartSpan("op"), setTags: map[string]int{}}
+	span := opentracing.WithEventCounters(inner, "retry", "cache_miss", "error")
+
+	span.LogFields(log.Event("retry"), log.Int("attempt", 1))
+	span.LogKV("event", "retry", "attempt", 2)
+	span.LogKV("event", "cache_miss")
+	span.LogFields(log.Event("unrelated"), log.String("retry", "not an event"))
+	span.FinishWithOptions(opentracing.FinishOptions{
+		LogRecords: []opentracing.LogRecord{{Timestamp: time.Now(), Fields: []log.Field{log.Event("retry")}}},
+	})
+	span.Finish()
+
+	sp := tracer.FinishedSpans()[0]
+	assert.Equal(t, int64(3), sp.Tag("event.retry.count"))
+	assert.Equal(t, int64(1), sp.Tag("event.cache_miss.count"))
+	assert.Nil(t, sp.Tag("event.error.count"))
+	assert.Nil(t, sp.Tag("event.unrelated.count"))
+	assert.Equal(t, map[string]int{"event.retry.count": 1, "event.cache_miss.count": 1}, inner.setTags)
+	assert.Len(t, sp.Logs(), 5)
+}
+
+func TestWithEventCountersConcurrent(t *testing.T) {
+	tracer := mocktracer.New()
+	span := opentracing.WithEventCounters(tracer.StartSpan("op"), "retry")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				span.LogFields(log.Event("retry"))
+				span.LogKV("event", "retry")
+			}
+		}()
+	}
+	wg.Wait()
+	span.Finish()
+
+	spans := tracer.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, int64(400), spans[0].Tag("event.retry.count"))
+}
@@ -0,0 +1,8 @@
+package opentracing
+
+// ExportedTestTracer 把 testTracer 导出给外部测试包（opentracing_test）使用，
+// 例如用 harness 包检查它的行为。
+var ExportedTestTracer Tracer = testTracer{}
+
+// RunWithSpan 把 runWithSpan 导出给外部测试包，以便在不让测试进程崩溃的情况下测试panic的记录。
+var RunWithSpan = runWithSpan
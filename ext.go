@@ -15,3 +15,66 @@ type TracerContextWithSpanExtension interface {
 	// 该hook在 ContextWithSpan 函数会在span放在context前执行。
 	ContextWithSpanHook(ctx context.Context, span Span) context.Context
 }
+
+// TracerSpanFromContextExtension 是一个扩展接口，Tracer的实现可能要实现该接口。
+// 它允许Tracer在 SpanFromContext 没有找到 Span 时介入go的context的读取。
+//
+// 与 TracerContextWithSpanExtension 一样，此扩展的主要目的是从 opentracing API 到其他一些跟踪 API 的适配器：
+// 当context中没有由 ContextWithSpan 放置的 Span，但其他跟踪系统在同一个context中有活跃的span时，
+// 适配器可以合成一个包装了它的 Span。
+type TracerSpanFromContextExtension interface {
+	// SpanFromContextHook 当全局 Tracer（见 GlobalTracer）实现了此接口，
+	// 并且context中没有由 ContextWithSpan 放置的 Span 时，会被 SpanFromContext 调用。
+	//
+	// 如果该hook能够从context中得到一个 Span，则返回 (span, true)，否则返回 (nil, false)。
+	SpanFromContextHook(ctx context.Context) (Span, bool)
+}
+
+// NoopAware 是一个扩展接口，Tracer的实现可能要实现该接口。
+// 当 IsNoop 返回 true 时，表示该 Tracer 的 StartSpan 不会记录任何数据，
+// StartSpanFromContext 可以因此跳过查找父级 Span 和构造引用的开销（见 SetNoopContextPassthrough）。
+//
+// NoopTracer 不需要实现此接口就会被识别。注意不要在一个嵌入了 NoopTracer 但重写了 StartSpan 的类型上实现它。
+type NoopAware interface {
+	// IsNoop 返回 Tracer 是否为空操作(no-op)的实现
+	IsNoop() bool
+}
+
+// ContextualPropagator 是一个扩展接口，Tracer的实现可能要实现该接口。
+// 它允许较慢的传播器（例如需要查询远程字典的实现）感知`context.Context`的取消和超时并提前返回。
+//
+// 见 InjectContext 和 ExtractContext。
+type ContextualPropagator interface {
+	// InjectContext 与 Tracer.Inject 相同，但在`ctx`被取消时应尽快返回`ctx.Err()`。
+	InjectContext(ctx context.Context, sc SpanContext, format interface{}, carrier interface{}) error
+
+	// ExtractContext 与 Tracer.Extract 相同，但在`ctx`被取消时应尽快返回`ctx.Err()`。
+	ExtractContext(ctx context.Context, format interface{}, carrier interface{}) (SpanContext, error)
+}
+
+// ErrorableTracer 是一个扩展接口，Tracer的实现可能要实现该接口。
+// 它允许 Tracer 在无法创建 Span 时（例如配置错误或资源耗尽）返回一个错误，而不是panic或者悄悄地返回空操作的 Span。
+//
+// 见 StartSpanE。
+type ErrorableTracer interface {
+	// StartSpanE 与 Tracer.StartSpan 相同，但可以返回一个错误。
+	StartSpanE(operationName string, opts ...StartSpanOption) (Span, error)
+}
+
+// SpanContextValidator 是一个扩展接口，Tracer的实现可能要实现该接口。
+// 它允许调用者在 Inject 之前判断一个 SpanContext 能否被该 Tracer 接受，
+// 例如网关可以在扇出(fan-out)之前过滤掉由其他 Tracer 创建的不兼容的 SpanContext。
+//
+// 见 CanInject。
+type SpanContextValidator interface {
+	// ValidSpanContext 返回 sc 能否被该 Tracer 的 Inject 接受，即 Inject 不会因此返回 ErrInvalidSpanContext。
+	ValidSpanContext(sc SpanContext) bool
+}
+
+// Retirable 是一个扩展接口，Span的实现可能要实现该接口。
+// 它允许 Tracer 在调用者放弃 Span（见 FinishOptions.Relinquish）之后重置并复用 Span 对象，
+// 避免每个 Span 都产生一次堆分配。见 ReusableSpanPool。
+type Retirable interface {
+	// Retire 把 Span 重置为可以被再次使用的状态，调用之后 Span 之前的所有数据都不再有效。
+	Retire()
+}
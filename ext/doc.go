@@ -0,0 +1,11 @@
+// Package ext provides typed helpers for the standard OpenTracing semantic
+// tags, such as span.kind, http.method, http.status_code, db.statement,
+// error and peer.*, as well as the RPCServerOption start option referenced
+// by the Tracer documentation:
+//
+//     clientContext, _ := tracer.Extract(opentracing.HTTPHeaders, carrier)
+//     span := tracer.StartSpan("rpc", ext.RPCServerOption(clientContext))
+//     ext.HTTPMethod.Set(span, req.Method)
+//
+// See https://github.com/opentracing/specification/blob/master/semantic_conventions.md
+package ext
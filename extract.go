@@ -0,0 +1,23 @@
+package opentracing
+
+// ExtractErrorTagKey 是 ExtractOrStartRoot 在 Extract 失败（不包括 ErrSpanContextNotFound）时设置的 tag 的 key。
+const ExtractErrorTagKey = "extract.error"
+
+// ExtractOrStartRoot 从 carrier 中 Extract 一个 SpanContext，并以`operationName`开始一个Span：
+//
+//   - 如果 Extract 成功，新的Span以`ChildOf`引用提取出的 SpanContext；
+//   - 如果 Extract 返回了 ErrSpanContextNotFound，新的Span是一个根Span；
+//   - 如果 Extract 返回了其他错误（例如 ErrSpanContextCorrupted），新的Span也是一个根Span，
+//     但会带有 tag `extract.error`，其值为错误信息，以便发现丢失的链路。
+//
+// 这避免了在 Extract 失败时直接使用`ChildOf(nil)`而悄悄地开始一条新链路。
+func ExtractOrStartRoot(tracer Tracer, format, carrier interface{}, operationName string, opts ...StartSpanOption) Span {
+	sc, err := tracer.Extract(format, carrier)
+	switch {
+	case err == nil:
+		opts = append(opts[:len(opts):len(opts)], ChildOf(sc))
+	case !IsSpanContextNotFound(err):
+		opts = append(opts[:len(opts):len(opts)], Tag{Key: ExtractErrorTagKey, Value: err.Error()})
+	}
+	return tracer.StartSpan(operationName, opts...)
+}
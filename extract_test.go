@@ -0,0 +1,44 @@
+package opentracing_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+func TestExtractOrStartRoot(t *testing.T) {
+	tracer := mocktracer.New()
+	upstream := tracer.StartSpan("upstream")
+	carrier := opentracing.TextMapCarrier{}
+	require.NoError(t, tracer.Inject(upstream.Context(), opentracing.TextMap, carrier))
+
+	child := opentracing.ExtractOrStartRoot(tracer, opentracing.TextMap, carrier, "child",
+		opentracing.Tag{Key: "k", Value: "v"}).(*mocktracer.MockSpan)
+	assert.Equal(t, upstream.Context().(mocktracer.MockSpanContext).SpanID, child.ParentID)
+	assert.Equal(t, map[string]interface{}{"k": "v"}, child.Tags())
+
+	root := opentracing.ExtractOrStartRoot(tracer, opentracing.TextMap, opentracing.TextMapCarrier{}, "root").(*mocktracer.MockSpan)
+	assert.Equal(t, 0, root.ParentID)
+	assert.Empty(t, root.Tags())
+
+	corrupted := opentracing.TextMapCarrier{"mockpfx-ids-traceid": "not a number"}
+	broken := opentracing.ExtractOrStartRoot(tracer, opentracing.TextMap, corrupted, "broken").(*mocktracer.MockSpan)
+	assert.Equal(t, 0, broken.ParentID)
+	assert.Contains(t, broken.Tag(opentracing.ExtractErrorTagKey), "corrupted")
+}
+
+func TestExtractOrStartRootKeepsCallerOptions(t *testing.T) {
+	tracer := mocktracer.New()
+	upstream := tracer.StartSpan("upstream")
+	carrier := opentracing.TextMapCarrier{}
+	require.NoError(t, tracer.Inject(upstream.Context(), opentracing.TextMap, carrier))
+
+	opts := make([]opentracing.StartSpanOption, 1, 2)
+	opts[0] = opentracing.Tag{Key: "k", Value: "v"}
+	opentracing.ExtractOrStartRoot(tracer, opentracing.TextMap, carrier, "child", opts...).Finish()
+	assert.Nil(t, opts[:2][1], "the caller's backing array must not be modified")
+}
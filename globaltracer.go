@@ -1,31 +1,153 @@
 package opentracing
 
+import (
+	"context"
+	"sync/atomic"
+)
+
 type registeredTracer struct {
 	tracer       Tracer
 	isRegistered bool
 }
 
-var (
-	globalTracer = registeredTracer{NoopTracer{}, false}
-)
+// globalTracer 保存当前的 registeredTracer，使注册和读取全局tracer可以在多个goroutine中并发进行。
+var globalTracer atomic.Value
+
+// loadGlobalTracer 返回当前的 registeredTracer，在第一次注册之前返回未注册的 NoopTracer。
+func loadGlobalTracer() registeredTracer {
+	if rt, ok := globalTracer.Load().(registeredTracer); ok {
+		return rt
+	}
+	return registeredTracer{NoopTracer{}, false}
+}
 
 // SetGlobalTracer 设置一个[单例]的追踪系统。 Tracer 可以使用 GlobalTracer() 返回。
 // 不管谁使用 GlobalTracer（而不是指直接管理 opentracing.Tracer 的实例），
 // 都应该在main()中尽早的调用 SetGlobalTracer，应在`StartSpan`的调用之前。
 // 在调用`SetGlobalTracer`之前，任何通过`StartSpan`创建的Span都是来自noop的。
+//
+// SetGlobalTracer 可以与 GlobalTracer 并发调用。
+// 注册 DelegatingTracer 本身没有任何效果（否则它会委托给自己）。
 func SetGlobalTracer(tracer Tracer) {
-	globalTracer = registeredTracer{tracer, true}
+	switch tracer.(type) {
+	case DelegatingTracer, *DelegatingTracer:
+		return
+	}
+	globalTracer.Store(registeredTracer{tracer, true})
+}
+
+// SetGlobalTracerWithRestore 与 SetGlobalTracer 相同，但返回一个 restore 函数，
+// 调用它会把全局tracer和 IsGlobalTracerRegistered() 的状态恢复为 SetGlobalTracerWithRestore 调用之前的值：
+//
+//    restore := opentracing.SetGlobalTracerWithRestore(mocktracer.New())
+//    defer restore()
+//
+// restore 会无条件地恢复之前的值，即使在此期间全局tracer又被其他调用修改过。
+func SetGlobalTracerWithRestore(tracer Tracer) (restore func()) {
+	old := loadGlobalTracer()
+	SetGlobalTracer(tracer)
+	return func() {
+		globalTracer.Store(old)
+	}
+}
+
+// ResetGlobalTracer 把全局tracer恢复为初始状态：GlobalTracer() 返回 DelegatingTracer，IsGlobalTracerRegistered() 返回 false。
+//
+// 它用于测试，使测试之间不会通过全局tracer互相影响；生产代码不应该调用它。
+func ResetGlobalTracer() {
+	globalTracer.Store(registeredTracer{NoopTracer{}, false})
 }
 
 // GloablTracer 返回`Tracer`实现的全局单例。
-// 在调用`SetGlobalTracer()`之前，`GlobalTracer()`返回的是noop实现，它会丢掉所有的数据。
+// 在调用`SetGlobalTracer()`之前，`GlobalTracer()`返回的是 DelegatingTracer，
+// 在注册之前它的行为与noop实现相同（会丢掉所有的数据），注册之后它会委托给注册的 Tracer。
 func GlobalTracer() Tracer {
-	return globalTracer.tracer
+	rt := loadGlobalTracer()
+	if !rt.isRegistered {
+		return DelegatingTracer{}
+	}
+	return rt.tracer
+}
+
+// DelegatingTracer 是在注册全局tracer之前 GlobalTracer() 返回的 Tracer，
+// 它把每一次调用都转发给调用时注册的全局tracer（还没有注册时为 NoopTracer）。
+//
+// 类库常常在初始化时就保存`opentracing.GlobalTracer()`的返回值，而应用在那之后才调用 SetGlobalTracer。
+// 有了 DelegatingTracer，这些类库之后创建的 Span 也会由注册的 Tracer 记录，而不会被悄悄丢弃。
+// 已经创建的 Span 不受影响：在注册之前创建的 Span 仍然是空操作的。
+//
+// DelegatingTracer 还会把 NoopAware、TracerSpanFromContextExtension、ContextualPropagator、
+// ErrorableTracer 和 SpanContextValidator 这些扩展接口转发给注册的 Tracer，注册的 Tracer 没有实现时使用默认行为。
+// 它的零值即可使用。
+type DelegatingTracer struct{}
+
+// delegate 返回当前注册的全局tracer，没有注册时为 NoopTracer。
+func (DelegatingTracer) delegate() Tracer {
+	return loadGlobalTracer().tracer
+}
+
+// StartSpan 实现 Tracer 接口
+func (t DelegatingTracer) StartSpan(operationName string, opts ...StartSpanOption) Span {
+	return t.delegate().StartSpan(operationName, opts...)
+}
+
+// Inject 实现 Tracer 接口
+func (t DelegatingTracer) Inject(sc SpanContext, format interface{}, carrier interface{}) error {
+	return t.delegate().Inject(sc, format, carrier)
+}
+
+// Extract 实现 Tracer 接口
+func (t DelegatingTracer) Extract(format interface{}, carrier interface{}) (SpanContext, error) {
+	return t.delegate().Extract(format, carrier)
+}
+
+// IsNoop 实现 NoopAware 接口，它返回当前注册的全局tracer是否为空操作的实现。
+func (t DelegatingTracer) IsNoop() bool {
+	return isNoopTracer(t.delegate())
+}
+
+// SpanFromContextHook 实现 TracerSpanFromContextExtension 接口
+func (t DelegatingTracer) SpanFromContextHook(ctx context.Context) (Span, bool) {
+	if hook, ok := t.delegate().(TracerSpanFromContextExtension); ok {
+		return hook.SpanFromContextHook(ctx)
+	}
+	return nil, false
+}
+
+// InjectContext 实现 ContextualPropagator 接口
+func (t DelegatingTracer) InjectContext(ctx context.Context, sc SpanContext, format interface{}, carrier interface{}) error {
+	return InjectContext(ctx, t.delegate(), sc, format, carrier)
+}
+
+// ExtractContext 实现 ContextualPropagator 接口
+func (t DelegatingTracer) ExtractContext(ctx context.Context, format interface{}, carrier interface{}) (SpanContext, error) {
+	return ExtractContext(ctx, t.delegate(), format, carrier)
+}
+
+// StartSpanE 实现 ErrorableTracer 接口
+func (t DelegatingTracer) StartSpanE(operationName string, opts ...StartSpanOption) (Span, error) {
+	return StartSpanE(t.delegate(), operationName, opts...)
+}
+
+// ValidSpanContext 实现 SpanContextValidator 接口
+func (t DelegatingTracer) ValidSpanContext(sc SpanContext) bool {
+	return CanInject(t.delegate(), sc)
 }
 
 // StartSpan 遵从 Tracer.StartSpan，见 `GlobalTracer()`。
 func StartSpan(operationName string, opts ...StartSpanOption) Span {
-	return globalTracer.tracer.StartSpan(operationName, opts...)
+	return GlobalTracer().StartSpan(operationName, opts...)
+}
+
+// StartChildSpan 使用`parent.Tracer()`开始并返回一个以`ChildOf(parent.Context())`引用 parent 的子Span，
+// `opts`会被追加在该引用之后。
+//
+// 如果 parent 为空(nil)，它与 StartSpan 相同，即使用 GlobalTracer() 创建一个根Span（除非`opts`中包含了引用）。
+func StartChildSpan(parent Span, operationName string, opts ...StartSpanOption) Span {
+	if parent == nil {
+		return StartSpan(operationName, opts...)
+	}
+	return parent.Tracer().StartSpan(operationName, append([]StartSpanOption{ChildOf(parent.Context())}, opts...)...)
 }
 
 // InitGlobalTracer 已废弃(deprecated)，请使用 SetGlobalTracer。
@@ -33,7 +155,20 @@ func InitGlobalTracer(tracer Tracer) {
 	SetGlobalTracer(tracer)
 }
 
+// WithGlobalTracer 临时把全局tracer设置为 tracer 并执行 fn，之后无论 fn 是否panic，
+// 都会把全局tracer和 IsGlobalTracerRegistered() 的状态恢复为调用之前的值。主要用于测试：
+//
+//    opentracing.WithGlobalTracer(mocktracer.New(), func() {
+//        ...
+//    })
+//
+// 注意在 fn 执行期间全局tracer对整个进程都是可见的，并发执行的测试也会使用它。
+func WithGlobalTracer(tracer Tracer, fn func()) {
+	defer SetGlobalTracerWithRestore(tracer)()
+	fn()
+}
+
 // IsGlobalTracerRegistered 返回一个布尔值去判断tracer是否已经在全局注册
 func IsGlobalTracerRegistered() bool {
-	return globalTracer.isRegistered
+	return loadGlobalTracer().isRegistered
 }
@@ -20,7 +20,136 @@ func TestAfterSettingGlobalTracerIsGlobalTracerRegisteredReturnsTrue(t *testing.
 }
 
 func TestDefaultTracerIsNoopTracer(t *testing.T) {
-	if reflect.TypeOf(GlobalTracer()) != reflect.TypeOf(NoopTracer{}) {
-		t.Errorf("Should return false when no global tracer is registered.")
+	defer globalTracer.Store(loadGlobalTracer())
+	ResetGlobalTracer()
+
+	if reflect.TypeOf(GlobalTracer()) != reflect.TypeOf(DelegatingTracer{}) {
+		t.Errorf("Expected DelegatingTracer when no global tracer is registered, got %T", GlobalTracer())
+	}
+	if !isNoopTracer(GlobalTracer()) {
+		t.Errorf("Expected the default global tracer to be a noop tracer")
+	}
+}
+
+func TestDelegatingTracer(t *testing.T) {
+	defer globalTracer.Store(loadGlobalTracer())
+	ResetGlobalTracer()
+
+	cached := GlobalTracer()
+	if _, ok := cached.StartSpan("before").(noopSpan); !ok {
+		t.Errorf("Expected a noop span before registration")
+	}
+
+	SetGlobalTracer(testTracer{})
+	if _, ok := cached.StartSpan("after").(testSpan); !ok {
+		t.Errorf("Expected the cached tracer to delegate to the registered tracer")
+	}
+	if isNoopTracer(cached) {
+		t.Errorf("Expected the cached tracer not to be a noop tracer after registration")
+	}
+	carrier := TextMapCarrier{}
+	if err := cached.Inject(testSpanContext{FakeID: 7}, TextMap, carrier); err != nil {
+		t.Fatalf("Inject failed: %v", err)
+	}
+	if sc, err := cached.Extract(TextMap, carrier); err != nil || sc.(testSpanContext).FakeID != 7 {
+		t.Errorf("Expected the injected context to be extracted, got %v, %v", sc, err)
+	}
+
+	SetGlobalTracer(DelegatingTracer{})
+	if _, ok := GlobalTracer().(testTracer); !ok {
+		t.Errorf("Registering a DelegatingTracer should have no effect, got %T", GlobalTracer())
+	}
+}
+
+func TestStartChildSpan(t *testing.T) {
+	defer globalTracer.Store(loadGlobalTracer())
+	SetGlobalTracer(testTracer{})
+
+	parent := testTracer{}.StartSpan("parent")
+	child := StartChildSpan(parent, "child", Tag{Key: "k", Value: "v"}).(testSpan)
+	if !child.spanContext.HasParent || child.spanContext.FakeID != parent.Context().(testSpanContext).FakeID {
+		t.Errorf("Expected a child of the parent span, got %+v", child.spanContext)
+	}
+	if child.OperationName != "child" || child.Tags["k"] != "v" {
+		t.Errorf("Expected the extra options to be applied, got %+v", child)
+	}
+
+	root := StartChildSpan(nil, "root").(testSpan)
+	if root.spanContext.HasParent {
+		t.Errorf("Expected a root span for a nil parent, got %+v", root.spanContext)
+	}
+}
+
+func TestWithGlobalTracer(t *testing.T) {
+	before, registered := GlobalTracer(), IsGlobalTracerRegistered()
+
+	WithGlobalTracer(testTracer{}, func() {
+		if _, ok := GlobalTracer().(testTracer); !ok || !IsGlobalTracerRegistered() {
+			t.Errorf("Expected the temporary global tracer to be registered, got %T", GlobalTracer())
+		}
+	})
+	if GlobalTracer() != before || IsGlobalTracerRegistered() != registered {
+		t.Errorf("Expected the global tracer to be restored")
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("Expected the panic to propagate, got %v", r)
+			}
+		}()
+		WithGlobalTracer(testTracer{}, func() { panic("boom") })
+	}()
+	if GlobalTracer() != before || IsGlobalTracerRegistered() != registered {
+		t.Errorf("Expected the global tracer to be restored after a panic")
+	}
+}
+
+func TestResetGlobalTracer(t *testing.T) {
+	defer globalTracer.Store(loadGlobalTracer())
+
+	SetGlobalTracer(testTracer{})
+	ResetGlobalTracer()
+	if IsGlobalTracerRegistered() {
+		t.Errorf("Should return false after ResetGlobalTracer.")
+	}
+	if _, ok := GlobalTracer().(DelegatingTracer); !ok {
+		t.Errorf("Expected DelegatingTracer after ResetGlobalTracer, got %T", GlobalTracer())
+	}
+}
+
+func TestGlobalTracerConcurrentAccess(t *testing.T) {
+	defer globalTracer.Store(loadGlobalTracer())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			SetGlobalTracer(testTracer{})
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		StartSpan("op").Finish()
+		_ = IsGlobalTracerRegistered()
+	}
+	<-done
+}
+
+func TestSetGlobalTracerWithRestore(t *testing.T) {
+	defer globalTracer.Store(loadGlobalTracer())
+	ResetGlobalTracer()
+
+	restore := SetGlobalTracerWithRestore(testTracer{})
+	if _, ok := GlobalTracer().(testTracer); !ok || !IsGlobalTracerRegistered() {
+		t.Errorf("Expected testTracer to be registered, got %T", GlobalTracer())
+	}
+	restoreInner := SetGlobalTracerWithRestore(NoopTracer{})
+	restoreInner()
+	if _, ok := GlobalTracer().(testTracer); !ok {
+		t.Errorf("Expected testTracer to be restored, got %T", GlobalTracer())
+	}
+	restore()
+	if IsGlobalTracerRegistered() {
+		t.Errorf("Expected the unregistered state to be restored")
 	}
 }
@@ -1,11 +1,66 @@
 package opentracing
 
-import "context"
+import (
+	"context"
+	"sync/atomic"
+)
 
 type contextKey struct{}
 
 var activeSpanKey = contextKey{}
 
+type tracerContextKey struct{}
+
+var preferredTracerKey = tracerContextKey{}
+
+type spanContextContextKey struct{}
+
+var remoteSpanContextKey = spanContextContextKey{}
+
+// noopContextPassthrough 不为0时，StartSpanFromContext 对 noop tracer 原样返回`ctx`
+var noopContextPassthrough int32
+
+// SetNoopContextPassthrough 控制 StartSpanFromContext 和 StartSpanFromContextWithTracer 在
+// tracer 是 NoopTracer（或实现了 NoopAware 并返回 true）时的行为。
+//
+// 默认(false)情况下，返回的context中仍然包含返回的空操作 Span，与其他 Tracer 的行为一致；
+// 设置为 true 后，将原样返回传入的`ctx`，不再有任何内存分配，
+// 此时对返回的context调用 SpanFromContext 会得到`ctx`中原有的Span（通常是`nil`）而不是空操作 Span。
+func SetNoopContextPassthrough(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&noopContextPassthrough, v)
+}
+
+// isNoopTracer 返回 tracer 是否为空操作(no-op)的实现
+func isNoopTracer(tracer Tracer) bool {
+	switch t := tracer.(type) {
+	case NoopTracer, *NoopTracer:
+		return true
+	case NoopAware:
+		return t.IsNoop()
+	}
+	return false
+}
+
+// ContextWithTracer 返回一个新的`context.Context`，它包含对tracer的引用。
+// StartSpanFromContext 会优先使用该tracer而不是 GlobalTracer()，
+// 这使得在同一个进程里可以对不同的模块使用不同的tracer。
+// 如果tracer为空(nil)，将返回一个不包含首选tracer的新context。
+func ContextWithTracer(ctx context.Context, tracer Tracer) context.Context {
+	return context.WithValue(ctx, preferredTracerKey, tracer)
+}
+
+// TracerFromContext 返回之前通过 ContextWithTracer 放入`ctx`中的`Tracer`，如果没有找到会返回`nil`。
+func TracerFromContext(ctx context.Context) Tracer {
+	if tracer, ok := ctx.Value(preferredTracerKey).(Tracer); ok {
+		return tracer
+	}
+	return nil
+}
+
 // ContextWithSpan 返回一个新的`context.Context`，它包含对span的引用。
 // 如果span为空(nil)，将返回一个不包含活跃span的新context。
 func ContextWithSpan(ctx context.Context, span Span) context.Context {
@@ -19,6 +74,9 @@ func ContextWithSpan(ctx context.Context, span Span) context.Context {
 
 // SpanFromContext 返回`ctx`中之前的`Span`(即上一个函数写进去的span)，如果没有找到`span`会返回`nil`
 //
+// 如果`ctx`中没有`Span`（包括被 ContextWithSpan(ctx, nil) 重置的情况），
+// 并且全局 Tracer 实现了 TracerSpanFromContextExtension，那么会返回它的 SpanFromContextHook 找到的`Span`。
+//
 // 注意： context.Context != SpanContext: 前者是Go的进程内上下文传播机制，
 // 后者包含有OpenTracing的Span识别和携带信息。
 func SpanFromContext(ctx context.Context) Span {
@@ -26,6 +84,35 @@ func SpanFromContext(ctx context.Context) Span {
 	if sp, ok := val.(Span); ok {
 		return sp
 	}
+	if tracerWithHook, ok := GlobalTracer().(TracerSpanFromContextExtension); ok {
+		if sp, ok := tracerWithHook.SpanFromContextHook(ctx); ok {
+			return sp
+		}
+	}
+	return nil
+}
+
+// ContextWithSpanContext 返回一个新的`context.Context`，它包含对`sc`的引用，
+// 例如让 Extract 得到的远程 SpanContext 在还没有开始 Span 的时候通过context传递给更底层的代码：
+//
+//    sc, _ := tracer.Extract(opentracing.HTTPHeaders, carrier)
+//    ctx = opentracing.ContextWithSpanContext(ctx, sc)
+//    ...
+//    sp, ctx := opentracing.StartSpanFromContext(ctx, "handler") // 以 sc 为父级
+//
+// 如果`ctx`中同时有活跃的 Span，StartSpanFromContext 会优先使用该 Span 作为父级。
+// 如果`sc`为空(nil)，将返回一个不包含 SpanContext 的新context。
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteSpanContextKey, sc)
+}
+
+// SpanContextFromContext 返回之前通过 ContextWithSpanContext 放入`ctx`中的`SpanContext`，如果没有找到会返回`nil`。
+//
+// 它不会返回`ctx`中活跃的 Span 的 SpanContext，需要时请使用 SpanFromContext(ctx).Context()。
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if sc, ok := ctx.Value(remoteSpanContextKey).(SpanContext); ok {
+		return sc
+	}
 	return nil
 }
 
@@ -33,6 +120,8 @@ func SpanFromContext(ctx context.Context) Span {
 // 使用在`ctx`中找到的 Span 作为新Span的`ChildOfRef`(即新span的父节点是ctx中的那个span)。
 // 如果没有找到任何父级， StartSpanFromContext 将创建一个根(root)Span
 //
+// 新的Span由 TracerFromContext(ctx) 创建，如果`ctx`中没有tracer，则使用 GlobalTracer()。
+//
 // 第二个返回值是一个 context.Context 对象，包含有返回的 Span
 //
 // 样例:
@@ -43,7 +132,11 @@ func SpanFromContext(ctx context.Context) Span {
 //        ...
 //    }
 func StartSpanFromContext(ctx context.Context, operationName string, opts ...StartSpanOption) (Span, context.Context) {
-	return StartSpanFromContextWithTracer(ctx, GlobalTracer(), operationName, opts...)
+	tracer := TracerFromContext(ctx)
+	if tracer == nil {
+		tracer = GlobalTracer()
+	}
+	return StartSpanFromContextWithTracer(ctx, tracer, operationName, opts...)
 }
 
 // StartSpanFromContextWithTracer 以`operationName`开始并返回一个Span，
@@ -52,10 +145,81 @@ func StartSpanFromContext(ctx context.Context, operationName string, opts ...Sta
 //
 // 它的行为与 StartSpanFromContext 相比，除了显示的tracer之外，其他是完全相同的。
 // 对于 StartSpanFromContext, 它使用了 GlobalTracer。
+//
+// 如果`ctx`中没有 Span，但有通过 ContextWithSpanContext 放入的 SpanContext，则以它作为父级。
+//
+// `ctx`中的父级会被追加到`opts`中已有的引用之后，而不是替换它们。因此可以把从远端提取(Extract)的
+// SpanContext 与`ctx`中的父级合并到同一个 Span 上，并用 ActiveSpanReference 调整`ctx`中的父级的引用类型：
+//
+//    remote, _ := tracer.Extract(opentracing.TextMap, carrier)
+//    sp, ctx := opentracing.StartSpanFromContextWithTracer(ctx, tracer, "consume",
+//        opentracing.ChildOf(remote), opentracing.ActiveSpanReference(opentracing.FollowsFromRef))
+//
+// 如果`opts`中包含 IgnoreActiveSpan()，则不会把`ctx`中的 Span 或 SpanContext 作为父级，新的Span是否为根Span只取决于`opts`中的引用。
+//
+// 如果tracer是空操作(no-op)的实现，将不会查找父级Span和构造引用，见 SetNoopContextPassthrough。
 func StartSpanFromContextWithTracer(ctx context.Context, tracer Tracer, operationName string, opts ...StartSpanOption) (Span, context.Context) {
-	if parentSpan := SpanFromContext(ctx); parentSpan != nil {
-		opts = append(opts, ChildOf(parentSpan.Context()))
+	if isNoopTracer(tracer) {
+		span := tracer.StartSpan(operationName, opts...)
+		if atomic.LoadInt32(&noopContextPassthrough) != 0 {
+			return span, ctx
+		}
+		return span, ContextWithSpan(ctx, span)
+	}
+	if !hasIgnoreActiveSpan(opts) {
+		refType := activeSpanReferenceType(opts)
+		if parentSpan := SpanFromContext(ctx); parentSpan != nil {
+			opts = append(opts, SpanReference{Type: refType, ReferencedContext: parentSpan.Context()})
+		} else if sc := SpanContextFromContext(ctx); sc != nil {
+			opts = append(opts, SpanReference{Type: refType, ReferencedContext: sc})
+		}
 	}
 	span := tracer.StartSpan(operationName, opts...)
 	return span, ContextWithSpan(ctx, span)
 }
+
+// IgnoreActiveSpan 返回一个 StartSpanOption，它使 StartSpanFromContext 和 StartSpanFromContextWithTracer
+// 不把`ctx`中的 Span（或 SpanContext）作为新Span的父级，例如在处理请求的过程中触发一个定时任务时强制开始一条新的链路：
+//
+//    sp, ctx := opentracing.StartSpanFromContext(ctx, "cron.job", opentracing.IgnoreActiveSpan())
+//
+// 返回的 Span 仍然会被放进返回的context中。对 Tracer.StartSpan 来说，该选项不起作用。
+func IgnoreActiveSpan() StartSpanOption {
+	return ignoreActiveSpanOption{}
+}
+
+type ignoreActiveSpanOption struct{}
+
+// Apply 实现`StartSpanOption`接口.
+func (ignoreActiveSpanOption) Apply(*StartSpanOptions) {}
+
+func hasIgnoreActiveSpan(opts []StartSpanOption) bool {
+	for _, o := range opts {
+		if _, ok := o.(ignoreActiveSpanOption); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ActiveSpanReference 返回一个 StartSpanOption，它使 StartSpanFromContext 和 StartSpanFromContextWithTracer
+// 以 refType（而不是默认的 ChildOfRef）引用`ctx`中的 Span（或 SpanContext）。
+// 有多个该选项时以最后一个为准。对 Tracer.StartSpan 来说，该选项不起作用。
+func ActiveSpanReference(refType SpanReferenceType) StartSpanOption {
+	return activeSpanReferenceOption(refType)
+}
+
+type activeSpanReferenceOption SpanReferenceType
+
+// Apply 实现`StartSpanOption`接口.
+func (activeSpanReferenceOption) Apply(*StartSpanOptions) {}
+
+func activeSpanReferenceType(opts []StartSpanOption) SpanReferenceType {
+	refType := ChildOfRef
+	for _, o := range opts {
+		if o, ok := o.(activeSpanReferenceOption); ok {
+			refType = SpanReferenceType(o)
+		}
+	}
+	return refType
+}
@@ -69,6 +69,57 @@ func TestContextWithSpanWithExtension(t *testing.T) {
 	}
 }
 
+type foreignCtxKey struct{}
+
+// adapterTracer 模拟一个桥接到其他跟踪系统的适配器，
+// 其他系统把自己的span存放在 foreignCtxKey 下。
+type adapterTracer struct {
+	testTracer
+}
+
+func (adapterTracer) SpanFromContextHook(ctx context.Context) (Span, bool) {
+	if id, ok := ctx.Value(foreignCtxKey{}).(int); ok {
+		return testSpan{OperationName: "foreign", spanContext: testSpanContext{FakeID: id}}, true
+	}
+	return nil, false
+}
+
+var _ TracerSpanFromContextExtension = adapterTracer{}
+
+func TestSpanFromContextWithExtension(t *testing.T) {
+	defer globalTracer.Store(loadGlobalTracer())
+
+	foreignCtx := context.WithValue(context.Background(), foreignCtxKey{}, 42)
+
+	// NoopTracer 没有实现该扩展
+	if sp := SpanFromContext(foreignCtx); sp != nil {
+		t.Errorf("Expected nil span with noop tracer, found %+v", sp)
+	}
+
+	SetGlobalTracer(adapterTracer{})
+
+	sp := SpanFromContext(foreignCtx)
+	if sp == nil || sp.Context().(testSpanContext).FakeID != 42 {
+		t.Fatalf("SpanFromContextHook was not used, found %+v", sp)
+	}
+	if sp := SpanFromContext(context.Background()); sp != nil {
+		t.Errorf("Expected nil span when hook finds nothing, found %+v", sp)
+	}
+
+	// 由 ContextWithSpan 放置的span优先
+	ownSpan := testSpan{OperationName: "own", spanContext: testSpanContext{FakeID: 7}}
+	ctx := ContextWithSpan(foreignCtx, ownSpan)
+	if sp := SpanFromContext(ctx); !ownSpan.Equal(sp) {
+		t.Errorf("Expected own span to take precedence, found %+v", sp)
+	}
+
+	// StartSpanFromContext 同样使用该hook找到父级
+	child, _ := StartSpanFromContext(foreignCtx, "child")
+	if sc := child.Context().(testSpanContext); !sc.HasParent || sc.FakeID != 42 {
+		t.Errorf("Expected child of foreign span, found %+v", sc)
+	}
+}
+
 func TestStartSpanFromContext(t *testing.T) {
 	testTracer := testTracer{}
 
@@ -99,6 +150,126 @@ func TestStartSpanFromContext(t *testing.T) {
 	}
 }
 
+func TestContextWithSpanContext(t *testing.T) {
+	if sc := SpanContextFromContext(context.Background()); sc != nil {
+		t.Errorf("Expected nil SpanContext, found %+v", sc)
+	}
+	remote := testSpanContext{FakeID: 7}
+	ctx := ContextWithSpanContext(context.Background(), remote)
+	if sc := SpanContextFromContext(ctx); sc != remote {
+		t.Errorf("Not the same SpanContext returned from context, found %+v", sc)
+	}
+
+	tracer := &parentRecordingTracer{}
+	StartSpanFromContextWithTracer(ctx, tracer, "child")
+	// 活跃的 Span 优先
+	active := testSpan{spanContext: testSpanContext{FakeID: 8}}
+	StartSpanFromContextWithTracer(ContextWithSpan(ctx, active), tracer, "child")
+	StartSpanFromContextWithTracer(ctx, tracer, "root", IgnoreActiveSpan())
+	assert.Equal(t, [][]SpanContext{{remote}, {active.spanContext}, nil}, tracer.parents)
+
+	if sc := SpanContextFromContext(ContextWithSpanContext(ctx, nil)); sc != nil {
+		t.Errorf("Not able to reset SpanContext in context, found %+v", sc)
+	}
+}
+
+// parentRecordingTracer 记录每个新 Span 的 ChildOf 引用
+type parentRecordingTracer struct {
+	testTracer
+	parents [][]SpanContext
+}
+
+func (r *parentRecordingTracer) StartSpan(operationName string, opts ...StartSpanOption) Span {
+	r.parents = append(r.parents, NewStartSpanOptions(opts...).ChildOfReferences())
+	return r.testTracer.StartSpan(operationName, opts...)
+}
+
+func TestStartSpanFromContextIgnoreActiveSpan(t *testing.T) {
+	parentCtx := ContextWithSpan(context.Background(), &testSpan{})
+
+	root, ctx := StartSpanFromContextWithTracer(parentCtx, testTracer{}, "cron", IgnoreActiveSpan())
+	if root.Context().(testSpanContext).HasParent {
+		t.Errorf("Should have ignored the parent in the context: %v", root)
+	}
+	if !root.(testSpan).Equal(SpanFromContext(ctx)) {
+		t.Errorf("Unable to find the new span in context: %v", ctx)
+	}
+
+	// 显式的引用仍然有效
+	child, _ := StartSpanFromContextWithTracer(parentCtx, testTracer{}, "child",
+		IgnoreActiveSpan(), ChildOf(root.Context()))
+	if !child.Context().(testSpanContext).HasParent {
+		t.Errorf("Explicit references should still apply: %v", child)
+	}
+}
+
+// optionsRecordingTracer 记录最后一次 StartSpan 的 StartSpanOptions
+type optionsRecordingTracer struct {
+	testTracer
+	sso *StartSpanOptions
+}
+
+func (r optionsRecordingTracer) StartSpan(operationName string, opts ...StartSpanOption) Span {
+	*r.sso = NewStartSpanOptions(opts...)
+	return r.testTracer.StartSpan(operationName, opts...)
+}
+
+func TestStartSpanFromContextMergesReferences(t *testing.T) {
+	local := testSpanContext{FakeID: 1}
+	remote := testSpanContext{FakeID: 2}
+	ctx := ContextWithSpan(context.Background(), testSpan{spanContext: local})
+	tracer := optionsRecordingTracer{sso: &StartSpanOptions{}}
+
+	StartSpanFromContextWithTracer(ctx, tracer, "consume", ChildOf(remote))
+	assert.Equal(t, []SpanReference{
+		{Type: ChildOfRef, ReferencedContext: remote},
+		{Type: ChildOfRef, ReferencedContext: local},
+	}, tracer.sso.References)
+
+	StartSpanFromContextWithTracer(ctx, tracer, "consume",
+		ChildOf(remote), ActiveSpanReference(FollowsFromRef))
+	assert.Equal(t, []SpanReference{
+		{Type: ChildOfRef, ReferencedContext: remote},
+		{Type: FollowsFromRef, ReferencedContext: local},
+	}, tracer.sso.References)
+
+	StartSpanFromContextWithTracer(ContextWithSpanContext(context.Background(), local), tracer, "consume",
+		ActiveSpanReference(FollowsFromRef))
+	assert.Equal(t, []SpanReference{
+		{Type: FollowsFromRef, ReferencedContext: local},
+	}, tracer.sso.References)
+}
+
+func TestContextWithTracer(t *testing.T) {
+	if tracer := TracerFromContext(context.Background()); tracer != nil {
+		t.Errorf("Expected nil tracer, found %+v", tracer)
+	}
+
+	// 没有设置时使用 GlobalTracer
+	span, _ := StartSpanFromContext(context.Background(), "global")
+	if _, ok := span.(noopSpan); !ok {
+		t.Errorf("Expected span from the global tracer, found %+v", span)
+	}
+
+	ctx := ContextWithTracer(context.Background(), testTracer{})
+	if _, ok := TracerFromContext(ctx).(testTracer); !ok {
+		t.Errorf("Not the same tracer returned from context, found %+v", TracerFromContext(ctx))
+	}
+	parent, ctx := StartSpanFromContext(ctx, "parent")
+	if _, ok := parent.(testSpan); !ok {
+		t.Fatalf("Expected span from the context tracer, found %+v", parent)
+	}
+	child, _ := StartSpanFromContext(ctx, "child")
+	if !child.Context().(testSpanContext).HasParent {
+		t.Errorf("Failed to find parent: %v", child)
+	}
+
+	ctx = ContextWithTracer(ctx, nil)
+	if tracer := TracerFromContext(ctx); tracer != nil {
+		t.Errorf("Not able to reset tracer in context, found %+v", tracer)
+	}
+}
+
 func TestStartSpanFromContextOptions(t *testing.T) {
 	testTracer := testTracer{}
 
@@ -120,3 +291,49 @@ func TestStartSpanFromContextOptions(t *testing.T) {
 	assert.Equal(t, childSpan.(testSpan).Tags["component"], nil)
 	assert.Equal(t, childSpan.(testSpan).StartTime, childStartTime)
 }
+
+func TestStartSpanFromContextNoopPassthrough(t *testing.T) {
+	parent := &noopSpan{}
+	ctx := ContextWithSpan(context.Background(), parent)
+
+	sp, ctx2 := StartSpanFromContextWithTracer(ctx, NoopTracer{}, "op")
+	assert.Equal(t, defaultNoopSpan, sp)
+	assert.Equal(t, sp, SpanFromContext(ctx2))
+
+	SetNoopContextPassthrough(true)
+	defer SetNoopContextPassthrough(false)
+
+	sp, ctx2 = StartSpanFromContextWithTracer(ctx, NoopTracer{}, "op")
+	assert.Equal(t, defaultNoopSpan, sp)
+	assert.Equal(t, ctx, ctx2)
+
+	bg := context.Background()
+	_, ctx2 = StartSpanFromContextWithTracer(bg, NoopTracerWithExtract(nil), "op")
+	assert.Equal(t, bg, ctx2)
+	assert.Nil(t, SpanFromContext(ctx2))
+
+	// 嵌入了 NoopTracer 的类型不会被当作 noop tracer
+	_, ctx2 = StartSpanFromContextWithTracer(bg, noopExtTracer{}, "op")
+	assert.NotEqual(t, bg, ctx2)
+}
+
+func BenchmarkStartSpanFromContextNoop(b *testing.B) {
+	ctx := context.Background()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sp, _ := StartSpanFromContext(ctx, "op")
+		sp.Finish()
+	}
+}
+
+func BenchmarkStartSpanFromContextNoopPassthrough(b *testing.B) {
+	SetNoopContextPassthrough(true)
+	defer SetNoopContextPassthrough(false)
+	ctx := ContextWithSpan(context.Background(), defaultNoopSpan)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sp, _ := StartSpanFromContext(ctx, "op")
+		sp.Finish()
+	}
+}
@@ -0,0 +1,107 @@
+package opentracing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// GoOption 调整 GoWithSpan 和 TracedWaitGroup.Go 创建的 Span。
+type GoOption func(*goOptions)
+
+type goOptions struct {
+	refType SpanReferenceType
+	opts    []StartSpanOption
+}
+
+// GoReference 设置新的 Span 与`ctx`中的 Span 的引用类型，默认为 FollowsFromRef。
+func GoReference(refType SpanReferenceType) GoOption {
+	return func(o *goOptions) {
+		o.refType = refType
+	}
+}
+
+// GoStartSpanOptions 为新的 Span 添加额外的 StartSpanOption，例如 Tags。
+func GoStartSpanOptions(opts ...StartSpanOption) GoOption {
+	return func(o *goOptions) {
+		o.opts = append(o.opts, opts...)
+	}
+}
+
+// GoWithSpan 以`operationName`开始一个 Span，它以 FollowsFromRef（见 GoReference）引用`ctx`中的 Span，
+// 然后在一个新的goroutine中以包含新Span的context运行`f`，并在`f`返回时结束(Finish)该Span。
+//
+// Span 在启动goroutine之前就已经创建，所以即使调用者的 Span 先结束也不会影响引用关系。
+// 新的Span由 TracerFromContext(ctx) 创建，如果`ctx`中没有tracer，则使用 GlobalTracer()。
+//
+// 如果`f`发生panic，会在Span上设置`error=true`并记录panic的值和调用栈，结束Span之后再重新panic。
+func GoWithSpan(ctx context.Context, operationName string, f func(ctx context.Context), opts ...GoOption) {
+	span, ctx := startGoSpan(ctx, operationName, opts)
+	go runWithSpan(ctx, span, f)
+}
+
+// TracedWaitGroup 与 sync.WaitGroup 类似，它的 Go 方法与 GoWithSpan 相同，并等待goroutine结束。
+//
+// TracedWaitGroup 的零值即可使用，在第一次使用之后不能被复制。
+type TracedWaitGroup struct {
+	wg sync.WaitGroup
+}
+
+// Go 与 GoWithSpan 相同，并把该goroutine计入 Wait 需要等待的goroutine中。
+// 在 Wait 返回时，所有goroutine的 Span 都已经结束。
+func (g *TracedWaitGroup) Go(ctx context.Context, operationName string, f func(ctx context.Context), opts ...GoOption) {
+	span, ctx := startGoSpan(ctx, operationName, opts)
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		runWithSpan(ctx, span, f)
+	}()
+}
+
+// Wait 阻塞直到所有通过 Go 启动的goroutine都结束。
+func (g *TracedWaitGroup) Wait() {
+	g.wg.Wait()
+}
+
+func startGoSpan(ctx context.Context, operationName string, opts []GoOption) (Span, context.Context) {
+	o := goOptions{refType: FollowsFromRef}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	tracer := TracerFromContext(ctx)
+	if tracer == nil {
+		tracer = GlobalTracer()
+	}
+	sso := o.opts
+	if parent := SpanFromContext(ctx); parent != nil {
+		sso = append(sso[:len(sso):len(sso)], SpanReference{Type: o.refType, ReferencedContext: parent.Context()})
+	}
+	span := tracer.StartSpan(operationName, sso...)
+	return span, ContextWithSpan(ctx, span)
+}
+
+// runWithSpan 运行`f`并结束`span`，如果`f`发生panic，在重新panic之前把它记录到`span`上。
+func runWithSpan(ctx context.Context, span Span, f func(ctx context.Context)) {
+	defer FinishWithRecover(span)()
+	f(ctx)
+}
+
+// FinishWithRecover 返回一个应该直接被`defer`调用的函数，它结束(Finish) span，
+// 并且如果当前goroutine正在panic，会先在 span 上设置`error=true`，记录panic的值和调用栈，
+// 然后在结束 span 之后重新panic：
+//
+//    span := tracer.StartSpan("work")
+//    defer opentracing.FinishWithRecover(span)()
+//
+// 因为`recover()`只有在被 defer 的函数中直接调用才有效，所以它以返回闭包的形式提供，
+// 注意末尾的`()`不能省略。
+func FinishWithRecover(span Span) func() {
+	return func() {
+		if r := recover(); r != nil {
+			setErrorFields(span, fmt.Sprint(r), r)
+			span.Finish()
+			panic(r)
+		}
+		span.Finish()
+	}
+}
@@ -0,0 +1,126 @@
+package opentracing_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+func TestGoWithSpan(t *testing.T) {
+	tracer := mocktracer.New()
+	parent := tracer.StartSpan("parent")
+	ctx := opentracing.ContextWithTracer(context.Background(), tracer)
+	ctx = opentracing.ContextWithSpan(ctx, parent)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	opentracing.GoWithSpan(ctx, "async", func(ctx context.Context) {
+		defer wg.Done()
+		opentracing.SpanFromContext(ctx).LogKV("event", "working")
+	}, opentracing.GoStartSpanOptions(opentracing.Tag{Key: "worker", Value: 1}))
+	// 调用者的 Span 可以先于goroutine结束
+	parent.Finish()
+	wg.Wait()
+
+	require.Eventually(t, func() bool { return len(tracer.FinishedSpans()) == 2 }, time.Second, time.Millisecond)
+	child := tracer.FinishedSpans()[1]
+	assert.Equal(t, "async", child.OperationName)
+	assert.Equal(t, parent.Context().(mocktracer.MockSpanContext).SpanID, child.ParentID)
+	assert.Equal(t, 1, child.Tag("worker"))
+	assert.Len(t, child.Logs(), 1)
+}
+
+func TestTracedWaitGroup(t *testing.T) {
+	tracer := mocktracer.New()
+	parent := tracer.StartSpan("parent")
+	ctx := opentracing.ContextWithTracer(context.Background(), tracer)
+	ctx = opentracing.ContextWithSpan(ctx, parent)
+
+	record := &referenceRecordingTracer{Tracer: tracer}
+	ctx = opentracing.ContextWithTracer(ctx, record)
+
+	var g opentracing.TracedWaitGroup
+	g.Go(ctx, "follows", func(ctx context.Context) {})
+	g.Go(ctx, "child", func(ctx context.Context) {}, opentracing.GoReference(opentracing.ChildOfRef))
+	g.Wait()
+
+	// Wait 返回时所有goroutine的 Span 都已经结束
+	assert.Len(t, tracer.FinishedSpans(), 2)
+	assert.ElementsMatch(t, []opentracing.SpanReferenceType{opentracing.FollowsFromRef, opentracing.ChildOfRef}, record.refs)
+	parent.Finish()
+}
+
+func TestGoWithSpanPanic(t *testing.T) {
+	tracer := mocktracer.New()
+	span := tracer.StartSpan("panicky")
+
+	assert.PanicsWithValue(t, "boom", func() {
+		opentracing.RunWithSpan(context.Background(), span, func(ctx context.Context) { panic("boom") })
+	})
+
+	spans := tracer.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, true, spans[0].Tag("error"))
+	require.Len(t, spans[0].Logs(), 1)
+	fields := spans[0].Logs()[0].Fields
+	require.Len(t, fields, 4)
+	assert.Equal(t, "message", fields[1].Key)
+	assert.Equal(t, "boom", fields[1].ValueString)
+	assert.Equal(t, "error.object", fields[2].Key)
+	assert.Equal(t, "stack", fields[3].Key)
+	assert.Contains(t, fields[3].ValueString, "goroutine")
+}
+
+// referenceRecordingTracer 记录每个新 Span 的引用类型
+type referenceRecordingTracer struct {
+	opentracing.Tracer
+	mu   sync.Mutex
+	refs []opentracing.SpanReferenceType
+}
+
+func (r *referenceRecordingTracer) StartSpan(operationName string, opts ...opentracing.StartSpanOption) opentracing.Span {
+	sso := opentracing.StartSpanOptions{}
+	for _, o := range opts {
+		o.Apply(&sso)
+	}
+	r.mu.Lock()
+	for _, ref := range sso.References {
+		r.refs = append(r.refs, ref.Type)
+	}
+	r.mu.Unlock()
+	return r.Tracer.StartSpan(operationName, opts...)
+}
+
+func TestFinishWithRecover(t *testing.T) {
+	tracer := mocktracer.New()
+
+	func() {
+		span := tracer.StartSpan("ok")
+		defer opentracing.FinishWithRecover(span)()
+	}()
+	spans := tracer.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.Nil(t, spans[0].Tag("error"))
+	assert.Empty(t, spans[0].Logs())
+
+	assert.PanicsWithValue(t, "boom", func() {
+		span := tracer.StartSpan("panicky")
+		defer opentracing.FinishWithRecover(span)()
+		panic("boom")
+	})
+	spans = tracer.FinishedSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, true, spans[1].Tag("error"))
+	require.Len(t, spans[1].Logs(), 1)
+	fields := spans[1].Logs()[0].Fields
+	assert.Equal(t, "message", fields[1].Key)
+	assert.Equal(t, "boom", fields[1].ValueString)
+	assert.Equal(t, "stack", fields[3].Key)
+}
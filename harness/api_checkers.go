@@ -18,11 +18,20 @@ additionally check if two Spans are part of the same trace, and if a Span and a
 are part of the same trace. Implementing an APICheckProbe provides additional assertions that
 your tracer is working properly.
 
+The suite also calls CheckConcurrentSpanUsage, which can be used on its own as well: it uses a
+single Span from many goroutines at once, so running the tests with `go test -race` reports data
+races in the Span implementation.
+
+RunPropagationTest checks Inject/Extract round trips of SpanContexts with baggage through all
+the built-in carrier formats, skipping the formats the tracer does not support.
+
 */
 package harness
 
 import (
 	"bytes"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -164,6 +173,32 @@ func (s *APICheckSuite) TestStartSpanWithParent() {
 	parentSpan.Finish()
 }
 
+// TestStartSpanWithOptions checks if a Tracer accepts all the built-in StartSpanOptions at once:
+// an explicit start time, tags, and multiple references of both types.
+func (s *APICheckSuite) TestStartSpanWithOptions() {
+	parentSpan := s.tracer.StartSpan("Hubert")
+	precedingSpan := s.tracer.StartSpan("Cubert", opentracing.ChildOf(parentSpan.Context()))
+	precedingSpan.Finish()
+
+	start := time.Now().Add(-time.Second)
+	span := s.tracer.StartSpan(
+		"Professor",
+		opentracing.ChildOf(parentSpan.Context()),
+		opentracing.FollowsFrom(precedingSpan.Context()),
+		opentracing.StartTime(start),
+		opentracing.Tags{"age": 160, "employer": "Planet Express"},
+		opentracing.Tag{Key: "inventor", Value: true})
+	s.NotNil(span)
+	span.FinishWithOptions(opentracing.FinishOptions{FinishTime: start.Add(time.Millisecond)})
+	if s.opts.Probe != nil {
+		s.True(s.opts.Probe.SameTrace(parentSpan, span))
+	} else {
+		s.T().Log("harness.Probe not specified, skipping")
+	}
+
+	parentSpan.Finish()
+}
+
 // TestSetOperationName attempts to set the operation name on a span after it has been created.
 func (s *APICheckSuite) TestSetOperationName() {
 	span := s.tracer.StartSpan("").SetOperationName("Farnsworth")
@@ -373,14 +408,14 @@ func (s *APICheckSuite) TestUnknownFormat() {
 
 	err := span.Tracer().Inject(span.Context(), customFormat, nil)
 	if s.opts.CheckInject {
-		s.Equal(opentracing.ErrUnsupportedFormat, err)
+		s.ErrorIs(err, opentracing.ErrUnsupportedFormat)
 	} else {
 		s.T().Log("CheckInject capability not set, skipping")
 	}
 	ctx, err := s.tracer.Extract(customFormat, nil)
 	s.Nil(ctx)
 	if s.opts.CheckExtract {
-		s.Equal(opentracing.ErrUnsupportedFormat, err)
+		s.ErrorIs(err, opentracing.ErrUnsupportedFormat)
 	} else {
 		s.T().Log("CheckExtract capability not set, skipping")
 	}
@@ -404,21 +439,21 @@ func (s *APICheckSuite) TestInvalidInject() {
 
 	// binary inject
 	err := span.Tracer().Inject(ForeignSpanContext{}, opentracing.Binary, new(bytes.Buffer))
-	s.Equal(opentracing.ErrInvalidSpanContext, err, "Foreign SpanContext should return invalid error")
+	s.ErrorIs(err, opentracing.ErrInvalidSpanContext, "Foreign SpanContext should return invalid error")
 	err = span.Tracer().Inject(span.Context(), opentracing.Binary, NotACarrier{})
-	s.Equal(opentracing.ErrInvalidCarrier, err, "Carrier that's not io.Writer should return error")
+	s.ErrorIs(err, opentracing.ErrInvalidCarrier, "Carrier that's not io.Writer should return error")
 
 	// text inject
 	err = span.Tracer().Inject(ForeignSpanContext{}, opentracing.TextMap, opentracing.TextMapCarrier{})
-	s.Equal(opentracing.ErrInvalidSpanContext, err, "Foreign SpanContext should return invalid error")
+	s.ErrorIs(err, opentracing.ErrInvalidSpanContext, "Foreign SpanContext should return invalid error")
 	err = span.Tracer().Inject(span.Context(), opentracing.TextMap, NotACarrier{})
-	s.Equal(opentracing.ErrInvalidCarrier, err, "Carrier that's not TextMapWriter should return error")
+	s.ErrorIs(err, opentracing.ErrInvalidCarrier, "Carrier that's not TextMapWriter should return error")
 
 	// HTTP inject
 	err = span.Tracer().Inject(ForeignSpanContext{}, opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier{})
-	s.Equal(opentracing.ErrInvalidSpanContext, err, "Foreign SpanContext should return invalid error")
+	s.ErrorIs(err, opentracing.ErrInvalidSpanContext, "Foreign SpanContext should return invalid error")
 	err = span.Tracer().Inject(span.Context(), opentracing.HTTPHeaders, NotACarrier{})
-	s.Equal(opentracing.ErrInvalidCarrier, err, "Carrier that's not TextMapWriter should return error")
+	s.ErrorIs(err, opentracing.ErrInvalidCarrier, "Carrier that's not TextMapWriter should return error")
 }
 
 // TestInvalidExtract checks if errors are returned when Extract is called with invalid inputs.
@@ -430,17 +465,17 @@ func (s *APICheckSuite) TestInvalidExtract() {
 
 	// binary extract
 	ctx, err := span.Tracer().Extract(opentracing.Binary, NotACarrier{})
-	s.Equal(opentracing.ErrInvalidCarrier, err, "Carrier that's not io.Reader should return error")
+	s.ErrorIs(err, opentracing.ErrInvalidCarrier, "Carrier that's not io.Reader should return error")
 	s.Nil(ctx)
 
 	// text extract
 	ctx, err = span.Tracer().Extract(opentracing.TextMap, NotACarrier{})
-	s.Equal(opentracing.ErrInvalidCarrier, err, "Carrier that's not TextMapReader should return error")
+	s.ErrorIs(err, opentracing.ErrInvalidCarrier, "Carrier that's not TextMapReader should return error")
 	s.Nil(ctx)
 
 	// HTTP extract
 	ctx, err = span.Tracer().Extract(opentracing.HTTPHeaders, NotACarrier{})
-	s.Equal(opentracing.ErrInvalidCarrier, err, "Carrier that's not TextMapReader should return error")
+	s.ErrorIs(err, opentracing.ErrInvalidCarrier, "Carrier that's not TextMapReader should return error")
 	s.Nil(ctx)
 
 	span.Finish()
@@ -470,3 +505,40 @@ func (s *APICheckSuite) TestMultiBaggage() {
 	}
 	span.Finish()
 }
+
+// TestConcurrentSpanUsage checks that a span can be used from multiple goroutines, see CheckConcurrentSpanUsage.
+func (s *APICheckSuite) TestConcurrentSpanUsage() {
+	CheckConcurrentSpanUsage(s.T(), s.tracer)
+}
+
+// CheckConcurrentSpanUsage starts a span and calls SetTag, LogFields, LogKV, SetBaggageItem,
+// BaggageItem and Context on it from dozens of goroutines at once, before finally calling Finish.
+// It does not assert anything by itself, but running it with `go test -race` reports any data
+// race in the tracer's Span implementation.
+func CheckConcurrentSpanUsage(t *testing.T, tracer opentracing.Tracer) {
+	const goroutines = 50
+	const iterations = 20
+
+	span := tracer.StartSpan("concurrent")
+	var wg sync.WaitGroup
+	wg.Add(goroutines)
+	for g := 0; g < goroutines; g++ {
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				key := fmt.Sprintf("key-%d", (g+i)%5)
+				span.SetTag(key, i)
+				span.LogFields(log.String("event", "concurrent"), log.Int("goroutine", g))
+				span.LogKV("iteration", i)
+				span.SetBaggageItem(key, "value")
+				span.BaggageItem(key)
+				span.Context().ForeachBaggageItem(func(k, v string) bool { return true })
+			}
+		}(g)
+	}
+	wg.Wait()
+	span.Finish()
+	if span.Context() == nil {
+		t.Error("Span.Context() returned nil after concurrent usage")
+	}
+}
@@ -0,0 +1,100 @@
+package harness
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RunPropagationTest checks that tracer can Inject a SpanContext into each of the built-in
+// carrier formats (TextMap, HTTPHeaders and Binary) and Extract it again. It covers spans
+// without baggage, with several baggage items and with baggage values containing special
+// characters, and asserts that the extracted baggage equals the injected baggage.
+//
+// Formats for which Inject or Extract fail with opentracing.ErrUnsupportedFormat are skipped.
+// Of the given options only UseProbe is used: if a probe is provided, the extracted context
+// must also be the same span as the injected one. Otherwise, if the contexts implement
+// opentracing.TraceIdentifiable, their trace and span IDs are compared.
+func RunPropagationTest(t *testing.T, tracer opentracing.Tracer, opts ...APICheckOption) {
+	s := &APICheckSuite{}
+	for _, o := range opts {
+		o(s)
+	}
+
+	formats := []struct {
+		name       string
+		format     interface{}
+		newCarrier func() interface{}
+	}{
+		{"TextMap", opentracing.TextMap, func() interface{} { return opentracing.TextMapCarrier{} }},
+		{"HTTPHeaders", opentracing.HTTPHeaders, func() interface{} { return opentracing.HTTPHeadersCarrier(http.Header{}) }},
+		{"Binary", opentracing.Binary, func() interface{} { return &bytes.Buffer{} }},
+	}
+	baggageCases := []struct {
+		name    string
+		baggage map[string]string
+	}{
+		{"NoBaggage", map[string]string{}},
+		{"MultipleBaggage", map[string]string{"user-id": "42", "tenant": "acme", "region": "eu-west-1"}},
+		{"SpecialCharacters", map[string]string{"query": "a b&c=d;e,f", "unicode": "héllo 世界", "percent": "100%"}},
+	}
+
+	for _, f := range formats {
+		f := f
+		t.Run(f.name, func(t *testing.T) {
+			for _, bc := range baggageCases {
+				bc := bc
+				t.Run(bc.name, func(t *testing.T) {
+					span := tracer.StartSpan("propagation")
+					defer span.Finish()
+					for k, v := range bc.baggage {
+						span.SetBaggageItem(k, v)
+					}
+
+					carrier := f.newCarrier()
+					err := tracer.Inject(span.Context(), f.format, carrier)
+					if opentracing.IsUnsupportedFormat(err) {
+						t.Skipf("Inject does not support the %s format", f.name)
+					}
+					require.NoError(t, err, "Inject")
+
+					extracted, err := tracer.Extract(f.format, carrier)
+					if opentracing.IsUnsupportedFormat(err) {
+						t.Skipf("Extract does not support the %s format", f.name)
+					}
+					require.NoError(t, err, "Extract")
+					require.NotNil(t, extracted, "Extract returned a nil SpanContext")
+
+					assert.Equal(t, bc.baggage, baggageOf(extracted), "extracted baggage")
+					assertSameSpan(t, s.opts.Probe, span, extracted)
+				})
+			}
+		})
+	}
+}
+
+func baggageOf(sc opentracing.SpanContext) map[string]string {
+	baggage := map[string]string{}
+	sc.ForeachBaggageItem(func(k, v string) bool {
+		baggage[k] = v
+		return true
+	})
+	return baggage
+}
+
+func assertSameSpan(t *testing.T, probe APICheckProbe, span opentracing.Span, extracted opentracing.SpanContext) {
+	if probe != nil {
+		assert.True(t, probe.SameSpanContext(span, extracted), "extracted context is not the injected span")
+		return
+	}
+	injected, ok1 := span.Context().(opentracing.TraceIdentifiable)
+	got, ok2 := extracted.(opentracing.TraceIdentifiable)
+	if ok1 && ok2 {
+		assert.Equal(t, injected.TraceIDString(), got.TraceIDString(), "extracted trace ID")
+		assert.Equal(t, injected.SpanIDString(), got.SpanIDString(), "extracted span ID")
+	}
+}
@@ -0,0 +1,13 @@
+package opentracing_test
+
+import (
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/harness"
+)
+
+func TestConcurrentSpanUsage(t *testing.T) {
+	harness.CheckConcurrentSpanUsage(t, opentracing.NoopTracer{})
+	harness.CheckConcurrentSpanUsage(t, opentracing.ExportedTestTracer)
+}
@@ -0,0 +1,10 @@
+// Package log 定义了 Span.LogFields() 使用的强类型的日志字段 Field，
+// 以及 Tracer 的实现用于读取这些字段的 Encoder 接口：
+//
+//     span.LogFields(
+//         log.String("event", "soft error"),
+//         log.String("type", "cache timeout"),
+//         log.Int("waited.millis", 1500))
+//
+// 该包是本仓库的一部分，因此使用本仓库不需要额外依赖上游的 opentracing-go 模块。
+package log
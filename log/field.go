@@ -21,14 +21,14 @@ const (
 	objectType
 	lazyLoggerType
 	noopType
+	stringsType
+	int64sType
 )
 
-// Field instances are constructed via LogBool, LogString, and so on.
-// Tracing implementations may then handle them via the Field.Marshal
-// method.
+// Field 实例通过 String、Bool 等函数构造。
+// Tracer 的实现可以通过 Field.Marshal 方法处理它们。
 //
-// "heavily influenced by" (i.e., partially stolen from)
-// https://github.com/uber-go/zap
+// "深受其影响"（也就是部分照搬自） https://github.com/uber-go/zap
 type Field struct {
 	key          string
 	fieldType    fieldType
@@ -37,7 +37,7 @@ type Field struct {
 	interfaceVal interface{}
 }
 
-// String adds a string-valued key:value pair to a Span.LogFields() record
+// String 向 Span.LogFields() 的记录中添加一个值为 string 类型的键值对
 func String(key, val string) Field {
 	return Field{
 		key:       key,
@@ -46,7 +46,7 @@ func String(key, val string) Field {
 	}
 }
 
-// Bool adds a bool-valued key:value pair to a Span.LogFields() record
+// Bool 向 Span.LogFields() 的记录中添加一个值为 bool 类型的键值对
 func Bool(key string, val bool) Field {
 	var numericVal int64
 	if val {
@@ -59,7 +59,7 @@ func Bool(key string, val bool) Field {
 	}
 }
 
-// Int adds an int-valued key:value pair to a Span.LogFields() record
+// Int 向 Span.LogFields() 的记录中添加一个值为 int 类型的键值对
 func Int(key string, val int) Field {
 	return Field{
 		key:        key,
@@ -68,7 +68,7 @@ func Int(key string, val int) Field {
 	}
 }
 
-// Int32 adds an int32-valued key:value pair to a Span.LogFields() record
+// Int32 向 Span.LogFields() 的记录中添加一个值为 int32 类型的键值对
 func Int32(key string, val int32) Field {
 	return Field{
 		key:        key,
@@ -77,7 +77,7 @@ func Int32(key string, val int32) Field {
 	}
 }
 
-// Int64 adds an int64-valued key:value pair to a Span.LogFields() record
+// Int64 向 Span.LogFields() 的记录中添加一个值为 int64 类型的键值对
 func Int64(key string, val int64) Field {
 	return Field{
 		key:        key,
@@ -86,7 +86,7 @@ func Int64(key string, val int64) Field {
 	}
 }
 
-// Uint32 adds a uint32-valued key:value pair to a Span.LogFields() record
+// Uint32 向 Span.LogFields() 的记录中添加一个值为 uint32 类型的键值对
 func Uint32(key string, val uint32) Field {
 	return Field{
 		key:        key,
@@ -95,7 +95,7 @@ func Uint32(key string, val uint32) Field {
 	}
 }
 
-// Uint64 adds a uint64-valued key:value pair to a Span.LogFields() record
+// Uint64 向 Span.LogFields() 的记录中添加一个值为 uint64 类型的键值对
 func Uint64(key string, val uint64) Field {
 	return Field{
 		key:        key,
@@ -104,7 +104,7 @@ func Uint64(key string, val uint64) Field {
 	}
 }
 
-// Float32 adds a float32-valued key:value pair to a Span.LogFields() record
+// Float32 向 Span.LogFields() 的记录中添加一个值为 float32 类型的键值对
 func Float32(key string, val float32) Field {
 	return Field{
 		key:        key,
@@ -113,7 +113,7 @@ func Float32(key string, val float32) Field {
 	}
 }
 
-// Float64 adds a float64-valued key:value pair to a Span.LogFields() record
+// Float64 向 Span.LogFields() 的记录中添加一个值为 float64 类型的键值对
 func Float64(key string, val float64) Field {
 	return Field{
 		key:        key,
@@ -122,19 +122,19 @@ func Float64(key string, val float64) Field {
 	}
 }
 
-// Error adds an error with the key "error.object" to a Span.LogFields() record
+// Error 向 Span.LogFields() 的记录中添加一个键为"error.object"的 error
 func Error(err error) Field {
 	return Field{
-		key:          "error.object",
+		key:          ErrorObjectKey,
 		fieldType:    errorType,
 		interfaceVal: err,
 	}
 }
 
-// Object adds an object-valued key:value pair to a Span.LogFields() record
-// Please pass in an immutable object, otherwise there may be concurrency issues.
-// Such as passing in the map, log.Object may result in "fatal error: concurrent map iteration and map write".
-// Because span is sent asynchronously, it is possible that this map will also be modified.
+// Object 向 Span.LogFields() 的记录中添加一个值为任意对象的键值对。
+// 请传入一个不可变的对象，否则可能会有并发问题。
+// 例如传入一个 map 时，log.Object 可能会导致"fatal error: concurrent map iteration and map write"，
+// 因为 span 是被异步发送的，这个 map 可能在此期间同时被修改。
 func Object(key string, obj interface{}) Field {
 	return Field{
 		key:          key,
@@ -143,22 +143,62 @@ func Object(key string, obj interface{}) Field {
 	}
 }
 
-// Event creates a string-valued Field for span logs with key="event" and value=val.
+// Strings 向 Span.LogFields() 的记录中添加一个值为 []string 类型的键值对。
+// vals 会被拷贝，因此之后对它的修改不会影响该 Field。
+func Strings(key string, vals []string) Field {
+	return Field{
+		key:          key,
+		fieldType:    stringsType,
+		interfaceVal: append([]string(nil), vals...),
+	}
+}
+
+// Ints 向 Span.LogFields() 的记录中添加一个值为 []int64 类型的键值对。
+// vals 会被拷贝，因此之后对它的修改不会影响该 Field。
+func Ints(key string, vals []int64) Field {
+	return Field{
+		key:          key,
+		fieldType:    int64sType,
+		interfaceVal: append([]int64(nil), vals...),
+	}
+}
+
+// OpenTracing 语义约定(semantic conventions)中标准的 span 日志字段的键。
+const (
+	EventKey       = "event"
+	MessageKey     = "message"
+	StackKey       = "stack"
+	ErrorKindKey   = "error.kind"
+	ErrorObjectKey = "error.object"
+)
+
+// Event 创建一个用于 span 日志的 string 类型的 Field，其 key="event"，value=val。
 func Event(val string) Field {
-	return String("event", val)
+	return String(EventKey, val)
 }
 
-// Message creates a string-valued Field for span logs with key="message" and value=val.
+// Message 创建一个用于 span 日志的 string 类型的 Field，其 key="message"，value=val。
 func Message(val string) Field {
-	return String("message", val)
+	return String(MessageKey, val)
+}
+
+// Stack 创建一个用于 span 日志的 string 类型的 Field，其 key="stack"，value=val，
+// 例如 runtime/debug.Stack() 的输出。
+func Stack(val string) Field {
+	return String(StackKey, val)
 }
 
-// LazyLogger allows for user-defined, late-bound logging of arbitrary data
+// ErrorKind 创建一个用于 span 日志的 string 类型的 Field，其 key="error.kind"，value=kind，
+// 即错误的类型或"种类"，例如"Exception"或"OSError"。
+func ErrorKind(kind string) Field {
+	return String(ErrorKindKey, kind)
+}
+
+// LazyLogger 允许用户自定义的、延迟绑定的任意数据的日志记录
 type LazyLogger func(fv Encoder)
 
-// Lazy adds a LazyLogger to a Span.LogFields() record; the tracing
-// implementation will call the LazyLogger function at an indefinite time in
-// the future (after Lazy() returns).
+// Lazy 向 Span.LogFields() 的记录中添加一个 LazyLogger；
+// Tracer 的实现会在未来的某个不确定的时间（在 Lazy() 返回之后）调用该 LazyLogger 函数。
 func Lazy(ll LazyLogger) Field {
 	return Field{
 		fieldType:    lazyLoggerType,
@@ -166,9 +206,8 @@ func Lazy(ll LazyLogger) Field {
 	}
 }
 
-// Noop creates a no-op log field that should be ignored by the tracer.
-// It can be used to capture optional fields, for example those that should
-// only be logged in non-production environment:
+// Noop 创建一个应该被 tracer 忽略的空操作(no-op)的日志字段。
+// 它可以用来表示可选的字段，例如只应该在非生产环境中记录的字段：
 //
 //     func customerField(order *Order) log.Field {
 //          if os.Getenv("ENVIRONMENT") == "dev" {
@@ -185,11 +224,9 @@ func Noop() Field {
 	}
 }
 
-// Encoder allows access to the contents of a Field (via a call to
-// Field.Marshal).
+// Encoder 允许（通过调用 Field.Marshal）访问 Field 的内容。
 //
-// Tracer implementations typically provide an implementation of Encoder;
-// OpenTracing callers typically do not need to concern themselves with it.
+// 通常由 Tracer 的实现提供 Encoder 的实现；OpenTracing 的调用者一般不需要关心它。
 type Encoder interface {
 	EmitString(key, value string)
 	EmitBool(key string, value bool)
@@ -204,8 +241,14 @@ type Encoder interface {
 	EmitLazyLogger(value LazyLogger)
 }
 
-// Marshal passes a Field instance through to the appropriate
-// field-type-specific method of an Encoder.
+// ArrayEncoder 是一个 Encoder 可以选择实现的接口，用于以数组的形式接收 Strings() 和 Ints() 字段的值。
+// 对于没有实现它的 Encoder，Field.Marshal 会把这些值传给 EmitObject。
+type ArrayEncoder interface {
+	EmitStrings(key string, values []string)
+	EmitInt64s(key string, values []int64)
+}
+
+// Marshal 把 Field 实例传给 Encoder 中与字段类型对应的方法。
 func (lf Field) Marshal(visitor Encoder) {
 	switch lf.fieldType {
 	case stringType:
@@ -236,17 +279,29 @@ func (lf Field) Marshal(visitor Encoder) {
 		visitor.EmitObject(lf.key, lf.interfaceVal)
 	case lazyLoggerType:
 		visitor.EmitLazyLogger(lf.interfaceVal.(LazyLogger))
+	case stringsType:
+		if ae, ok := visitor.(ArrayEncoder); ok {
+			ae.EmitStrings(lf.key, lf.interfaceVal.([]string))
+		} else {
+			visitor.EmitObject(lf.key, lf.interfaceVal)
+		}
+	case int64sType:
+		if ae, ok := visitor.(ArrayEncoder); ok {
+			ae.EmitInt64s(lf.key, lf.interfaceVal.([]int64))
+		} else {
+			visitor.EmitObject(lf.key, lf.interfaceVal)
+		}
 	case noopType:
 		// intentionally left blank
 	}
 }
 
-// Key returns the field's key.
+// Key 返回字段的键。
 func (lf Field) Key() string {
 	return lf.key
 }
 
-// Value returns the field's value as interface{}.
+// Value 以 interface{} 的形式返回字段的值。
 func (lf Field) Value() interface{} {
 	switch lf.fieldType {
 	case stringType:
@@ -267,7 +322,7 @@ func (lf Field) Value() interface{} {
 		return math.Float32frombits(uint32(lf.numericVal))
 	case float64Type:
 		return math.Float64frombits(uint64(lf.numericVal))
-	case errorType, objectType, lazyLoggerType:
+	case errorType, objectType, lazyLoggerType, stringsType, int64sType:
 		return lf.interfaceVal
 	case noopType:
 		return nil
@@ -276,7 +331,7 @@ func (lf Field) Value() interface{} {
 	}
 }
 
-// String returns a string representation of the key and value.
+// String 返回键和值的字符串表示。
 func (lf Field) String() string {
 	return fmt.Sprint(lf.key, ":", lf.Value())
 }
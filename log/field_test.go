@@ -34,6 +34,14 @@ func TestFieldString(t *testing.T) {
 			field:    Noop(),
 			expected: ":<nil>",
 		},
+		{
+			field:    Strings("key", []string{"a", "b"}),
+			expected: "key:[a b]",
+		},
+		{
+			field:    Ints("key", []int64{1, 2}),
+			expected: "key:[1 2]",
+		},
 		{
 			field:    Event("test"),
 			expected: "event:test",
@@ -42,6 +50,14 @@ func TestFieldString(t *testing.T) {
 			field:    Message("test2"),
 			expected: "message:test2",
 		},
+		{
+			field:    Stack("goroutine 1"),
+			expected: "stack:goroutine 1",
+		},
+		{
+			field:    ErrorKind("OSError"),
+			expected: "error.kind:OSError",
+		},
 	}
 	for i, tc := range testCases {
 		if str := tc.field.String(); str != tc.expected {
@@ -5,8 +5,7 @@ import (
 	"reflect"
 )
 
-// InterleavedKVToFields converts keyValues a la Span.LogKV() to a Field slice
-// a la Span.LogFields().
+// InterleavedKVToFields 把 Span.LogKV() 风格的 keyValues 转换为 Span.LogFields() 风格的 Field 切片。
 func InterleavedKVToFields(keyValues ...interface{}) ([]Field, error) {
 	if len(keyValues)%2 != 0 {
 		return nil, fmt.Errorf("non-even keyValues len: %d", len(keyValues))
@@ -59,3 +58,57 @@ func InterleavedKVToFields(keyValues ...interface{}) ([]Field, error) {
 	}
 	return fields, nil
 }
+
+// DedupeFields 返回每个键只保留最后一个 Field 的 fields，它位于最后一次出现的位置，其余字段的相对顺序不变。
+// 没有键的字段，例如 Noop() 和 Lazy() 字段，总是会被保留。如果没有重复的键，直接返回 fields 本身。
+func DedupeFields(fields []Field) []Field {
+	last := make(map[string]int, len(fields))
+	dup := false
+	for i, f := range fields {
+		if f.Key() == "" {
+			continue
+		}
+		if _, ok := last[f.Key()]; ok {
+			dup = true
+		}
+		last[f.Key()] = i
+	}
+	if !dup {
+		return fields
+	}
+	deduped := make([]Field, 0, len(fields))
+	for i, f := range fields {
+		if j, ok := last[f.Key()]; !ok || j == i {
+			deduped = append(deduped, f)
+		}
+	}
+	return deduped
+}
+
+// FieldsToMap 把 fields 编码为一个从键到值的 map，例如用于把一条日志记录序列化为 JSON。
+// 值的类型与它们经过的 Encoder 方法一致：error 字段会变为 string，Strings() 和 Ints() 字段会变为 []string 和 []int64，
+// Lazy() 字段会被就地展开。相同的键时，后面的字段会覆盖前面的字段。
+func FieldsToMap(fields []Field) map[string]interface{} {
+	m := make(mapEncoder, len(fields))
+	for _, f := range fields {
+		f.Marshal(m)
+	}
+	return m
+}
+
+// mapEncoder 是一个保存所有传给它的值的 Encoder 和 ArrayEncoder。
+type mapEncoder map[string]interface{}
+
+func (m mapEncoder) EmitString(key, value string)             { m[key] = value }
+func (m mapEncoder) EmitBool(key string, value bool)          { m[key] = value }
+func (m mapEncoder) EmitInt(key string, value int)            { m[key] = value }
+func (m mapEncoder) EmitInt32(key string, value int32)        { m[key] = value }
+func (m mapEncoder) EmitInt64(key string, value int64)        { m[key] = value }
+func (m mapEncoder) EmitUint32(key string, value uint32)      { m[key] = value }
+func (m mapEncoder) EmitUint64(key string, value uint64)      { m[key] = value }
+func (m mapEncoder) EmitFloat32(key string, value float32)    { m[key] = value }
+func (m mapEncoder) EmitFloat64(key string, value float64)    { m[key] = value }
+func (m mapEncoder) EmitObject(key string, value interface{}) { m[key] = value }
+func (m mapEncoder) EmitStrings(key string, values []string)  { m[key] = values }
+func (m mapEncoder) EmitInt64s(key string, values []int64)    { m[key] = values }
+func (m mapEncoder) EmitLazyLogger(value LazyLogger)          { value(m) }
@@ -84,3 +84,73 @@ func TestInterleavedKVToFields(t *testing.T) {
 		})
 	}
 }
+
+func TestDedupeFields(t *testing.T) {
+	fields := []Field{String("a", "1"), Int("b", 2), Bool("c", true)}
+	assert.Equal(t, fields, DedupeFields(fields))
+
+	fields = []Field{
+		Event("first"),
+		String("a", "1"),
+		Noop(),
+		Event("second"),
+		Int("b", 2),
+		String("a", "3"),
+	}
+	assert.Equal(t, []Field{
+		Noop(),
+		Event("second"),
+		Int("b", 2),
+		String("a", "3"),
+	}, DedupeFields(fields))
+
+	assert.Empty(t, DedupeFields(nil))
+
+	// 没有重复的键时，即使有没有键的字段也直接返回 fields 本身
+	fields = []Field{Noop(), String("a", "1"), Noop()}
+	deduped := DedupeFields(fields)
+	assert.Equal(t, fields, deduped)
+	assert.True(t, &fields[0] == &deduped[0])
+}
+
+func TestFieldsToMap(t *testing.T) {
+	ids := []int64{1, 2, 3}
+	fields := []Field{
+		Strings("tags", []string{"a", "b"}),
+		Ints("ids", ids),
+		String("s", "v"),
+		Error(errors.New("boom")),
+		Lazy(func(e Encoder) { e.EmitBool("lazy", true) }),
+		Noop(),
+	}
+	ids[0] = 42 // must not affect the Field created above
+
+	assert.Equal(t, map[string]interface{}{
+		"tags":         []string{"a", "b"},
+		"ids":          []int64{1, 2, 3},
+		"s":            "v",
+		"error.object": "boom",
+		"lazy":         true,
+	}, FieldsToMap(fields))
+}
+
+// legacyEncoder is an Encoder that does not implement ArrayEncoder; only
+// EmitObject may be called on it.
+type legacyEncoder struct {
+	Encoder
+	objects map[string]interface{}
+}
+
+func (e legacyEncoder) EmitObject(key string, value interface{}) {
+	e.objects[key] = value
+}
+
+func TestArrayFieldsWithoutArrayEncoder(t *testing.T) {
+	enc := legacyEncoder{objects: map[string]interface{}{}}
+	Strings("tags", []string{"a"}).Marshal(enc)
+	Ints("ids", []int64{1}).Marshal(enc)
+	assert.Equal(t, map[string]interface{}{
+		"tags": []string{"a"},
+		"ids":  []int64{1},
+	}, enc.objects)
+}
@@ -0,0 +1,170 @@
+package opentracing
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/opentracing/opentracing-go/log"
+)
+
+// LogTruncatedTagKey 是 WrapSpanWithLogLimit 在日志第一次超出限制时在 Span 上设置的 tag 的 key。
+const LogTruncatedTagKey = "log.truncated"
+
+// LogsDroppedTagKey 是 WrapSpanWithLogLimit 和 WrapSpanWithLogRateLimit 在 Span 结束时设置的 tag 的 key，
+// 值为被丢弃的日志条数（int64），没有日志被丢弃时不会设置。
+const LogsDroppedTagKey = "logs.dropped"
+
+// WrapSpanWithLogLimit 返回一个包装了 sp 的 Span，它最多只会把 maxLogs 次日志调用
+// （LogFields、LogKV，以及已废弃的 LogEvent、LogEventWithPayload、Log，
+// 还有 FinishWithOptions 中的每一条 LogRecord）传递给 sp，之后的日志都会被丢弃，
+// 并且在第一次超出限制时在 sp 上设置 tag `log.truncated=true`。
+//
+// Span 结束时，如果有日志被丢弃，还会在 sp 上设置 tag `logs.dropped=<丢弃的条数>`。
+//
+// 这可以保护后端不被循环中意外产生的大量日志淹没。其余方法（包括 Context() 和 Tracer()）都会原样委托给 sp。
+func WrapSpanWithLogLimit(sp Span, maxLogs int) Span {
+	return &logLimitSpan{Span: sp, maxLogs: int64(maxLogs)}
+}
+
+// WrapSpanWithLogRateLimit 返回一个包装了 sp 的 Span，它平均每秒最多把 perSecond 次日志调用传递给 sp
+// （允许最多 perSecond 次的突发），超出速率的日志会被丢弃。计入限制的日志调用与 WrapSpanWithLogLimit 相同。
+//
+// 与 WrapSpanWithLogLimit 不同，它不会设置`log.truncated`，只会在 Span 结束时设置 tag `logs.dropped`。
+// 适用于持续时间很长、需要一直记录日志但又不能无限增长的 Span。
+func WrapSpanWithLogRateLimit(sp Span, perSecond int) Span {
+	return &logLimitSpan{Span: sp, rate: newLogRateLimiter(float64(perSecond), time.Now)}
+}
+
+type logLimitSpan struct {
+	// 使用 atomic 访问的 64 位字段必须放在最前面，以保证在 32 位平台上是 8 字节对齐的
+	logs    int64 // 使用 atomic 访问
+	dropped int64 // 使用 atomic 访问
+
+	Span
+	maxLogs int64
+	rate    *logRateLimiter // 不为 nil 时按速率限制，忽略 maxLogs
+}
+
+// allow 记录一次日志调用，并判断该日志是否应该被传递给底层的 Span。
+func (s *logLimitSpan) allow() bool {
+	if s.rate != nil {
+		if s.rate.allow() {
+			return true
+		}
+		atomic.AddInt64(&s.dropped, 1)
+		return false
+	}
+	n := atomic.AddInt64(&s.logs, 1)
+	if n <= s.maxLogs {
+		return true
+	}
+	if n == s.maxLogs+1 {
+		s.Span.SetTag(LogTruncatedTagKey, true)
+	}
+	atomic.AddInt64(&s.dropped, 1)
+	return false
+}
+
+// tagDropped 在有日志被丢弃时，在底层的 Span 上记录丢弃的条数。
+func (s *logLimitSpan) tagDropped() {
+	if n := atomic.LoadInt64(&s.dropped); n > 0 {
+		s.Span.SetTag(LogsDroppedTagKey, n)
+	}
+}
+
+func (s *logLimitSpan) LogFields(fields ...log.Field) {
+	if s.allow() {
+		s.Span.LogFields(fields...)
+	}
+}
+
+func (s *logLimitSpan) LogKV(alternatingKeyValues ...interface{}) {
+	if s.allow() {
+		s.Span.LogKV(alternatingKeyValues...)
+	}
+}
+
+func (s *logLimitSpan) LogEvent(event string) {
+	if s.allow() {
+		s.Span.LogEvent(event)
+	}
+}
+
+func (s *logLimitSpan) LogEventWithPayload(event string, payload interface{}) {
+	if s.allow() {
+		s.Span.LogEventWithPayload(event, payload)
+	}
+}
+
+func (s *logLimitSpan) Log(data LogData) {
+	if s.allow() {
+		s.Span.Log(data)
+	}
+}
+
+func (s *logLimitSpan) Finish() {
+	s.tagDropped()
+	s.Span.Finish()
+}
+
+func (s *logLimitSpan) FinishWithOptions(opts FinishOptions) {
+	if len(opts.LogRecords) > 0 {
+		records := make([]LogRecord, 0, len(opts.LogRecords))
+		for _, lr := range opts.LogRecords {
+			if s.allow() {
+				records = append(records, lr)
+			}
+		}
+		opts.LogRecords = records
+	}
+	s.tagDropped()
+	s.Span.FinishWithOptions(opts)
+}
+
+func (s *logLimitSpan) SetOperationName(operationName string) Span {
+	s.Span.SetOperationName(operationName)
+	return s
+}
+
+func (s *logLimitSpan) SetTag(key string, value interface{}) Span {
+	s.Span.SetTag(key, value)
+	return s
+}
+
+func (s *logLimitSpan) SetBaggageItem(restrictedKey, value string) Span {
+	s.Span.SetBaggageItem(restrictedKey, value)
+	return s
+}
+
+// logRateLimiter 是一个简单的令牌桶：容量和每秒补充的令牌数都是 perSecond。
+type logRateLimiter struct {
+	mu        sync.Mutex
+	perSecond float64
+	tokens    float64
+	last      time.Time
+	now       func() time.Time
+}
+
+func newLogRateLimiter(perSecond float64, now func() time.Time) *logRateLimiter {
+	return &logRateLimiter{perSecond: perSecond, tokens: perSecond, last: now(), now: now}
+}
+
+// allow 消耗一个令牌，没有可用的令牌时返回 false。
+func (l *logRateLimiter) allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens += elapsed.Seconds() * l.perSecond
+		if l.tokens > l.perSecond {
+			l.tokens = l.perSecond
+		}
+	}
+	l.last = now
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
@@ -0,0 +1,91 @@
+package opentracing_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+func TestWrapSpanWithLogLimit(t *testing.T) {
+	tracer := mocktracer.New()
+	span := opentracing.WrapSpanWithLogLimit(tracer.StartSpan("op"), 2)
+
+	span.LogFields(log.String("event", "first"))
+	span.LogKV("event", "second")
+	for i := 0; i < 100; i++ {
+		span.LogFields(log.Int("i", i))
+	}
+	span.FinishWithOptions(opentracing.FinishOptions{
+		LogRecords: []opentracing.LogRecord{
+			{Timestamp: time.Now(), Fields: []log.Field{log.String("event", "late")}},
+		},
+	})
+
+	finished := tracer.FinishedSpans()
+	if assert.Len(t, finished, 1) {
+		logs := finished[0].Logs()
+		assert.Len(t, logs, 2)
+		assert.Equal(t, "first", logs[0].Fields[0].ValueString)
+		assert.Equal(t, "second", logs[1].Fields[0].ValueString)
+		assert.Equal(t, true, finished[0].Tag(opentracing.LogTruncatedTagKey))
+		assert.Equal(t, int64(101), finished[0].Tag(opentracing.LogsDroppedTagKey))
+	}
+}
+
+func TestWrapSpanWithLogRateLimit(t *testing.T) {
+	tracer := mocktracer.New()
+	span := opentracing.WrapSpanWithLogRateLimit(tracer.StartSpan("op"), 3)
+
+	for i := 0; i < 10; i++ {
+		span.LogKV("i", i)
+	}
+	span.Finish()
+
+	finished := tracer.FinishedSpans()[0]
+	assert.Len(t, finished.Logs(), 3)
+	assert.Equal(t, int64(7), finished.Tag(opentracing.LogsDroppedTagKey))
+	assert.Nil(t, finished.Tag(opentracing.LogTruncatedTagKey))
+}
+
+func TestWrapSpanWithLogLimitDelegates(t *testing.T) {
+	tracer := mocktracer.New()
+	inner := tracer.StartSpan("op")
+	span := opentracing.WrapSpanWithLogLimit(inner, 10)
+
+	assert.Equal(t, span, span.SetTag("k", "v"), "SetTag must return the wrapper")
+	span.SetOperationName("renamed").SetBaggageItem("b", "c")
+	assert.Equal(t, inner.Context(), span.Context())
+	assert.Equal(t, tracer, span.Tracer())
+	span.LogFields(log.String("event", "within limit"))
+	span.Finish()
+
+	finished := tracer.FinishedSpans()[0]
+	assert.Equal(t, "renamed", finished.OperationName)
+	assert.Equal(t, map[string]interface{}{"k": "v"}, finished.Tags())
+	assert.Equal(t, "c", finished.BaggageItem("b"))
+	assert.Len(t, finished.Logs(), 1)
+}
+
+func TestWrapSpanWithLogLimitConcurrent(t *testing.T) {
+	tracer := mocktracer.New()
+	span := opentracing.WrapSpanWithLogLimit(tracer.StartSpan("op"), 50)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				span.LogKV("j", j)
+			}
+		}()
+	}
+	wg.Wait()
+	span.Finish()
+	assert.Len(t, tracer.FinishedSpans()[0].Logs(), 50)
+}
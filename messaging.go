@@ -0,0 +1,129 @@
+package opentracing
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+// EncodedHeaderValuePrefix 是 MessageHeadersCarrier 和 AMQPHeadersCarrier 写入经过编码的值时使用的前缀。
+//
+// 消息中间件和它们的客户端不一定能正确地传递任意字节的 header 值，因此 Set 只会原样写入由可打印 ASCII 字符
+// 组成的值；其他的值（二进制数据、非 ASCII 字符、换行等），以及本身就以该前缀开头的值，会被写成
+// 该前缀加上标准 base64 编码的形式，在 Get 和 ForeachKey 中再被解码回原来的字符串。
+const EncodedHeaderValuePrefix = "base64:"
+
+// encodeHeaderValue 按 EncodedHeaderValuePrefix 描述的规则编码 val。
+func encodeHeaderValue(val string) string {
+	if strings.HasPrefix(val, EncodedHeaderValuePrefix) || !isHeaderSafe(val) {
+		return EncodedHeaderValuePrefix + base64.StdEncoding.EncodeToString([]byte(val))
+	}
+	return val
+}
+
+// decodeHeaderValue 解码由 encodeHeaderValue 编码的值，没有前缀或者无法解码的值会被原样返回。
+func decodeHeaderValue(val string) string {
+	if !strings.HasPrefix(val, EncodedHeaderValuePrefix) {
+		return val
+	}
+	b, err := base64.StdEncoding.DecodeString(val[len(EncodedHeaderValuePrefix):])
+	if err != nil {
+		return val
+	}
+	return string(b)
+}
+
+// isHeaderSafe 判断 s 是否只包含可打印的 ASCII 字符。
+func isHeaderSafe(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// MessageHeadersCarrier 同时满足 TextMapWriter 和 TextMapReader 接口，
+// 用于以 map[string][]byte 表示的消息头，例如 Kafka 消息的 record headers。
+//
+// 生产者用例:
+//
+//     headers := map[string][]byte{}
+//     err := tracer.Inject(span.Context(), opentracing.TextMap, opentracing.MessageHeadersCarrier(headers))
+//     // 把 headers 转换为 Kafka 客户端的 header 类型并随消息发送
+//
+// 消费者用例:
+//
+//     spanCtx, err := tracer.Extract(opentracing.TextMap, opentracing.MessageHeadersCarrier(headers))
+//
+// 不安全的值会被编码，见 EncodedHeaderValuePrefix；Set 写入的字符串在 Get 和 ForeachKey 中会被原样读出。
+type MessageHeadersCarrier map[string][]byte
+
+// Set 实现 TextMapWriter 接口。
+func (c MessageHeadersCarrier) Set(key, val string) {
+	c[key] = []byte(encodeHeaderValue(val))
+}
+
+// Get 实现 TextMapReaderLookup 接口。
+func (c MessageHeadersCarrier) Get(key string) (string, bool) {
+	v, ok := c[key]
+	if !ok {
+		return "", false
+	}
+	return decodeHeaderValue(string(v)), true
+}
+
+// ForeachKey 实现 TextMapReader 接口。
+func (c MessageHeadersCarrier) ForeachKey(handler func(key, val string) error) error {
+	for k, v := range c {
+		if err := handler(k, decodeHeaderValue(string(v))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AMQPHeadersCarrier 同时满足 TextMapWriter 和 TextMapReader 接口，
+// 用于 AMQP 消息的 headers 表（例如 amqp.Table，它的底层类型就是 map[string]interface{}）：
+//
+//     carrier := opentracing.AMQPHeadersCarrier(msg.Headers)
+//     spanCtx, err := tracer.Extract(opentracing.TextMap, carrier)
+//
+// Set 以 string 类型写入值，不安全的值会被编码，见 EncodedHeaderValuePrefix。
+// AMQP 客户端解码时可能把字符串表示为 string 或者 []byte，Get 和 ForeachKey 对这两种类型都会解码后
+// 以字符串的形式返回，其他类型的值（数字、嵌套的表等）不可能是 Tracer 写入的，会被跳过。
+type AMQPHeadersCarrier map[string]interface{}
+
+// Set 实现 TextMapWriter 接口。
+func (c AMQPHeadersCarrier) Set(key, val string) {
+	c[key] = encodeHeaderValue(val)
+}
+
+// Get 实现 TextMapReaderLookup 接口。
+func (c AMQPHeadersCarrier) Get(key string) (string, bool) {
+	return amqpHeaderString(c[key])
+}
+
+// ForeachKey 实现 TextMapReader 接口。
+func (c AMQPHeadersCarrier) ForeachKey(handler func(key, val string) error) error {
+	for k, v := range c {
+		s, ok := amqpHeaderString(v)
+		if !ok {
+			continue
+		}
+		if err := handler(k, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// amqpHeaderString 把 string 或 []byte 类型的 header 值解码为字符串，其他类型的值返回 false。
+func amqpHeaderString(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return decodeHeaderValue(v), true
+	case []byte:
+		return decodeHeaderValue(string(v)), true
+	}
+	return "", false
+}
@@ -0,0 +1,111 @@
+package opentracing_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+func collectTextMap(t *testing.T, r opentracing.TextMapReader) map[string]string {
+	got := map[string]string{}
+	require.NoError(t, r.ForeachKey(func(k, v string) error {
+		got[k] = v
+		return nil
+	}))
+	return got
+}
+
+func TestMessageHeadersCarrier(t *testing.T) {
+	headers := map[string][]byte{"content-type": []byte("application/json")}
+	carrier := opentracing.MessageHeadersCarrier(headers)
+	carrier.Set("trace", "1:2")
+	assert.Equal(t, []byte("1:2"), headers["trace"])
+	assert.Equal(t, map[string]string{
+		"content-type": "application/json",
+		"trace":        "1:2",
+	}, collectTextMap(t, carrier))
+
+	stop := errors.New("stop")
+	assert.Equal(t, stop, carrier.ForeachKey(func(k, v string) error { return stop }))
+}
+
+func TestAMQPHeadersCarrier(t *testing.T) {
+	headers := map[string]interface{}{
+		"raw":     []byte("bytes"),
+		"retries": int32(3),
+		"nested":  map[string]interface{}{"a": "b"},
+	}
+	carrier := opentracing.AMQPHeadersCarrier(headers)
+	carrier.Set("trace", "1:2")
+	assert.Equal(t, "1:2", headers["trace"])
+	assert.Equal(t, map[string]string{
+		"raw":   "bytes",
+		"trace": "1:2",
+	}, collectTextMap(t, carrier))
+}
+
+func TestMessageCarriersRoundTrip(t *testing.T) {
+	tracer := mocktracer.New()
+	span := tracer.StartSpan("produce")
+	span.SetBaggageItem("tenant", "acme")
+	sc := span.Context().(mocktracer.MockSpanContext)
+
+	for name, carrier := range map[string]opentracing.TextMapReadWriter{
+		"kafka": opentracing.MessageHeadersCarrier{},
+		"amqp":  opentracing.AMQPHeadersCarrier{},
+	} {
+		require.NoError(t, tracer.Inject(sc, opentracing.TextMap, carrier), name)
+		extracted, err := tracer.Extract(opentracing.TextMap, carrier)
+		require.NoError(t, err, name)
+		got := extracted.(mocktracer.MockSpanContext)
+		assert.Equal(t, sc.TraceID, got.TraceID, name)
+		assert.Equal(t, sc.SpanID, got.SpanID, name)
+		assert.Equal(t, "acme", got.Baggage["tenant"], name)
+	}
+}
+
+func TestMessageCarriersEncodeUnsafeValues(t *testing.T) {
+	values := map[string]string{
+		"binary": "\x00\xff\r\nend",
+		"utf8":   "用户",
+		"prefix": opentracing.EncodedHeaderValuePrefix + "abc",
+		"plain":  "1:2",
+	}
+	kafka := map[string][]byte{}
+	amqp := map[string]interface{}{}
+	for name, carrier := range map[string]opentracing.TextMapReadWriter{
+		"kafka": opentracing.MessageHeadersCarrier(kafka),
+		"amqp":  opentracing.AMQPHeadersCarrier(amqp),
+	} {
+		for k, v := range values {
+			carrier.Set(k, v)
+		}
+		assert.Equal(t, values, collectTextMap(t, carrier), name)
+		for k, v := range values {
+			got, ok := opentracing.TextMapGet(carrier, k)
+			assert.True(t, ok, "%s: %s", name, k)
+			assert.Equal(t, v, got, "%s: %s", name, k)
+		}
+		_, ok := opentracing.TextMapGet(carrier, "missing")
+		assert.False(t, ok, name)
+	}
+
+	// 写入消息中的值只包含可打印的 ASCII 字符
+	for k, v := range kafka {
+		for _, b := range v {
+			assert.True(t, b >= 0x20 && b <= 0x7e, "%s: %q", k, v)
+		}
+	}
+	assert.Equal(t, "1:2", amqp["plain"])
+	assert.Equal(t, opentracing.EncodedHeaderValuePrefix+"55So5oi3", amqp["utf8"])
+
+	// 不是由 Set 写入的、无法解码的值原样返回
+	amqp["foreign"] = []byte(opentracing.EncodedHeaderValuePrefix + "!!")
+	got, _ := opentracing.TextMapGet(opentracing.AMQPHeadersCarrier(amqp), "foreign")
+	assert.Equal(t, opentracing.EncodedHeaderValuePrefix+"!!", got)
+}
@@ -0,0 +1,219 @@
+package mocktracer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SpanSnapshot is an immutable copy of the state of a MockSpan, suitable for
+// printing and for comparing against expectations in tests.
+//
+// When a SpanSnapshot is used as an expectation for Diff, only OperationName
+// and Tags are taken into account.
+type SpanSnapshot struct {
+	OperationName string
+	TraceID       int
+	SpanID        int
+	ParentID      int
+	StartTime     time.Time
+	FinishTime    time.Time
+	Tags          map[string]interface{}
+	Logs          []MockLogRecord
+}
+
+// Duration returns the time elapsed between the start and the finish of the span.
+func (s SpanSnapshot) Duration() time.Duration {
+	return s.FinishTime.Sub(s.StartTime)
+}
+
+// Snapshot returns a copy of the span's current state.
+func (s *MockSpan) Snapshot() SpanSnapshot {
+	tags := s.Tags()
+	logs := s.Logs()
+	s.RLock()
+	defer s.RUnlock()
+	return SpanSnapshot{
+		OperationName: s.OperationName,
+		TraceID:       s.SpanContext.TraceID,
+		SpanID:        s.SpanContext.SpanID,
+		ParentID:      s.ParentID,
+		StartTime:     s.StartTime,
+		FinishTime:    s.FinishTime,
+		Tags:          tags,
+		Logs:          logs,
+	}
+}
+
+// FinishedSnapshots returns snapshots of all spans returned by FinishedSpans().
+func (t *MockTracer) FinishedSnapshots() []SpanSnapshot {
+	spans := t.FinishedSpans()
+	snapshots := make([]SpanSnapshot, len(spans))
+	for i, span := range spans {
+		snapshots[i] = span.Snapshot()
+	}
+	return snapshots
+}
+
+// String returns a human-readable tree of the finished spans, see Dump.
+func (t *MockTracer) String() string {
+	var buf bytes.Buffer
+	// writes to a bytes.Buffer never fail
+	_ = t.Dump(&buf)
+	return buf.String()
+}
+
+// Dump writes a stable, human-readable tree of the finished spans to w.
+//
+// Each span is printed on its own line as the operation name, the duration
+// and the tags sorted by key, followed by its log records (with timestamps
+// relative to the span start) and, indented by two more spaces, its children.
+// Spans with the same parent are ordered by start time. A span whose parent
+// has not been finished is printed as a root.
+//
+//     parent [30ms] {component=test}
+//       log +5ms: event=cache.miss
+//       child [10ms] {db.type=sql}
+//
+func (t *MockTracer) Dump(w io.Writer) error {
+	return DumpSnapshots(w, t.FinishedSnapshots())
+}
+
+// DumpSnapshots writes snapshots to w in the format described by MockTracer.Dump.
+func DumpSnapshots(w io.Writer, snapshots []SpanSnapshot) error {
+	byID := make(map[[2]int]bool, len(snapshots))
+	for _, s := range snapshots {
+		byID[[2]int{s.TraceID, s.SpanID}] = true
+	}
+	children := make(map[[2]int][]SpanSnapshot)
+	var roots []SpanSnapshot
+	for _, s := range snapshots {
+		parent := [2]int{s.TraceID, s.ParentID}
+		if s.ParentID != 0 && byID[parent] {
+			children[parent] = append(children[parent], s)
+		} else {
+			roots = append(roots, s)
+		}
+	}
+
+	var dump func(s SpanSnapshot, depth int) error
+	dump = func(s SpanSnapshot, depth int) error {
+		indent := strings.Repeat("  ", depth)
+		line := fmt.Sprintf("%s%s [%s]", indent, s.OperationName, s.Duration())
+		if len(s.Tags) > 0 {
+			line += " {" + formatTags(s.Tags) + "}"
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+		for _, lr := range s.Logs {
+			fields := make([]string, len(lr.Fields))
+			for i, f := range lr.Fields {
+				fields[i] = f.Key + "=" + f.ValueString
+			}
+			if _, err := fmt.Fprintf(w, "%s  log +%s: %s\n",
+				indent, lr.Timestamp.Sub(s.StartTime), strings.Join(fields, " ")); err != nil {
+				return err
+			}
+		}
+		kids := children[[2]int{s.TraceID, s.SpanID}]
+		sortSnapshots(kids)
+		for _, child := range kids {
+			if err := dump(child, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	sortSnapshots(roots)
+	for _, root := range roots {
+		if err := dump(root, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Diff compares the expected spans with the actual ones and returns a
+// human-readable report of the differences, or an empty string if there are
+// none. Spans are matched by operation name, in order; for every matched pair
+// the tags must be equal.
+func Diff(expected, actual []SpanSnapshot) string {
+	var report []string
+	used := make([]bool, len(actual))
+	for _, e := range expected {
+		match := -1
+		for i, a := range actual {
+			if !used[i] && a.OperationName == e.OperationName {
+				match = i
+				break
+			}
+		}
+		if match < 0 {
+			report = append(report, fmt.Sprintf("missing span %q", e.OperationName))
+			continue
+		}
+		used[match] = true
+		report = append(report, diffTags(e.OperationName, e.Tags, actual[match].Tags)...)
+	}
+	for i, a := range actual {
+		if !used[i] {
+			report = append(report, fmt.Sprintf("extra span %q", a.OperationName))
+		}
+	}
+	if len(report) == 0 {
+		return ""
+	}
+	return strings.Join(report, "\n") + "\n"
+}
+
+func diffTags(operationName string, expected, actual map[string]interface{}) []string {
+	var report []string
+	for _, k := range sortedKeys(expected) {
+		a, ok := actual[k]
+		switch {
+		case !ok:
+			report = append(report, fmt.Sprintf("span %q: missing tag %s=%#v", operationName, k, expected[k]))
+		case !reflect.DeepEqual(expected[k], a):
+			report = append(report, fmt.Sprintf("span %q: tag %s: expected %#v, got %#v", operationName, k, expected[k], a))
+		}
+	}
+	for _, k := range sortedKeys(actual) {
+		if _, ok := expected[k]; !ok {
+			report = append(report, fmt.Sprintf("span %q: unexpected tag %s=%#v", operationName, k, actual[k]))
+		}
+	}
+	return report
+}
+
+func formatTags(tags map[string]interface{}) string {
+	keys := sortedKeys(tags)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = fmt.Sprintf("%s=%v", k, tags[k])
+	}
+	return strings.Join(pairs, " ")
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortSnapshots(snapshots []SpanSnapshot) {
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		if !snapshots[i].StartTime.Equal(snapshots[j].StartTime) {
+			return snapshots[i].StartTime.Before(snapshots[j].StartTime)
+		}
+		return snapshots[i].SpanID < snapshots[j].SpanID
+	})
+}
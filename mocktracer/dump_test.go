@@ -0,0 +1,84 @@
+package mocktracer
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/log"
+)
+
+func finishedTree(tracer *MockTracer) {
+	start := time.Date(2020, 7, 1, 0, 0, 0, 0, time.UTC)
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+
+	root := tracer.StartSpan("GET /users",
+		opentracing.StartTime(at(0)),
+		opentracing.Tags{"span.kind": "server", "component": "http"})
+	cache := tracer.StartSpan("cache.get",
+		opentracing.ChildOf(root.Context()), opentracing.StartTime(at(1)))
+	cache.FinishWithOptions(opentracing.FinishOptions{
+		FinishTime: at(3),
+		LogRecords: []opentracing.LogRecord{
+			{Timestamp: at(2), Fields: []log.Field{log.String("event", "cache.miss"), log.Int("attempt", 1)}},
+		},
+	})
+	db := tracer.StartSpan("db.query",
+		opentracing.ChildOf(root.Context()), opentracing.StartTime(at(5)),
+		opentracing.Tag{Key: "db.type", Value: "sql"})
+	db.FinishWithOptions(opentracing.FinishOptions{FinishTime: at(15)})
+	root.FinishWithOptions(opentracing.FinishOptions{FinishTime: at(30)})
+}
+
+func TestMockTracer_Dump(t *testing.T) {
+	tracer := New()
+	finishedTree(tracer)
+
+	var buf bytes.Buffer
+	require.NoError(t, tracer.Dump(&buf))
+	golden := `GET /users [30ms] {component=http span.kind=server}
+  cache.get [2ms]
+    log +1ms: event=cache.miss attempt=1
+  db.query [10ms] {db.type=sql}
+`
+	assert.Equal(t, golden, buf.String())
+	assert.Equal(t, golden, tracer.String())
+}
+
+func TestMockTracer_DumpUnfinishedParent(t *testing.T) {
+	tracer := New()
+	start := time.Date(2020, 7, 1, 0, 0, 0, 0, time.UTC)
+	parent := tracer.StartSpan("parent", opentracing.StartTime(start))
+	child := tracer.StartSpan("child", opentracing.ChildOf(parent.Context()), opentracing.StartTime(start))
+	child.FinishWithOptions(opentracing.FinishOptions{FinishTime: start.Add(time.Second)})
+
+	assert.Equal(t, "child [1s]\n", tracer.String())
+}
+
+func TestDiff(t *testing.T) {
+	tracer := New()
+	finishedTree(tracer)
+	actual := tracer.FinishedSnapshots()
+
+	assert.Equal(t, "", Diff([]SpanSnapshot{
+		{OperationName: "cache.get"},
+		{OperationName: "db.query", Tags: map[string]interface{}{"db.type": "sql"}},
+		{OperationName: "GET /users", Tags: map[string]interface{}{"span.kind": "server", "component": "http"}},
+	}, actual))
+
+	golden := `span "db.query": tag db.type: expected "redis", got "sql"
+span "GET /users": missing tag http.status_code=200
+span "GET /users": unexpected tag component="http"
+missing span "rpc.call"
+extra span "cache.get"
+`
+	assert.Equal(t, golden, Diff([]SpanSnapshot{
+		{OperationName: "db.query", Tags: map[string]interface{}{"db.type": "redis"}},
+		{OperationName: "GET /users", Tags: map[string]interface{}{"span.kind": "server", "http.status_code": 200}},
+		{OperationName: "rpc.call"},
+	}, actual))
+}
@@ -2,6 +2,7 @@ package mocktracer
 
 import (
 	"fmt"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -40,6 +41,23 @@ func (c MockSpanContext) ForeachBaggageItem(handler func(k, v string) bool) {
 	}
 }
 
+// IsSampled belongs to the opentracing.SampledSpanContext interface
+func (c MockSpanContext) IsSampled() bool {
+	return c.Sampled
+}
+
+// TraceIDString implements opentracing.TraceIdentifiable. It returns the
+// trace ID in decimal, as the mocktracer propagators encode it.
+func (c MockSpanContext) TraceIDString() string {
+	return strconv.Itoa(c.TraceID)
+}
+
+// SpanIDString implements opentracing.TraceIdentifiable. It returns the span
+// ID in decimal.
+func (c MockSpanContext) SpanIDString() string {
+	return strconv.Itoa(c.SpanID)
+}
+
 // WithBaggageItem creates a new context with an extra baggage item.
 func (c MockSpanContext) WithBaggageItem(key, value string) MockSpanContext {
 	var newBaggage map[string]string
@@ -71,11 +89,15 @@ type MockSpan struct {
 	SpanContext MockSpanContext
 	tags        map[string]interface{}
 	logs        []MockLogRecord
+	values      map[string]interface{}
+	references  []opentracing.SpanReference
 	tracer      *MockTracer
+
+	resolveName func(current string) string
 }
 
 func newMockSpan(t *MockTracer, name string, opts opentracing.StartSpanOptions) *MockSpan {
-	tags := opts.Tags
+	tags := opts.CloneTags()
 	if tags == nil {
 		tags = map[string]interface{}{}
 	}
@@ -83,11 +105,15 @@ func newMockSpan(t *MockTracer, name string, opts opentracing.StartSpanOptions)
 	parentID := int(0)
 	var baggage map[string]string
 	sampled := true
-	if len(opts.References) > 0 {
-		traceID = opts.References[0].ReferencedContext.(MockSpanContext).TraceID
-		parentID = opts.References[0].ReferencedContext.(MockSpanContext).SpanID
-		sampled = opts.References[0].ReferencedContext.(MockSpanContext).Sampled
-		baggage = opts.References[0].ReferencedContext.(MockSpanContext).Baggage
+	if parent, ok := parentContext(opts); ok {
+		traceID = parent.TraceID
+		parentID = parent.SpanID
+		sampled = parent.Sampled
+		baggage = parent.Baggage
+	}
+	if priority, ok := opts.SamplingPriority(); ok {
+		sampled = priority > 0
+		delete(tags, string(ext.SamplingPriority))
 	}
 	spanContext := MockSpanContext{traceID, nextMockID(), sampled, baggage}
 	startTime := opts.StartTime
@@ -101,9 +127,24 @@ func newMockSpan(t *MockTracer, name string, opts opentracing.StartSpanOptions)
 		tags:          tags,
 		logs:          []MockLogRecord{},
 		SpanContext:   spanContext,
+		references:    append([]opentracing.SpanReference(nil), opts.References...),
+
+		tracer:      t,
+		resolveName: opts.ResolveOperationName,
+	}
+}
 
-		tracer: t,
+// parentContext returns the context a new span inherits its trace from: the
+// first ChildOf reference or, failing that, the first FollowsFrom reference.
+func parentContext(opts opentracing.StartSpanOptions) (MockSpanContext, bool) {
+	if opts.IsRoot() {
+		return emptyContext, false
+	}
+	parents := opts.ChildOfReferences()
+	if len(parents) == 0 {
+		parents = opts.FollowsFromReferences()
 	}
+	return parents[0].(MockSpanContext), true
 }
 
 // Tags returns a copy of tags accumulated by the span so far
@@ -133,6 +174,16 @@ func (s *MockSpan) Logs() []MockLogRecord {
 	return logs
 }
 
+// References returns a copy of the references the span was started with, in
+// the order they were given.
+func (s *MockSpan) References() []opentracing.SpanReference {
+	s.RLock()
+	defer s.RUnlock()
+	refs := make([]opentracing.SpanReference, len(s.references))
+	copy(refs, s.references)
+	return refs
+}
+
 // Context belongs to the Span interface
 func (s *MockSpan) Context() opentracing.SpanContext {
 	s.Lock()
@@ -158,6 +209,27 @@ func (s *MockSpan) SetTag(key string, value interface{}) opentracing.Span {
 	return s
 }
 
+// SetValue belongs to the opentracing.SpanWithValues interface.
+//
+// Values are kept on this span only; they are neither part of the
+// MockSpanContext nor propagated by Inject/Extract.
+func (s *MockSpan) SetValue(key string, value interface{}) opentracing.Span {
+	s.Lock()
+	defer s.Unlock()
+	if s.values == nil {
+		s.values = make(map[string]interface{})
+	}
+	s.values[key] = value
+	return s
+}
+
+// Value belongs to the opentracing.SpanWithValues interface.
+func (s *MockSpan) Value(key string) interface{} {
+	s.RLock()
+	defer s.RUnlock()
+	return s.values[key]
+}
+
 // SetBaggageItem belongs to the Span interface
 func (s *MockSpan) SetBaggageItem(key, val string) opentracing.Span {
 	s.Lock()
@@ -175,41 +247,56 @@ func (s *MockSpan) BaggageItem(key string) string {
 
 // Finish belongs to the Span interface
 func (s *MockSpan) Finish() {
+	s.resolveOperationName()
 	s.Lock()
 	s.FinishTime = time.Now()
 	s.Unlock()
 	s.tracer.recordFinishedSpan(s)
 }
 
+// resolveOperationName applies the StartSpanOptions.OperationNameCallback,
+// if any, to the operation name of the finishing span. The callback only
+// runs on the first Finish, so finishing again does not rename a span that
+// was already recorded.
+func (s *MockSpan) resolveOperationName() {
+	s.Lock()
+	resolve := s.resolveName
+	s.resolveName = nil
+	name := s.OperationName
+	s.Unlock()
+	if resolve == nil {
+		return
+	}
+	// The callback is user code, so call it without holding the lock.
+	name = resolve(name)
+	s.Lock()
+	s.OperationName = name
+	s.Unlock()
+}
+
 // FinishWithOptions belongs to the Span interface
 func (s *MockSpan) FinishWithOptions(opts opentracing.FinishOptions) {
+	s.resolveOperationName()
 	s.Lock()
 	s.FinishTime = opts.FinishTime
+	if s.FinishTime.IsZero() {
+		s.FinishTime = time.Now()
+	}
 	s.Unlock()
 
-	// Handle any late-bound LogRecords.
+	// Handle any late-bound LogRecords, including the (deprecated) BulkLogData.
+	opts.MigrateBulkLogData()
 	for _, lr := range opts.LogRecords {
 		s.logFieldsWithTimestamp(lr.Timestamp, lr.Fields...)
 	}
-	// Handle (deprecated) BulkLogData.
-	for _, ld := range opts.BulkLogData {
-		if ld.Payload != nil {
-			s.logFieldsWithTimestamp(
-				ld.Timestamp,
-				log.String("event", ld.Event),
-				log.Object("payload", ld.Payload))
-		} else {
-			s.logFieldsWithTimestamp(
-				ld.Timestamp,
-				log.String("event", ld.Event))
-		}
-	}
 
 	s.tracer.recordFinishedSpan(s)
 }
 
 // String allows printing span for debugging
 func (s *MockSpan) String() string {
+	s.RLock()
+	defer s.RUnlock()
 	return fmt.Sprintf(
 		"traceId=%d, spanId=%d, parentId=%d, sampled=%t, name=%s",
 		s.SpanContext.TraceID, s.SpanContext.SpanID, s.ParentID,
@@ -1,6 +1,7 @@
 package mocktracer
 
 import (
+	"fmt"
 	"sync"
 
 	"github.com/opentracing/opentracing-go"
@@ -38,6 +39,30 @@ type MockTracer struct {
 	startedSpans  []*MockSpan
 	injectors     map[interface{}]Injector
 	extractors    map[interface{}]Extractor
+	injectHook    ErrorHook
+	extractHook   ErrorHook
+}
+
+// ErrorHook allows tests to make Inject or Extract fail on demand, e.g. to
+// simulate corrupted tracing headers. It is called with the format and the
+// carrier before the operation is performed; a non-nil return value is
+// returned to the caller instead of performing the operation.
+type ErrorHook func(format interface{}, carrier interface{}) error
+
+// SetInjectErrorHook installs a hook that is consulted by every Inject call.
+// Passing nil restores the default behavior.
+func (t *MockTracer) SetInjectErrorHook(hook ErrorHook) {
+	t.Lock()
+	defer t.Unlock()
+	t.injectHook = hook
+}
+
+// SetExtractErrorHook installs a hook that is consulted by every Extract call.
+// Passing nil restores the default behavior.
+func (t *MockTracer) SetExtractErrorHook(hook ErrorHook) {
+	t.Lock()
+	defer t.Unlock()
+	t.extractHook = hook
 }
 
 // UnfinishedSpans returns all spans that have been started and not finished since the
@@ -73,12 +98,10 @@ func (t *MockTracer) Reset() {
 
 // StartSpan belongs to the Tracer interface.
 func (t *MockTracer) StartSpan(operationName string, opts ...opentracing.StartSpanOption) opentracing.Span {
-	sso := opentracing.StartSpanOptions{}
-	for _, o := range opts {
-		o.Apply(&sso)
-	}
+	sso := opentracing.NewStartSpanOptions(opts...)
 
 	span := newMockSpan(t, operationName, sso)
+	opentracing.ApplyBaggage(span, sso)
 	t.recordStartedSpan(span)
 	return span
 }
@@ -95,26 +118,42 @@ func (t *MockTracer) RegisterExtractor(format interface{}, extractor Extractor)
 
 // Inject belongs to the Tracer interface.
 func (t *MockTracer) Inject(sm opentracing.SpanContext, format interface{}, carrier interface{}) error {
+	if hook, _ := t.errorHooks(); hook != nil {
+		if err := hook(format, carrier); err != nil {
+			return err
+		}
+	}
 	spanContext, ok := sm.(MockSpanContext)
 	if !ok {
-		return opentracing.ErrInvalidSpanContext
+		return fmt.Errorf("%w: %T", opentracing.ErrInvalidSpanContext, sm)
 	}
 	injector, ok := t.injectors[format]
 	if !ok {
-		return opentracing.ErrUnsupportedFormat
+		return fmt.Errorf("%w: %v", opentracing.ErrUnsupportedFormat, format)
 	}
 	return injector.Inject(spanContext, carrier)
 }
 
 // Extract belongs to the Tracer interface.
 func (t *MockTracer) Extract(format interface{}, carrier interface{}) (opentracing.SpanContext, error) {
+	if _, hook := t.errorHooks(); hook != nil {
+		if err := hook(format, carrier); err != nil {
+			return nil, err
+		}
+	}
 	extractor, ok := t.extractors[format]
 	if !ok {
-		return nil, opentracing.ErrUnsupportedFormat
+		return nil, fmt.Errorf("%w: %v", opentracing.ErrUnsupportedFormat, format)
 	}
 	return extractor.Extract(carrier)
 }
 
+func (t *MockTracer) errorHooks() (inject, extract ErrorHook) {
+	t.RLock()
+	defer t.RUnlock()
+	return t.injectHook, t.extractHook
+}
+
 func (t *MockTracer) recordStartedSpan(span *MockSpan) {
 	t.Lock()
 	defer t.Unlock()
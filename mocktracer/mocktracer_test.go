@@ -1,8 +1,12 @@
 package mocktracer
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"reflect"
+	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -12,6 +16,7 @@ import (
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/harness"
 	"github.com/opentracing/opentracing-go/log"
 )
 
@@ -36,6 +41,40 @@ func TestMockTracer_StartSpan(t *testing.T) {
 	assert.Equal(t, child.ParentID, parent.Context().(MockSpanContext).SpanID)
 }
 
+func TestMockTracer_StartSpanParentSelection(t *testing.T) {
+	tracer := New()
+	producer := tracer.StartSpan("producer")
+	parent := tracer.StartSpan("parent")
+
+	span := tracer.StartSpan("x",
+		opentracing.FollowsFrom(producer.Context()),
+		opentracing.SpanReference{Type: opentracing.ChildOfRef},
+		opentracing.ChildOf(parent.Context()))
+	assert.Equal(t, parent.Context().(MockSpanContext).SpanID, span.(*MockSpan).ParentID,
+		"ChildOf reference takes precedence over FollowsFrom")
+
+	span = tracer.StartSpan("y", opentracing.FollowsFrom(producer.Context()))
+	assert.Equal(t, producer.Context().(MockSpanContext).SpanID, span.(*MockSpan).ParentID)
+
+	span = tracer.StartSpan("z", opentracing.SpanReference{Type: opentracing.ChildOfRef})
+	assert.Equal(t, 0, span.(*MockSpan).ParentID)
+}
+
+func TestMockSpan_References(t *testing.T) {
+	tracer := New()
+	parent := tracer.StartSpan("parent")
+	producer := tracer.StartSpan("producer")
+	span := tracer.StartSpan("child",
+		opentracing.FollowsFrom(producer.Context()),
+		opentracing.ChildOf(parent.Context())).(*MockSpan)
+
+	assert.Equal(t, []opentracing.SpanReference{
+		opentracing.FollowsFrom(producer.Context()),
+		opentracing.ChildOf(parent.Context()),
+	}, span.References())
+	assert.Empty(t, parent.(*MockSpan).References())
+}
+
 func TestMockSpan_SetOperationName(t *testing.T) {
 	tracer := New()
 	span := tracer.StartSpan("")
@@ -67,6 +106,20 @@ func TestMockSpanContext_Baggage(t *testing.T) {
 	assert.Equal(t, 1, len(baggage))
 }
 
+func TestMockSpanContext_TraceIdentifiable(t *testing.T) {
+	tracer := New()
+	span := tracer.StartSpan("x")
+	sc := span.Context().(MockSpanContext)
+
+	ctx := opentracing.ContextWithSpan(context.Background(), span)
+	traceID, ok := opentracing.TraceIDFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, strconv.Itoa(sc.TraceID), traceID)
+	spanID, ok := opentracing.SpanIDFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, strconv.Itoa(sc.SpanID), spanID)
+}
+
 func TestMockSpan_Tag(t *testing.T) {
 	tracer := New()
 	span := tracer.StartSpan("x")
@@ -245,10 +298,10 @@ func TestMockTracer_Propagation(t *testing.T) {
 		}
 		mSpan := span.(*MockSpan)
 
-		assert.Equal(t, opentracing.ErrUnsupportedFormat,
-			tracer.Inject(span.Context(), opentracing.Binary, nil))
-		assert.Equal(t, opentracing.ErrInvalidCarrier,
-			tracer.Inject(span.Context(), opentracing.TextMap, span))
+		assert.ErrorIs(t,
+			tracer.Inject(span.Context(), opentracing.Binary, nil), opentracing.ErrUnsupportedFormat)
+		assert.ErrorIs(t,
+			tracer.Inject(span.Context(), opentracing.TextMap, span), opentracing.ErrInvalidCarrier)
 
 		carrier := test.carrier()
 
@@ -261,9 +314,9 @@ func TestMockTracer_Propagation(t *testing.T) {
 		}
 
 		_, err = tracer.Extract(opentracing.Binary, nil)
-		assert.Equal(t, opentracing.ErrUnsupportedFormat, err)
+		assert.ErrorIs(t, err, opentracing.ErrUnsupportedFormat)
 		_, err = tracer.Extract(opentracing.TextMap, tracer)
-		assert.Equal(t, opentracing.ErrInvalidCarrier, err)
+		assert.ErrorIs(t, err, opentracing.ErrInvalidCarrier)
 
 		extractedContext, err := tracer.Extract(test.format, carrier)
 		require.NoError(t, err)
@@ -274,6 +327,69 @@ func TestMockTracer_Propagation(t *testing.T) {
 	}
 }
 
+func TestMockTracer_PropagationErrors(t *testing.T) {
+	tracer := New()
+
+	err := tracer.Inject(foreignSpanContext{}, opentracing.TextMap, opentracing.TextMapCarrier{})
+	assert.True(t, opentracing.IsInvalidSpanContext(err))
+
+	_, err = tracer.Extract(opentracing.TextMap, opentracing.TextMapCarrier{})
+	assert.True(t, opentracing.IsSpanContextNotFound(err))
+
+	_, err = tracer.Extract("custom-format", opentracing.TextMapCarrier{})
+	assert.True(t, opentracing.IsUnsupportedFormat(err))
+	assert.Contains(t, err.Error(), "custom-format")
+
+	corrupted := opentracing.TextMapCarrier{
+		mockTextMapIdsPrefix + "traceid": "1",
+		mockTextMapIdsPrefix + "spanid":  "not-a-number",
+	}
+	_, err = tracer.Extract(opentracing.TextMap, corrupted)
+	assert.True(t, opentracing.IsSpanContextCorrupted(err))
+	assert.Contains(t, err.Error(), mockTextMapIdsPrefix+"spanid")
+}
+
+func TestMockTracer_ErrorHooks(t *testing.T) {
+	tracer := New()
+	span := tracer.StartSpan("x")
+	carrier := opentracing.TextMapCarrier{}
+	require.NoError(t, tracer.Inject(span.Context(), opentracing.TextMap, carrier))
+
+	// corrupt only carriers that contain a marker header
+	tracer.SetExtractErrorHook(func(format interface{}, c interface{}) error {
+		if tm, ok := c.(opentracing.TextMapCarrier); ok && tm["x-corrupt"] != "" {
+			return opentracing.ErrSpanContextCorrupted
+		}
+		return nil
+	})
+	_, err := tracer.Extract(opentracing.TextMap, carrier)
+	assert.NoError(t, err)
+	carrier["x-corrupt"] = "1"
+	_, err = tracer.Extract(opentracing.TextMap, carrier)
+	assert.Equal(t, opentracing.ErrSpanContextCorrupted, err)
+
+	injectErr := errors.New("inject failed")
+	tracer.SetInjectErrorHook(func(format interface{}, c interface{}) error {
+		if format == opentracing.HTTPHeaders {
+			return injectErr
+		}
+		return nil
+	})
+	assert.NoError(t, tracer.Inject(span.Context(), opentracing.TextMap, opentracing.TextMapCarrier{}))
+	assert.Equal(t, injectErr, tracer.Inject(span.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier{}))
+
+	// removing the hooks restores the default round trip
+	tracer.SetInjectErrorHook(nil)
+	tracer.SetExtractErrorHook(nil)
+	assert.NoError(t, tracer.Inject(span.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier{}))
+	_, err = tracer.Extract(opentracing.TextMap, carrier)
+	assert.NoError(t, err)
+}
+
+type foreignSpanContext struct{}
+
+func (foreignSpanContext) ForeachBaggageItem(handler func(k, v string) bool) {}
+
 func TestMockSpan_Races(t *testing.T) {
 	span := New().StartSpan("x")
 	var wg sync.WaitGroup
@@ -288,3 +404,106 @@ func TestMockSpan_Races(t *testing.T) {
 	}()
 	wg.Wait()
 }
+
+func TestMockSpan_Values(t *testing.T) {
+	tracer := New()
+	span := tracer.StartSpan("x")
+	ctx := opentracing.ContextWithSpan(context.Background(), span)
+
+	opentracing.SetSpanValue(opentracing.SpanFromContext(ctx), "shard", 7)
+	assert.Equal(t, 7, opentracing.SpanValue(opentracing.SpanFromContext(ctx), "shard"))
+	assert.Nil(t, opentracing.SpanValue(span, "missing"))
+
+	// values must not leak into the propagated context
+	carrier := opentracing.TextMapCarrier{}
+	require.NoError(t, tracer.Inject(span.Context(), opentracing.TextMap, carrier))
+	extracted, err := tracer.Extract(opentracing.TextMap, carrier)
+	require.NoError(t, err)
+	child := tracer.StartSpan("y", opentracing.ChildOf(extracted))
+	assert.Nil(t, opentracing.SpanValue(child, "shard"))
+	assert.Empty(t, extracted.(MockSpanContext).Baggage)
+}
+
+func TestMockSpan_ValuesRaces(t *testing.T) {
+	span := New().StartSpan("x")
+	ctx := opentracing.ContextWithSpan(context.Background(), span)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			opentracing.SetSpanValue(opentracing.SpanFromContext(ctx), "key", i)
+		}(i)
+		go func() {
+			defer wg.Done()
+			opentracing.SpanValue(opentracing.SpanFromContext(ctx), "key")
+		}()
+	}
+	wg.Wait()
+	assert.NotNil(t, opentracing.SpanValue(span, "key"))
+}
+
+func TestMockSpan_ConcurrentUsage(t *testing.T) {
+	tracer := New()
+	harness.CheckConcurrentSpanUsage(t, tracer)
+	assert.Len(t, tracer.FinishedSpans(), 1)
+}
+
+type mockProbe struct{}
+
+func (mockProbe) SameTrace(first, second opentracing.Span) bool {
+	return first.Context().(MockSpanContext).TraceID == second.Context().(MockSpanContext).TraceID
+}
+
+func (mockProbe) SameSpanContext(span opentracing.Span, sc opentracing.SpanContext) bool {
+	a, b := span.Context().(MockSpanContext), sc.(MockSpanContext)
+	return a.TraceID == b.TraceID && a.SpanID == b.SpanID
+}
+
+func TestMockTracer_PropagationHarness(t *testing.T) {
+	harness.RunPropagationTest(t, New(), harness.UseProbe(mockProbe{}))
+}
+
+func TestMockTracer_BaggageKeyNormalizer(t *testing.T) {
+	tracer := New()
+	span := tracer.StartSpan("x")
+	span.SetBaggageItem("UserID", "42")
+
+	extract := func(format interface{}, carrier interface{}) map[string]string {
+		require.NoError(t, tracer.Inject(span.Context(), format, carrier))
+		sc, err := tracer.Extract(format, carrier)
+		require.NoError(t, err)
+		return sc.(MockSpanContext).Baggage
+	}
+
+	// by default TextMap preserves the case, HTTP headers do not
+	assert.Equal(t, map[string]string{"UserID": "42"}, extract(opentracing.TextMap, opentracing.TextMapCarrier{}))
+	assert.Equal(t, map[string]string{"userid": "42"}, extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(http.Header{})))
+
+	opentracing.SetBaggageKeyNormalizer(strings.ToLower)
+	defer opentracing.SetBaggageKeyNormalizer(nil)
+	assert.Equal(t, map[string]string{"userid": "42"}, extract(opentracing.TextMap, opentracing.TextMapCarrier{}))
+	assert.Equal(t, map[string]string{"userid": "42"}, extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(http.Header{})))
+}
+
+func TestMockTracer_StartSpanWithBaggage(t *testing.T) {
+	tracer := New()
+	parent := tracer.StartSpan("parent")
+	parent.SetBaggageItem("tenant", "a")
+
+	span := tracer.StartSpan("x",
+		opentracing.ChildOf(parent.Context()),
+		opentracing.WithBaggage(map[string]string{"user": "42", "tenant": "b"}))
+	assert.Equal(t, "42", span.BaggageItem("user"))
+	assert.Equal(t, "b", span.BaggageItem("tenant"))
+	assert.Equal(t, "a", parent.BaggageItem("tenant"), "the parent baggage must not change")
+
+	// ApplyBaggage for tracers that ignore StartSpanOptions.Baggage
+	sso := opentracing.StartSpanOptions{}
+	opentracing.WithBaggage(map[string]string{"k": "v"}).Apply(&sso)
+	noop := opentracing.NoopTracer{}.StartSpan("x")
+	opentracing.ApplyBaggage(noop, sso)
+	other := tracer.StartSpan("y")
+	opentracing.ApplyBaggage(other, sso)
+	assert.Equal(t, "v", other.BaggageItem("k"))
+}
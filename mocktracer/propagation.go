@@ -47,7 +47,7 @@ type TextMapPropagator struct {
 func (t *TextMapPropagator) Inject(spanContext MockSpanContext, carrier interface{}) error {
 	writer, ok := carrier.(opentracing.TextMapWriter)
 	if !ok {
-		return opentracing.ErrInvalidCarrier
+		return fmt.Errorf("%w: %T is not a TextMapWriter", opentracing.ErrInvalidCarrier, carrier)
 	}
 	// Ids:
 	writer.Set(mockTextMapIdsPrefix+"traceid", strconv.Itoa(spanContext.TraceID))
@@ -59,7 +59,7 @@ func (t *TextMapPropagator) Inject(spanContext MockSpanContext, carrier interfac
 		if t.HTTPHeaders {
 			safeVal = url.QueryEscape(baggageVal)
 		}
-		writer.Set(mockTextMapBaggagePrefix+baggageKey, safeVal)
+		writer.Set(mockTextMapBaggagePrefix+opentracing.NormalizeBaggageKey(baggageKey), safeVal)
 	}
 	return nil
 }
@@ -68,7 +68,7 @@ func (t *TextMapPropagator) Inject(spanContext MockSpanContext, carrier interfac
 func (t *TextMapPropagator) Extract(carrier interface{}) (MockSpanContext, error) {
 	reader, ok := carrier.(opentracing.TextMapReader)
 	if !ok {
-		return emptyContext, opentracing.ErrInvalidCarrier
+		return emptyContext, fmt.Errorf("%w: %T is not a TextMapReader", opentracing.ErrInvalidCarrier, carrier)
 	}
 	rval := MockSpanContext{0, 0, true, nil}
 	err := reader.ForeachKey(func(key, val string) error {
@@ -78,20 +78,20 @@ func (t *TextMapPropagator) Extract(carrier interface{}) (MockSpanContext, error
 			// Ids:
 			i, err := strconv.Atoi(val)
 			if err != nil {
-				return err
+				return corruptedKeyError(key, err)
 			}
 			rval.TraceID = i
 		case lowerKey == mockTextMapIdsPrefix+"spanid":
 			// Ids:
 			i, err := strconv.Atoi(val)
 			if err != nil {
-				return err
+				return corruptedKeyError(key, err)
 			}
 			rval.SpanID = i
 		case lowerKey == mockTextMapIdsPrefix+"sampled":
 			b, err := strconv.ParseBool(val)
 			if err != nil {
-				return err
+				return corruptedKeyError(key, err)
 			}
 			rval.Sampled = b
 		case strings.HasPrefix(lowerKey, mockTextMapBaggagePrefix):
@@ -106,15 +106,26 @@ func (t *TextMapPropagator) Extract(carrier interface{}) (MockSpanContext, error
 					safeVal = rawVal
 				}
 			}
-			rval.Baggage[lowerKey[len(mockTextMapBaggagePrefix):]] = safeVal
+			baggageKey := key[len(mockTextMapBaggagePrefix):]
+			if t.HTTPHeaders {
+				// HTTP headers do not preserve the case of the key
+				baggageKey = lowerKey[len(mockTextMapBaggagePrefix):]
+			}
+			rval.Baggage[opentracing.NormalizeBaggageKey(baggageKey)] = safeVal
 		}
 		return nil
 	})
-	if rval.TraceID == 0 || rval.SpanID == 0 {
-		return emptyContext, opentracing.ErrSpanContextNotFound
-	}
 	if err != nil {
 		return emptyContext, err
 	}
+	if rval.TraceID == 0 || rval.SpanID == 0 {
+		return emptyContext, opentracing.ErrSpanContextNotFound
+	}
 	return rval, nil
 }
+
+// corruptedKeyError wraps opentracing.ErrSpanContextCorrupted with the name
+// of the offending carrier key and the underlying parse error.
+func corruptedKeyError(key string, err error) error {
+	return fmt.Errorf("%w: key %q: %v", opentracing.ErrSpanContextCorrupted, key, err)
+}
@@ -0,0 +1,205 @@
+package opentracing
+
+import (
+	"sync"
+
+	"github.com/opentracing/opentracing-go/log"
+)
+
+// MultiTracer 返回一个把每个 Span 同时交给 tracers 中的每一个 Tracer 的 Tracer，
+// 例如在迁移期间同时把数据发送给旧的后端和新的后端。
+//
+// StartSpan 在每个 Tracer 上各创建一个 Span，并返回一个聚合的 Span，
+// 它的 SetTag、LogFields、SetBaggageItem、Finish 等方法会广播给所有的底层 Span，BaggageItem 读取第一个 Span。
+//
+// 聚合的 Span 的 Context() 返回第一个 Tracer 中的 Span 的 SpanContext，因此可以直接交给第一个 Tracer 的 Inject，
+// 也可以断言为它的具体类型。Inject 和 Extract 都委托给第一个 Tracer。
+//
+// 以一个尚未结束的聚合的 Span 的 Context() 作为引用时，每个 Tracer 都会收到它自己的 SpanContext。
+// 这要求第一个 Tracer 的 SpanContext 实现 TraceIdentifiable，聚合的 Span 通过 trace id 和 span id 找到其他 Tracer 中对应的 Span。
+// 其他的 SpanContext（例如 Extract 的结果，或者已经结束的 Span 的 SpanContext）只会传递给第一个 Tracer，
+// 其他 Tracer 中的 Span 会成为根Span。
+//
+// 如果 tracers 为空，返回 NoopTracer；如果只有一个 Tracer，则直接返回它。
+func MultiTracer(tracers ...Tracer) Tracer {
+	switch len(tracers) {
+	case 0:
+		return NoopTracer{}
+	case 1:
+		return tracers[0]
+	}
+	return &multiTracer{tracers: append([]Tracer(nil), tracers...), live: make(map[multiSpanKey]*multiSpan)}
+}
+
+// MultiTracerWithPrimary 与 MultiTracer(primary, secondaries...) 相同，但显式地指定了负责传播的主 Tracer：
+// Inject 和 Extract 只会使用 primary，而 secondaries 只接收 Span 的数据。
+func MultiTracerWithPrimary(primary Tracer, secondaries ...Tracer) Tracer {
+	return MultiTracer(append([]Tracer{primary}, secondaries...)...)
+}
+
+type multiTracer struct {
+	tracers []Tracer
+
+	mu   sync.Mutex
+	live map[multiSpanKey]*multiSpan // 尚未结束的 Span，以第一个 Tracer 中的 SpanContext 为键
+}
+
+// multiSpanKey 通过 TraceIdentifiable 标识第一个 Tracer 中的一个 SpanContext。
+type multiSpanKey struct {
+	traceID, spanID string
+}
+
+func multiSpanKeyOf(sc SpanContext) (multiSpanKey, bool) {
+	ids, ok := sc.(TraceIdentifiable)
+	if !ok {
+		return multiSpanKey{}, false
+	}
+	return multiSpanKey{traceID: ids.TraceIDString(), spanID: ids.SpanIDString()}, true
+}
+
+// lookup 返回 SpanContext 为 sc 的尚未结束的聚合的 Span。
+func (t *multiTracer) lookup(sc SpanContext) *multiSpan {
+	key, ok := multiSpanKeyOf(sc)
+	if !ok {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.live[key]
+}
+
+func (t *multiTracer) StartSpan(operationName string, opts ...StartSpanOption) Span {
+	sso := NewStartSpanOptions(opts...)
+	parents := make([]*multiSpan, len(sso.References))
+	for j, ref := range sso.References {
+		parents[j] = t.lookup(ref.ReferencedContext)
+	}
+	spans := make([]Span, len(t.tracers))
+	for i, tracer := range t.tracers {
+		tracerOpts := sso
+		tracerOpts.Tags = sso.CloneTags()
+		tracerOpts.References = nil
+		for j, ref := range sso.References {
+			if parents[j] != nil {
+				ref.ReferencedContext = parents[j].spans[i].Context()
+			} else if i > 0 {
+				continue
+			}
+			tracerOpts.References = append(tracerOpts.References, ref)
+		}
+		spans[i] = tracer.StartSpan(operationName, appliedStartSpanOptions(tracerOpts))
+	}
+	sp := &multiSpan{spans: spans, tracer: t}
+	if key, ok := multiSpanKeyOf(spans[0].Context()); ok {
+		sp.key = &key
+		t.mu.Lock()
+		t.live[key] = sp
+		t.mu.Unlock()
+	}
+	return sp
+}
+
+func (t *multiTracer) Inject(sc SpanContext, format interface{}, carrier interface{}) error {
+	return t.tracers[0].Inject(sc, format, carrier)
+}
+
+func (t *multiTracer) Extract(format interface{}, carrier interface{}) (SpanContext, error) {
+	return t.tracers[0].Extract(format, carrier)
+}
+
+type multiSpan struct {
+	spans  []Span
+	tracer *multiTracer
+	key    *multiSpanKey // 在 multiTracer.live 中的键，为nil时没有注册
+}
+
+func (s *multiSpan) Context() SpanContext {
+	return s.spans[0].Context()
+}
+
+// unregister 在 Span 结束后把它从 multiTracer.live 中删除，之后以它作为引用的 Span 在其他 Tracer 中会成为根Span。
+func (s *multiSpan) unregister() {
+	if s.key == nil {
+		return
+	}
+	s.tracer.mu.Lock()
+	if s.tracer.live[*s.key] == s {
+		delete(s.tracer.live, *s.key)
+	}
+	s.tracer.mu.Unlock()
+}
+
+func (s *multiSpan) Tracer() Tracer { return s.tracer }
+
+func (s *multiSpan) BaggageItem(restrictedKey string) string {
+	return s.spans[0].BaggageItem(restrictedKey)
+}
+
+func (s *multiSpan) SetOperationName(operationName string) Span {
+	for _, sp := range s.spans {
+		sp.SetOperationName(operationName)
+	}
+	return s
+}
+
+func (s *multiSpan) SetTag(key string, value interface{}) Span {
+	for _, sp := range s.spans {
+		sp.SetTag(key, value)
+	}
+	return s
+}
+
+func (s *multiSpan) SetBaggageItem(restrictedKey, value string) Span {
+	for _, sp := range s.spans {
+		sp.SetBaggageItem(restrictedKey, value)
+	}
+	return s
+}
+
+func (s *multiSpan) LogFields(fields ...log.Field) {
+	for _, sp := range s.spans {
+		sp.LogFields(fields...)
+	}
+}
+
+func (s *multiSpan) LogKV(alternatingKeyValues ...interface{}) {
+	for _, sp := range s.spans {
+		sp.LogKV(alternatingKeyValues...)
+	}
+}
+
+func (s *multiSpan) Finish() {
+	for _, sp := range s.spans {
+		sp.Finish()
+	}
+	s.unregister()
+}
+
+func (s *multiSpan) FinishWithOptions(opts FinishOptions) {
+	for _, sp := range s.spans {
+		// 每个底层 Span 都拥有自己的 LogRecords 切片
+		spanOpts := opts
+		spanOpts.LogRecords = append([]LogRecord(nil), opts.LogRecords...)
+		spanOpts.BulkLogData = append([]LogData(nil), opts.BulkLogData...)
+		sp.FinishWithOptions(spanOpts)
+	}
+	s.unregister()
+}
+
+func (s *multiSpan) LogEvent(event string) {
+	for _, sp := range s.spans {
+		sp.LogEvent(event)
+	}
+}
+
+func (s *multiSpan) LogEventWithPayload(event string, payload interface{}) {
+	for _, sp := range s.spans {
+		sp.LogEventWithPayload(event, payload)
+	}
+}
+
+func (s *multiSpan) Log(data LogData) {
+	for _, sp := range s.spans {
+		sp.Log(data)
+	}
+}
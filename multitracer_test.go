@@ -0,0 +1,110 @@
+package opentracing_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+func TestMultiTracer(t *testing.T) {
+	legacy, jaeger := mocktracer.New(), mocktracer.New()
+	tracer := opentracing.MultiTracer(legacy, jaeger)
+
+	parent := tracer.StartSpan("parent", opentracing.Tag{Key: "start", Value: 1})
+	assert.Equal(t, tracer, parent.Tracer())
+	assert.Equal(t, parent, parent.SetTag("k", "v"))
+	parent.SetBaggageItem("user", "42")
+	parent.LogFields(log.String("event", "e"))
+	child := parent.Tracer().StartSpan("child", opentracing.ChildOf(parent.Context()))
+	child.Finish()
+	parent.Finish()
+
+	for _, mt := range []*mocktracer.MockTracer{legacy, jaeger} {
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 2)
+		assert.Equal(t, map[string]interface{}{"start": 1, "k": "v"}, spans[1].Tags())
+		assert.Len(t, spans[1].Logs(), 1)
+		assert.Equal(t, "42", spans[1].BaggageItem("user"))
+		assert.Equal(t, spans[1].SpanContext.SpanID, spans[0].ParentID, "child must reference the parent in every tracer")
+	}
+}
+
+func TestMultiTracerPropagation(t *testing.T) {
+	legacy, jaeger := mocktracer.New(), mocktracer.New()
+	tracer := opentracing.MultiTracer(legacy, jaeger)
+
+	span := tracer.StartSpan("op")
+	span.SetBaggageItem("user", "42")
+	carrier := opentracing.TextMapCarrier{}
+	require.NoError(t, tracer.Inject(span.Context(), opentracing.TextMap, carrier))
+
+	sc, err := tracer.Extract(opentracing.TextMap, carrier)
+	require.NoError(t, err)
+	assert.Equal(t, legacy.UnfinishedSpans()[0].Context(), sc)
+	span.Finish()
+
+	// 被提取的 SpanContext 只属于第一个 Tracer
+	tracer.StartSpan("server", opentracing.ChildOf(sc)).Finish()
+	assert.Equal(t, sc.(mocktracer.MockSpanContext).SpanID, legacy.FinishedSpans()[1].ParentID)
+	assert.Equal(t, 0, jaeger.FinishedSpans()[1].ParentID)
+}
+
+func TestMultiTracerContext(t *testing.T) {
+	legacy, jaeger := mocktracer.New(), mocktracer.New()
+	tracer := opentracing.MultiTracer(legacy, jaeger)
+
+	span := tracer.StartSpan("op")
+	defer span.Finish()
+	sc, ok := span.Context().(mocktracer.MockSpanContext)
+	require.True(t, ok, "Context must return the first tracer's SpanContext")
+	assert.Equal(t, legacy.UnfinishedSpans()[0].Context(), sc)
+	require.NoError(t, legacy.Inject(span.Context(), opentracing.TextMap, opentracing.TextMapCarrier{}))
+
+	ctx := opentracing.ContextWithSpan(context.Background(), span)
+	traceID, ok := opentracing.TraceIDFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, sc.TraceIDString(), traceID)
+	sampled, known := opentracing.IsSampled(span.Context())
+	assert.True(t, sampled)
+	assert.True(t, known)
+
+	// 除了底层 Span 的 Context() 本身的分配之外没有额外的分配
+	inner := legacy.UnfinishedSpans()[0]
+	assert.Equal(t, testing.AllocsPerRun(10, func() { inner.Context() }), testing.AllocsPerRun(10, func() { span.Context() }))
+
+	// 通过 context.Context 传递的父级在每个 Tracer 中都能找到
+	child, _ := opentracing.StartSpanFromContextWithTracer(ctx, tracer, "child")
+	child.Finish()
+	assert.Equal(t, legacy.UnfinishedSpans()[0].Context().(mocktracer.MockSpanContext).SpanID, legacy.FinishedSpans()[0].ParentID)
+	assert.Equal(t, jaeger.UnfinishedSpans()[0].Context().(mocktracer.MockSpanContext).SpanID, jaeger.FinishedSpans()[0].ParentID)
+}
+
+func TestMultiTracerDegenerate(t *testing.T) {
+	assert.Equal(t, opentracing.NoopTracer{}, opentracing.MultiTracer())
+	mt := mocktracer.New()
+	assert.Equal(t, mt, opentracing.MultiTracer(mt))
+}
+
+func TestMultiTracerWithPrimary(t *testing.T) {
+	primary, secondary := mocktracer.New(), mocktracer.New()
+	tracer := opentracing.MultiTracerWithPrimary(primary, secondary)
+
+	span := tracer.StartSpan("op")
+	span.SetTag("k", "v")
+	span.Finish()
+	carrier := opentracing.TextMapCarrier{}
+	require.NoError(t, tracer.Inject(span.Context(), opentracing.TextMap, carrier))
+
+	sc, err := primary.Extract(opentracing.TextMap, carrier)
+	require.NoError(t, err)
+	assert.Equal(t, primary.FinishedSpans()[0].Context(), sc)
+	assert.Equal(t, "v", secondary.FinishedSpans()[0].Tag("k"))
+
+	assert.Equal(t, primary, opentracing.MultiTracerWithPrimary(primary))
+}
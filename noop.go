@@ -1,6 +1,12 @@
 package opentracing
 
-import "github.com/opentracing/opentracing-go/log"
+import (
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/opentracing/opentracing-go/log"
+)
 
 // NoopTracer 是一个微不足道的，最小开销的 Tracer 实现，该实现中所有方法都是空操作(no-ops)。
 //
@@ -11,7 +17,7 @@ import "github.com/opentracing/opentracing-go/log"
 // 基于同样的原因，NoopTracer 是全局tracer的默认值。
 // （见 GlobalTracer 和 SetGlobalTracer）
 //
-// 警告(WARNING)： NoopTracer 没有支持携带数据(baggage)的传播
+// 警告(WARNING)： NoopTracer 没有支持携带数据(baggage)的传播，需要携带数据的应用可以使用 NoopTracerWithBaggage
 type NoopTracer struct{}
 
 type noopSpan struct{}
@@ -59,3 +65,166 @@ func (n NoopTracer) Inject(sp SpanContext, format interface{}, carrier interface
 func (n NoopTracer) Extract(format interface{}, carrier interface{}) (SpanContext, error) {
 	return nil, ErrSpanContextNotFound
 }
+
+// NoopTracerWithExtract 返回一个除了 Extract 之外与 NoopTracer 完全相同的 Tracer，
+// 它的 Extract 会委托给 extract 函数。
+//
+// 这适用于降级到 noop 但仍需要读取上游上下文的场景，例如只提取 trace id 或携带数据(baggage)用于日志。
+// 如果 extract 为空(nil)，Extract 会与 NoopTracer 一样返回 ErrSpanContextNotFound。
+func NoopTracerWithExtract(extract func(format interface{}, carrier interface{}) (SpanContext, error)) Tracer {
+	return noopTracerWithExtract{extract: extract}
+}
+
+type noopTracerWithExtract struct {
+	NoopTracer
+	extract func(format interface{}, carrier interface{}) (SpanContext, error)
+}
+
+// IsNoop 实现 NoopAware 接口
+func (n noopTracerWithExtract) IsNoop() bool { return true }
+
+// Extract 实现 Tracer 接口
+func (n noopTracerWithExtract) Extract(format interface{}, carrier interface{}) (SpanContext, error) {
+	if n.extract == nil {
+		return n.NoopTracer.Extract(format, carrier)
+	}
+	return n.extract(format, carrier)
+}
+
+// NoopBaggagePrefix 是 NoopTracerWithBaggage 通过 TextMap 和 HTTPHeaders 传播携带数据(baggage)时使用的键前缀。
+const NoopBaggagePrefix = "ot-baggage-"
+
+// NoopTracerWithBaggage 返回一个只支持携带数据(baggage)的 Tracer：它的 Span 会在内存中保存携带数据，
+// 新的 Span 会继承所有引用(References)的 SpanContext 以及 WithBaggage 选项中的携带数据，
+// 并且通过 TextMap 和 HTTPHeaders 格式以`ot-baggage-<key>`为键（值经过 URL 编码）注入和提取携带数据
+// （HTTP 头不保留大小写，所以通过 HTTPHeaders 提取的键都是小写的）；
+// 其余所有操作（tag、日志、其它格式的传播等）都与 NoopTracer 一样是空操作。
+//
+// 这适用于依赖携带数据实现非链路追踪功能（例如按租户路由）的应用，在注册真正的 Tracer 之前也能正常工作：
+//
+//    opentracing.SetGlobalTracer(opentracing.NoopTracerWithBaggage())
+//
+// 与 NoopTracer 不同，它没有实现 NoopAware，所以 StartSpanFromContext 会照常把`ctx`中的 Span 作为父级，
+// 使携带数据沿着调用链传递下去。
+func NoopTracerWithBaggage() Tracer {
+	return baggageNoopTracer{}
+}
+
+type baggageNoopTracer struct{}
+
+// baggageNoopSpanContext 是不可变的，修改携带数据的 Span 会换成一个新的 baggageNoopSpanContext。
+type baggageNoopSpanContext struct {
+	baggage map[string]string
+}
+
+func (c baggageNoopSpanContext) ForeachBaggageItem(handler func(k, v string) bool) {
+	for k, v := range c.baggage {
+		if !handler(k, v) {
+			return
+		}
+	}
+}
+
+type baggageNoopSpan struct {
+	noopSpan
+
+	mu  sync.RWMutex
+	ctx baggageNoopSpanContext
+}
+
+func (s *baggageNoopSpan) Context() SpanContext {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ctx
+}
+
+func (s *baggageNoopSpan) SetBaggageItem(key, val string) Span {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	baggage := make(map[string]string, len(s.ctx.baggage)+1)
+	for k, v := range s.ctx.baggage {
+		baggage[k] = v
+	}
+	baggage[key] = val
+	s.ctx = baggageNoopSpanContext{baggage: baggage}
+	return s
+}
+
+func (s *baggageNoopSpan) BaggageItem(key string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ctx.baggage[key]
+}
+
+func (s *baggageNoopSpan) SetTag(key string, value interface{}) Span  { return s }
+func (s *baggageNoopSpan) SetOperationName(operationName string) Span { return s }
+func (s *baggageNoopSpan) Tracer() Tracer                             { return baggageNoopTracer{} }
+
+// StartSpan 实现 Tracer 接口
+func (t baggageNoopTracer) StartSpan(operationName string, opts ...StartSpanOption) Span {
+	sso := NewStartSpanOptions(opts...)
+	baggage := make(map[string]string)
+	for _, ref := range sso.References {
+		for k, v := range baggageMap(ref.ReferencedContext) {
+			baggage[k] = v
+		}
+	}
+	for k, v := range sso.Baggage {
+		baggage[k] = v
+	}
+	return &baggageNoopSpan{ctx: baggageNoopSpanContext{baggage: baggage}}
+}
+
+// Inject 实现 Tracer 接口
+func (t baggageNoopTracer) Inject(sc SpanContext, format interface{}, carrier interface{}) error {
+	if format != TextMap && format != HTTPHeaders {
+		return nil
+	}
+	writer, ok := carrier.(TextMapWriter)
+	if !ok {
+		return ErrInvalidCarrier
+	}
+	if sc == nil {
+		return nil
+	}
+	sc.ForeachBaggageItem(func(k, v string) bool {
+		writer.Set(NoopBaggagePrefix+NormalizeBaggageKey(k), url.QueryEscape(v))
+		return true
+	})
+	return nil
+}
+
+// Extract 实现 Tracer 接口
+func (t baggageNoopTracer) Extract(format interface{}, carrier interface{}) (SpanContext, error) {
+	if format != TextMap && format != HTTPHeaders {
+		return nil, ErrSpanContextNotFound
+	}
+	reader, ok := carrier.(TextMapReader)
+	if !ok {
+		return nil, ErrInvalidCarrier
+	}
+	baggage := make(map[string]string)
+	err := reader.ForeachKey(func(key, val string) error {
+		if len(key) <= len(NoopBaggagePrefix) || !strings.EqualFold(key[:len(NoopBaggagePrefix)], NoopBaggagePrefix) {
+			return nil
+		}
+		v, err := url.QueryUnescape(val)
+		if err != nil {
+			return ErrSpanContextCorrupted
+		}
+		baggageKey := key[len(NoopBaggagePrefix):]
+		if format == HTTPHeaders {
+			// HTTP 头不保留键的大小写
+			baggageKey = strings.ToLower(baggageKey)
+		}
+		baggage[NormalizeBaggageKey(baggageKey)] = v
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(baggage) == 0 {
+		return nil, ErrSpanContextNotFound
+	}
+	return baggageNoopSpanContext{baggage: baggage}, nil
+}
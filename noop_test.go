@@ -0,0 +1,81 @@
+package opentracing
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoopTracerExtract(t *testing.T) {
+	sc, err := NoopTracer{}.Extract(TextMap, TextMapCarrier{})
+	assert.Nil(t, sc)
+	assert.Equal(t, ErrSpanContextNotFound, err)
+
+	sc, err = NoopTracerWithExtract(nil).Extract(TextMap, TextMapCarrier{})
+	assert.Nil(t, sc)
+	assert.Equal(t, ErrSpanContextNotFound, err)
+}
+
+func TestNoopTracerWithExtract(t *testing.T) {
+	tracer := NoopTracerWithExtract(func(format interface{}, carrier interface{}) (SpanContext, error) {
+		var sc BasicSpanContext
+		err := carrier.(TextMapReader).ForeachKey(func(key, val string) error {
+			if key == "user_id" {
+				sc = sc.WithBaggageItem(key, val)
+			}
+			return nil
+		})
+		return sc, err
+	})
+
+	sc, err := tracer.Extract(TextMap, TextMapCarrier{"user_id": "42", "other": "x"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"user_id": "42"}, sc.(BasicSpanContext).Baggage)
+
+	// 其余行为与 NoopTracer 相同
+	span := tracer.StartSpan("op", ChildOf(sc))
+	assert.Equal(t, defaultNoopSpan, span)
+	assert.NoError(t, tracer.Inject(span.Context(), TextMap, TextMapCarrier{}))
+}
+
+func TestNoopTracerWithBaggage(t *testing.T) {
+	tracer := NoopTracerWithBaggage()
+	parent := tracer.StartSpan("parent", WithBaggage(map[string]string{"region": "eu"}))
+	assert.Equal(t, parent, parent.SetBaggageItem("tenant", "acme corp"), "SetBaggageItem must return the span")
+	assert.Equal(t, parent, parent.SetTag("k", "v"))
+	assert.Equal(t, "acme corp", parent.BaggageItem("tenant"))
+	assert.Equal(t, tracer, parent.Tracer())
+
+	// StartSpanFromContext 会把 ctx 中的 Span 作为父级
+	child, _ := StartSpanFromContextWithTracer(ContextWithSpan(context.Background(), parent), tracer, "child")
+	assert.Equal(t, map[string]string{"region": "eu", "tenant": "acme corp"}, baggageMap(child.Context()))
+
+	// 之前取出的 SpanContext 不受之后的修改影响
+	before := parent.Context()
+	parent.SetBaggageItem("tenant", "other")
+	assert.Equal(t, "acme corp", baggageMap(before)["tenant"])
+
+	textMap := TextMapCarrier{}
+	assert.NoError(t, tracer.Inject(child.Context(), TextMap, textMap))
+	assert.Equal(t, "acme+corp", textMap[NoopBaggagePrefix+"tenant"])
+	sc, err := tracer.Extract(TextMap, textMap)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"region": "eu", "tenant": "acme corp"}, baggageMap(sc))
+
+	headers := HTTPHeadersCarrier(http.Header{})
+	assert.NoError(t, tracer.Inject(child.Context(), HTTPHeaders, headers))
+	assert.Equal(t, "acme+corp", http.Header(headers).Get("Ot-Baggage-Tenant"))
+	sc, err = tracer.Extract(HTTPHeaders, headers)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"region": "eu", "tenant": "acme corp"}, baggageMap(sc))
+
+	_, err = tracer.Extract(TextMap, TextMapCarrier{"other": "x"})
+	assert.Equal(t, ErrSpanContextNotFound, err)
+	_, err = tracer.Extract(TextMap, TextMapCarrier{NoopBaggagePrefix + "k": "%zz"})
+	assert.Equal(t, ErrSpanContextCorrupted, err)
+	assert.Equal(t, ErrInvalidCarrier, tracer.Inject(child.Context(), TextMap, "not a carrier"))
+	assert.NoError(t, tracer.Inject(child.Context(), Binary, "ignored"))
+	assert.False(t, isNoopTracer(tracer))
+}
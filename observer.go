@@ -0,0 +1,206 @@
+package opentracing
+
+import (
+	"sync"
+	"time"
+)
+
+// TracerObserver 可以观察 Tracer 创建的每一个 Span，例如用于在不修改 Tracer 实现的情况下统计指标。
+type TracerObserver interface {
+	// OnStartSpan 在 Span 开始之后被调用。如果该观察者需要观察这个 Span，则返回一个 SpanObserver 和 true。
+	OnStartSpan(sp Span, operationName string, opts StartSpanOptions) (SpanObserver, bool)
+}
+
+// SpanObserver 接收一个 Span 的生命周期事件。
+type SpanObserver interface {
+	// OnSetOperationName 在 Span.SetOperationName 时被调用
+	OnSetOperationName(operationName string)
+	// OnSetTag 在 Span.SetTag 时被调用
+	OnSetTag(key string, value interface{})
+	// OnFinish 在 Span.Finish 或 Span.FinishWithOptions 时被调用，重复结束同一个 Span 时只会在第一次被调用
+	OnFinish(opts FinishOptions)
+}
+
+// WithObservers 返回一个包装了 tracer 的 Tracer，它把每个 Span 的开始、SetOperationName、SetTag 和结束事件
+// 分发给 observers，例如用于统计 RED 指标，而不需要修改 Tracer 的实现。
+//
+// 每个 TracerObserver 的 OnStartSpan 会以应用后的选项被调用，返回 false 的观察者不会收到该 Span 之后的事件。
+// 通过返回的 Span 的 Tracer() 创建的子Span也会被观察。如果没有 observers，直接返回 tracer。
+//
+// Inject 和 Extract 会直接委托给 tracer。
+func WithObservers(tracer Tracer, observers ...TracerObserver) Tracer {
+	if len(observers) == 0 {
+		return tracer
+	}
+	return &observedTracer{Tracer: tracer, observers: observers}
+}
+
+type observedTracer struct {
+	Tracer
+	observers []TracerObserver
+}
+
+func (t *observedTracer) StartSpan(operationName string, opts ...StartSpanOption) Span {
+	sso := NewStartSpanOptions(opts...)
+	// TracerObserver 可能直接读取 Tags 字段
+	sso.TagsMap()
+	sp := t.Tracer.StartSpan(operationName, appliedStartSpanOptions(sso))
+	var spanObservers []SpanObserver
+	for _, o := range t.observers {
+		if so, ok := o.OnStartSpan(sp, operationName, sso); ok {
+			spanObservers = append(spanObservers, so)
+		}
+	}
+	if len(spanObservers) == 0 {
+		return &tracerOverrideSpan{Span: sp, tracer: t}
+	}
+	return &observedSpan{Span: sp, tracer: t, observers: spanObservers}
+}
+
+// observedSpan 把 Span 的事件分发给 observers
+type observedSpan struct {
+	Span
+	tracer    Tracer
+	observers []SpanObserver
+	finished  sync.Once
+}
+
+func (s *observedSpan) Tracer() Tracer { return s.tracer }
+
+func (s *observedSpan) SetOperationName(operationName string) Span {
+	s.Span.SetOperationName(operationName)
+	for _, o := range s.observers {
+		o.OnSetOperationName(operationName)
+	}
+	return s
+}
+
+func (s *observedSpan) SetTag(key string, value interface{}) Span {
+	s.Span.SetTag(key, value)
+	for _, o := range s.observers {
+		o.OnSetTag(key, value)
+	}
+	return s
+}
+
+func (s *observedSpan) SetBaggageItem(restrictedKey, value string) Span {
+	s.Span.SetBaggageItem(restrictedKey, value)
+	return s
+}
+
+func (s *observedSpan) Finish() {
+	s.Span.Finish()
+	s.notifyFinish(FinishOptions{})
+}
+
+func (s *observedSpan) FinishWithOptions(opts FinishOptions) {
+	s.Span.FinishWithOptions(opts)
+	s.notifyFinish(opts)
+}
+
+func (s *observedSpan) notifyFinish(opts FinishOptions) {
+	s.finished.Do(func() {
+		for _, o := range s.observers {
+			o.OnFinish(opts)
+		}
+	})
+}
+
+// LatencyStats 是 LatencyObserver 对一个操作名的耗时统计。
+type LatencyStats struct {
+	Count int64
+	Min   time.Duration
+	Max   time.Duration
+	Mean  time.Duration
+}
+
+// LatencyObserver 是一个 TracerObserver，它按操作名（以 Span 结束时的名字为准）统计已完成的 Span 的耗时，
+// 适用于在本地压测时快速查看每个操作的耗时，而不需要完整的链路追踪后端。
+//
+// 开始时间取自 StartSpanOptions.StartTime，结束时间取自 FinishOptions.FinishTime，零值时都使用 OnStartSpan 或 OnFinish 被调用的时间。
+// LatencyObserver 可以在多个goroutine中并发使用。
+type LatencyObserver struct {
+	mu    sync.Mutex
+	stats map[string]*latencyAccumulator
+}
+
+type latencyAccumulator struct {
+	count    int64
+	min, max time.Duration
+	total    time.Duration
+}
+
+// NewLatencyObserver 返回一个空的 LatencyObserver。
+func NewLatencyObserver() *LatencyObserver {
+	return &LatencyObserver{stats: make(map[string]*latencyAccumulator)}
+}
+
+// OnStartSpan 实现 TracerObserver 接口
+func (o *LatencyObserver) OnStartSpan(sp Span, operationName string, opts StartSpanOptions) (SpanObserver, bool) {
+	start := opts.StartTime
+	if start.IsZero() {
+		start = time.Now()
+	}
+	return &latencySpanObserver{observer: o, operationName: operationName, start: start}, true
+}
+
+// Snapshot 返回当前每个操作名的统计的副本。
+func (o *LatencyObserver) Snapshot() map[string]LatencyStats {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	snapshot := make(map[string]LatencyStats, len(o.stats))
+	for name, acc := range o.stats {
+		snapshot[name] = LatencyStats{
+			Count: acc.count,
+			Min:   acc.min,
+			Max:   acc.max,
+			Mean:  acc.total / time.Duration(acc.count),
+		}
+	}
+	return snapshot
+}
+
+func (o *LatencyObserver) record(operationName string, d time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	acc, ok := o.stats[operationName]
+	if !ok {
+		acc = &latencyAccumulator{min: d, max: d}
+		o.stats[operationName] = acc
+	}
+	acc.count++
+	acc.total += d
+	if d < acc.min {
+		acc.min = d
+	}
+	if d > acc.max {
+		acc.max = d
+	}
+}
+
+type latencySpanObserver struct {
+	observer *LatencyObserver
+	start    time.Time
+
+	mu            sync.Mutex
+	operationName string
+}
+
+func (s *latencySpanObserver) OnSetOperationName(operationName string) {
+	s.mu.Lock()
+	s.operationName = operationName
+	s.mu.Unlock()
+}
+
+func (s *latencySpanObserver) OnSetTag(key string, value interface{}) {}
+
+func (s *latencySpanObserver) OnFinish(opts FinishOptions) {
+	finish := opts.FinishTime
+	if finish.IsZero() {
+		finish = time.Now()
+	}
+	s.mu.Lock()
+	operationName := s.operationName
+	s.mu.Unlock()
+	s.observer.record(operationName, finish.Sub(s.start))
+}
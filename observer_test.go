@@ -0,0 +1,112 @@
+package opentracing
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatencyObserver(t *testing.T) {
+	o := NewLatencyObserver()
+	start := time.Now()
+
+	finish := func(name string, d time.Duration) SpanObserver {
+		so, ok := o.OnStartSpan(defaultNoopSpan, name, StartSpanOptions{StartTime: start})
+		require.True(t, ok)
+		so.OnFinish(FinishOptions{FinishTime: start.Add(d)})
+		return so
+	}
+	finish("a", 10*time.Millisecond)
+	finish("a", 30*time.Millisecond)
+	finish("a", 20*time.Millisecond)
+	finish("b", time.Second)
+
+	// 以结束时的操作名为准
+	so, _ := o.OnStartSpan(defaultNoopSpan, "pending", StartSpanOptions{StartTime: start})
+	so.OnSetTag("k", "v")
+	so.OnSetOperationName("b")
+	so.OnFinish(FinishOptions{FinishTime: start.Add(3 * time.Second)})
+
+	assert.Equal(t, map[string]LatencyStats{
+		"a": {Count: 3, Min: 10 * time.Millisecond, Max: 30 * time.Millisecond, Mean: 20 * time.Millisecond},
+		"b": {Count: 2, Min: time.Second, Max: 3 * time.Second, Mean: 2 * time.Second},
+	}, o.Snapshot())
+}
+
+func TestLatencyObserverConcurrent(t *testing.T) {
+	o := NewLatencyObserver()
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				so, _ := o.OnStartSpan(defaultNoopSpan, "op", StartSpanOptions{})
+				so.OnFinish(FinishOptions{})
+				o.Snapshot()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(1000), o.Snapshot()["op"].Count)
+}
+
+// recordingObserver 记录它收到的所有事件
+type recordingObserver struct {
+	accept bool
+	events []string
+}
+
+func (o *recordingObserver) OnStartSpan(sp Span, operationName string, opts StartSpanOptions) (SpanObserver, bool) {
+	o.events = append(o.events, "start:"+operationName)
+	return o, o.accept
+}
+
+func (o *recordingObserver) OnSetOperationName(operationName string) {
+	o.events = append(o.events, "name:"+operationName)
+}
+
+func (o *recordingObserver) OnSetTag(key string, value interface{}) {
+	o.events = append(o.events, "tag:"+key)
+}
+
+func (o *recordingObserver) OnFinish(opts FinishOptions) {
+	o.events = append(o.events, "finish")
+}
+
+func TestWithObservers(t *testing.T) {
+	assert.Equal(t, Tracer(testTracer{}), WithObservers(testTracer{}))
+
+	accepting := &recordingObserver{accept: true}
+	declining := &recordingObserver{}
+	latency := NewLatencyObserver()
+	tracer := WithObservers(testTracer{}, accepting, declining, latency)
+
+	span := tracer.StartSpan("op")
+	assert.Equal(t, span, span.SetTag("k", "v").SetOperationName("renamed").SetBaggageItem("b", "1"))
+	child := span.Tracer().StartSpan("child", ChildOf(span.Context()))
+	child.FinishWithOptions(FinishOptions{})
+	span.Finish()
+
+	assert.Equal(t, []string{"start:op", "tag:k", "name:renamed", "start:child", "finish", "finish"}, accepting.events)
+	assert.Equal(t, []string{"start:op", "start:child"}, declining.events)
+	stats := latency.Snapshot()
+	assert.Equal(t, int64(1), stats["renamed"].Count)
+	assert.Equal(t, int64(1), stats["child"].Count)
+}
+
+func TestWithObserversFinishOnce(t *testing.T) {
+	o := NewLatencyObserver()
+	tracer := WithObservers(NoopTracer{}, o)
+
+	span := tracer.StartSpan("op")
+	span.Finish()
+	span.Finish()
+	span.FinishWithOptions(FinishOptions{})
+
+	require.Contains(t, o.Snapshot(), "op")
+	assert.Equal(t, int64(1), o.Snapshot()["op"].Count)
+}
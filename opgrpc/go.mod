@@ -0,0 +1,23 @@
+module github.com/opentracing/opentracing-go/opgrpc
+
+go 1.17
+
+replace github.com/opentracing/opentracing-go => ../
+
+require (
+	github.com/opentracing/opentracing-go v1.2.0
+	github.com/stretchr/testify v1.7.0
+	google.golang.org/grpc v1.50.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/golang/protobuf v1.5.2 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/net v0.0.0-20201021035429-f5854403a974 // indirect
+	golang.org/x/sys v0.0.0-20210119212857-b64e53b001e4 // indirect
+	golang.org/x/text v0.3.3 // indirect
+	google.golang.org/genproto v0.0.0-20200526211855-cb27e3aa2013 // indirect
+	google.golang.org/protobuf v1.27.1 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
+)
@@ -0,0 +1,108 @@
+// Package opgrpc provides unary gRPC interceptors that propagate OpenTracing
+// spans through gRPC metadata.
+//
+// The package is a separate module so that the core opentracing-go package
+// does not depend on google.golang.org/grpc.
+package opgrpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+)
+
+// StatusCodeTagKey is the tag set on every span to the string form of the
+// gRPC status code of the call, e.g. "OK" or "NotFound".
+const StatusCodeTagKey = "grpc.status_code"
+
+const componentName = "gRPC"
+
+// MetadataCarrier satisfies both opentracing.TextMapWriter and
+// opentracing.TextMapReader, using gRPC metadata for storage. gRPC
+// lowercases all metadata keys.
+type MetadataCarrier metadata.MD
+
+// Set conforms to the opentracing.TextMapWriter interface.
+func (c MetadataCarrier) Set(key, val string) {
+	metadata.MD(c).Set(key, val)
+}
+
+// ForeachKey conforms to the opentracing.TextMapReader interface.
+func (c MetadataCarrier) ForeachKey(handler func(key, val string) error) error {
+	for k, vals := range c {
+		for _, v := range vals {
+			if err := handler(k, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that extracts
+// the caller's span context from the incoming metadata, starts a server span
+// named after the full method, makes it available to the handler via
+// opentracing.SpanFromContext and finishes it once the handler returns.
+//
+// A missing or corrupted upstream span context results in a root span.
+func UnaryServerInterceptor(tracer opentracing.Tracer) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		// On error parent is nil, which RPCServerOption ignores.
+		parent, _ := tracer.Extract(opentracing.HTTPHeaders, MetadataCarrier(md))
+		span := tracer.StartSpan(info.FullMethod,
+			ext.RPCServerOption(parent),
+			opentracing.Tag{Key: string(ext.Component), Value: componentName})
+		defer span.Finish()
+
+		resp, err := handler(opentracing.ContextWithSpan(ctx, span), req)
+		setStatus(span, err)
+		return resp, err
+	}
+}
+
+// UnaryClientInterceptor returns a grpc.UnaryClientInterceptor that starts a
+// client span named after the full method, as a child of the span in ctx if
+// there is one, and injects it into the outgoing metadata.
+func UnaryClientInterceptor(tracer opentracing.Tracer) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		var parent opentracing.SpanContext
+		if sp := opentracing.SpanFromContext(ctx); sp != nil {
+			parent = sp.Context()
+		}
+		span := tracer.StartSpan(method,
+			opentracing.ChildOf(parent),
+			ext.SpanKindRPCClient,
+			opentracing.Tag{Key: string(ext.Component), Value: componentName})
+		defer span.Finish()
+
+		md, ok := metadata.FromOutgoingContext(ctx)
+		if ok {
+			md = md.Copy()
+		} else {
+			md = metadata.MD{}
+		}
+		if err := tracer.Inject(span.Context(), opentracing.HTTPHeaders, MetadataCarrier(md)); err != nil {
+			span.LogFields(log.Event("inject.error"), log.Error(err))
+		}
+
+		err := invoker(metadata.NewOutgoingContext(ctx, md), method, req, reply, cc, opts...)
+		setStatus(span, err)
+		return err
+	}
+}
+
+// setStatus records the gRPC status code of err on span, and marks the span
+// as failed for any code other than OK.
+func setStatus(span opentracing.Span, err error) {
+	span.SetTag(StatusCodeTagKey, status.Code(err).String())
+	if err != nil {
+		ext.LogError(span, err)
+	}
+}
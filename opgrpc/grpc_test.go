@@ -0,0 +1,92 @@
+package opgrpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+var testInfo = &grpc.UnaryServerInfo{FullMethod: "/pkg.Service/Method"}
+
+func TestUnaryServerInterceptorWithoutUpstream(t *testing.T) {
+	tracer := mocktracer.New()
+	interceptor := UnaryServerInterceptor(tracer)
+
+	var handlerSpan opentracing.Span
+	resp, err := interceptor(context.Background(), "req", testInfo, func(ctx context.Context, req interface{}) (interface{}, error) {
+		handlerSpan = opentracing.SpanFromContext(ctx)
+		return "resp", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "resp", resp)
+
+	spans := tracer.FinishedSpans()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, handlerSpan, span)
+	assert.Equal(t, "/pkg.Service/Method", span.OperationName)
+	assert.Equal(t, 0, span.ParentID)
+	assert.Equal(t, ext.SpanKindRPCServerEnum, span.Tag(string(ext.SpanKind)))
+	assert.Equal(t, "gRPC", span.Tag(string(ext.Component)))
+	assert.Equal(t, "OK", span.Tag(StatusCodeTagKey))
+	assert.Nil(t, span.Tag(string(ext.Error)))
+}
+
+func TestUnaryServerInterceptorWithUpstream(t *testing.T) {
+	tracer := mocktracer.New()
+	upstream := tracer.StartSpan("client").(*mocktracer.MockSpan)
+	md := metadata.MD{}
+	require.NoError(t, tracer.Inject(upstream.Context(), opentracing.HTTPHeaders, MetadataCarrier(md)))
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	_, err := UnaryServerInterceptor(tracer)(ctx, "req", testInfo, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "missing")
+	})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	spans := tracer.FinishedSpans()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, upstream.SpanContext.TraceID, span.SpanContext.TraceID)
+	assert.Equal(t, upstream.SpanContext.SpanID, span.ParentID)
+	assert.Equal(t, "NotFound", span.Tag(StatusCodeTagKey))
+	assert.Equal(t, true, span.Tag(string(ext.Error)))
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	tracer := mocktracer.New()
+	parent := tracer.StartSpan("parent")
+	ctx := opentracing.ContextWithSpan(context.Background(), parent)
+	ctx = metadata.AppendToOutgoingContext(ctx, "x-existing", "1")
+
+	var outgoing metadata.MD
+	err := UnaryClientInterceptor(tracer)(ctx, "/pkg.Service/Method", "req", nil, nil,
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			outgoing, _ = metadata.FromOutgoingContext(ctx)
+			return nil
+		})
+	require.NoError(t, err)
+
+	spans := tracer.FinishedSpans()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "/pkg.Service/Method", span.OperationName)
+	assert.Equal(t, parent.(*mocktracer.MockSpan).SpanContext.SpanID, span.ParentID)
+	assert.Equal(t, ext.SpanKindRPCClientEnum, span.Tag(string(ext.SpanKind)))
+	assert.Equal(t, "OK", span.Tag(StatusCodeTagKey))
+
+	assert.Equal(t, []string{"1"}, outgoing.Get("x-existing"))
+	sc, err := tracer.Extract(opentracing.HTTPHeaders, MetadataCarrier(outgoing))
+	require.NoError(t, err)
+	assert.Equal(t, span.SpanContext.SpanID, sc.(mocktracer.MockSpanContext).SpanID)
+}
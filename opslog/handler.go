@@ -0,0 +1,61 @@
+// Package opslog bridges log/slog and OpenTracing: its Handler adds the IDs
+// of the active span to every log record.
+package opslog
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+// Attribute keys added by Handler.
+const (
+	TraceIDKey = "trace_id"
+	SpanIDKey  = "span_id"
+)
+
+// Handler is a slog.Handler that adds the trace_id and span_id attributes to
+// each record whose context contains a span (see
+// opentracing.SpanFromContext) with a SpanContext implementing
+// opentracing.TraceIdentifiable, and then passes the record to the wrapped
+// handler. Records without such a span are passed through unchanged.
+//
+// Like any other attributes added in Handle, the IDs are qualified by the
+// groups opened with WithGroup.
+type Handler struct {
+	next slog.Handler
+}
+
+// NewHandler returns a Handler wrapping next.
+func NewHandler(next slog.Handler) *Handler {
+	return &Handler{next: next}
+}
+
+// Enabled implements slog.Handler.
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		if span := opentracing.SpanFromContext(ctx); span != nil {
+			if ids, ok := span.Context().(opentracing.TraceIdentifiable); ok {
+				r = r.Clone()
+				r.AddAttrs(slog.String(TraceIDKey, ids.TraceIDString()), slog.String(SpanIDKey, ids.SpanIDString()))
+			}
+		}
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs implements slog.Handler.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{next: h.next.WithAttrs(attrs)}
+}
+
+// WithGroup implements slog.Handler.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name)}
+}
@@ -0,0 +1,59 @@
+package opslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+type idSpanContext struct{}
+
+func (c idSpanContext) ForeachBaggageItem(handler func(k, v string) bool) {}
+func (c idSpanContext) TraceIDString() string                             { return "trace-1" }
+func (c idSpanContext) SpanIDString() string                              { return "span-2" }
+
+func logLine(ctx context.Context, t *testing.T, logger func(*slog.Logger) *slog.Logger) map[string]interface{} {
+	var buf bytes.Buffer
+	l := slog.New(NewHandler(slog.NewJSONHandler(&buf, nil)))
+	if logger != nil {
+		l = logger(l)
+	}
+	l.InfoContext(ctx, "hello", "k", "v")
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	return line
+}
+
+func TestHandler(t *testing.T) {
+	span := opentracing.ContextShimSpan(opentracing.NoopTracer{}, idSpanContext{})
+	ctx := opentracing.ContextWithSpan(context.Background(), span)
+
+	line := logLine(ctx, t, nil)
+	assert.Equal(t, "hello", line["msg"])
+	assert.Equal(t, "v", line["k"])
+	assert.Equal(t, "trace-1", line[TraceIDKey])
+	assert.Equal(t, "span-2", line[SpanIDKey])
+
+	line = logLine(ctx, t, func(l *slog.Logger) *slog.Logger { return l.With("service", "api") })
+	assert.Equal(t, "api", line["service"])
+	assert.Equal(t, "trace-1", line[TraceIDKey])
+}
+
+func TestHandlerWithoutSpan(t *testing.T) {
+	line := logLine(context.Background(), t, nil)
+	assert.Equal(t, "v", line["k"])
+	assert.NotContains(t, line, TraceIDKey)
+	assert.NotContains(t, line, SpanIDKey)
+
+	// the span context does not expose its IDs
+	ctx := opentracing.ContextWithSpan(context.Background(), opentracing.NoopTracer{}.StartSpan("x"))
+	line = logLine(ctx, t, nil)
+	assert.NotContains(t, line, TraceIDKey)
+}
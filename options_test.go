@@ -1,8 +1,12 @@
 package opentracing
 
 import (
+	"errors"
+	"fmt"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -29,3 +33,369 @@ func TestChildOfAndFollowsFrom(t *testing.T) {
 		}, opts.References, "%s(ctx) must append a reference", test.name)
 	}
 }
+
+func TestTypedTags(t *testing.T) {
+	sso := NewStartSpanOptions(
+		StringTag{"component", "db"},
+		IntTag{"retry", 3},
+		BoolTag{"cache.hit", true},
+		Float64Tag{"ratio", 0.5},
+	)
+	assert.Equal(t, map[string]interface{}{
+		"component": "db",
+		"retry":     3,
+		"cache.hit": true,
+		"ratio":     0.5,
+	}, sso.Tags)
+
+	span := &tagRecordingSpan{tags: map[string]interface{}{}}
+	StringTag{"a", "x"}.Set(span)
+	IntTag{"b", 1}.Set(span)
+	BoolTag{"c", false}.Set(span)
+	Float64Tag{"d", 1.5}.Set(span)
+	assert.Equal(t, map[string]interface{}{"a": "x", "b": 1, "c": false, "d": 1.5}, span.tags)
+}
+
+func TestReferences(t *testing.T) {
+	a, b := testSpanContext{FakeID: 1}, testSpanContext{FakeID: 2}
+	sso := NewStartSpanOptions(References(ChildOf(a), FollowsFrom(nil), FollowsFrom(b)))
+	assert.Equal(t, []SpanReference{
+		{Type: ChildOfRef, ReferencedContext: a},
+		{Type: FollowsFromRef, ReferencedContext: b},
+	}, sso.References)
+
+	assert.Nil(t, NewStartSpanOptions(References()).References)
+}
+
+func TestReferencesWithTags(t *testing.T) {
+	sc := testSpanContext{FakeID: 1}
+	tags := Tags{"message.id": "m1"}
+	child := ChildOfWithTags(sc, tags)
+	follows := FollowsFromWithTags(sc, nil)
+	tags["message.id"] = "m2"
+
+	sso := NewStartSpanOptions(child, follows)
+	assert.Equal(t, []SpanReference{
+		{Type: ChildOfRef, ReferencedContext: sc, Attributes: &ReferenceAttributes{Tags: map[string]interface{}{"message.id": "m1"}}},
+		{Type: FollowsFromRef, ReferencedContext: sc},
+	}, sso.References)
+
+	assert.Nil(t, NewStartSpanOptions(ChildOfWithTags(nil, tags)).References)
+}
+
+func TestSpanReferenceComparable(t *testing.T) {
+	sc := testSpanContext{FakeID: 1}
+	child := ChildOfWithTags(sc, Tags{"message.id": "m1"})
+	assert.True(t, child == child)
+	assert.True(t, FollowsFromWithTags(sc, nil) == FollowsFrom(sc))
+	assert.False(t, child == ChildOf(sc))
+
+	seen := map[SpanReference]bool{child: true}
+	assert.True(t, seen[child])
+	assert.False(t, seen[ChildOf(sc)])
+}
+
+func TestStartSpanOptionsCloneTags(t *testing.T) {
+	require.Nil(t, StartSpanOptions{}.CloneTags(), "nil Tags must be cloned as nil")
+
+	original := map[string]interface{}{"component": "test"}
+	opts := StartSpanOptions{Tags: original}
+	cloned := opts.CloneTags()
+	require.Equal(t, original, cloned)
+
+	original["component"] = "changed"
+	original["extra"] = true
+	require.Equal(t, map[string]interface{}{"component": "test"}, cloned,
+		"modifying the original map must not affect the clone")
+
+	cloned["another"] = 1
+	require.NotContains(t, original, "another", "modifying the clone must not affect the original map")
+}
+
+func TestChildOfAllAndFollowsFromAll(t *testing.T) {
+	tests := []struct {
+		newOpt  func(...SpanContext) StartSpanOption
+		refType SpanReferenceType
+		name    string
+	}{
+		{ChildOfAll, ChildOfRef, "ChildOfAll"},
+		{FollowsFromAll, FollowsFromRef, "FollowsFromAll"},
+	}
+
+	for _, test := range tests {
+		opts := new(StartSpanOptions)
+		test.newOpt().Apply(opts)
+		require.Nil(t, opts.References, "%s() must not append a reference", test.name)
+
+		ctx1, ctx2 := testSpanContext{FakeID: 1}, testSpanContext{FakeID: 2}
+		test.newOpt(ctx1, nil, ctx2, nil).Apply(opts)
+		require.Equal(t, []SpanReference{
+			{ReferencedContext: ctx1, Type: test.refType},
+			{ReferencedContext: ctx2, Type: test.refType},
+		}, opts.References, "%s must skip nil contexts", test.name)
+	}
+}
+
+func TestStartSpanOptionsReferences(t *testing.T) {
+	ctx1, ctx2, ctx3 := testSpanContext{FakeID: 1}, testSpanContext{FakeID: 2}, testSpanContext{FakeID: 3}
+	tests := []struct {
+		name        string
+		refs        []SpanReference
+		root        bool
+		childOf     []SpanContext
+		followsFrom []SpanContext
+		duplicates  bool
+	}{
+		{name: "no references", root: true},
+		{
+			name: "only nil references",
+			refs: []SpanReference{{Type: ChildOfRef}, {Type: FollowsFromRef}},
+			root: true,
+		},
+		{
+			name:    "single parent",
+			refs:    []SpanReference{{Type: ChildOfRef, ReferencedContext: ctx1}},
+			childOf: []SpanContext{ctx1},
+		},
+		{
+			name: "mixed with nils",
+			refs: []SpanReference{
+				{Type: FollowsFromRef, ReferencedContext: ctx1},
+				{Type: ChildOfRef},
+				{Type: ChildOfRef, ReferencedContext: ctx2},
+				{Type: FollowsFromRef, ReferencedContext: ctx3},
+			},
+			childOf:     []SpanContext{ctx2},
+			followsFrom: []SpanContext{ctx1, ctx3},
+		},
+		{
+			name: "duplicate context",
+			refs: []SpanReference{
+				{Type: ChildOfRef, ReferencedContext: ctx1},
+				{Type: FollowsFromRef, ReferencedContext: ctx1},
+			},
+			childOf:     []SpanContext{ctx1},
+			followsFrom: []SpanContext{ctx1},
+			duplicates:  true,
+		},
+		{
+			name: "duplicate non-comparable context",
+			refs: []SpanReference{
+				{Type: FollowsFromRef, ReferencedContext: baggageSpanContext{"k": "v"}},
+				{Type: FollowsFromRef, ReferencedContext: baggageSpanContext{"k": "v"}},
+			},
+			followsFrom: []SpanContext{baggageSpanContext{"k": "v"}, baggageSpanContext{"k": "v"}},
+			duplicates:  true,
+		},
+	}
+
+	for _, test := range tests {
+		opts := StartSpanOptions{References: test.refs}
+		require.Equal(t, test.root, opts.IsRoot(), test.name)
+		require.Equal(t, test.childOf, opts.ChildOfReferences(), test.name)
+		require.Equal(t, test.followsFrom, opts.FollowsFromReferences(), test.name)
+		require.Equal(t, test.followsFrom, opts.ReferencesOfType(FollowsFromRef), test.name)
+		if test.duplicates {
+			require.Error(t, opts.ValidateReferences(), test.name)
+		} else {
+			require.NoError(t, opts.ValidateReferences(), test.name)
+		}
+	}
+}
+
+func TestSpanReferenceString(t *testing.T) {
+	assert.Equal(t, "child_of", ChildOfRef.String())
+	assert.Equal(t, "follows_from", FollowsFromRef.String())
+	assert.Equal(t, "unknown(7)", SpanReferenceType(7).String())
+
+	ref := SpanReference{Type: FollowsFromRef, ReferencedContext: baggageSpanContext{"user_id": "1", "tenant": "a"}}
+	assert.Equal(t, "follows_from{tenant=a, user_id=1}", ref.String())
+	assert.Equal(t, "follows_from{tenant=a, user_id=1}", fmt.Sprint(ref))
+	assert.Equal(t, "child_of{}", SpanReference{ReferencedContext: noopSpanContext{}}.String())
+	assert.Equal(t, "child_of(nil)", SpanReference{}.String())
+}
+
+func TestStartSpanOptionsString(t *testing.T) {
+	sso := StartSpanOptions{}
+	assert.Equal(t, "StartSpanOptions{refs=0[], start=<now>, tags={}}", sso.String())
+
+	ChildOf(noopSpanContext{}).Apply(&sso)
+	FollowsFrom(baggageSpanContext{"k": "v"}).Apply(&sso)
+	Tags{"retry": 3, "component": "db", "error": false}.Apply(&sso)
+	assert.Equal(t,
+		"StartSpanOptions{refs=2[child_of, follows_from], start=<now>, tags={component=db, error=false, retry=3}}",
+		sso.String())
+
+	StartTime(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)).Apply(&sso)
+	assert.Contains(t, sso.String(), "start=2020-01-02T03:04:05Z")
+}
+
+func TestWithBaggage(t *testing.T) {
+	sso := StartSpanOptions{}
+	WithBaggage(map[string]string{"a": "1", "b": "2"}).Apply(&sso)
+	WithBaggage(map[string]string{"b": "3"}).Apply(&sso)
+	WithBaggage(nil).Apply(&sso)
+	assert.Equal(t, map[string]string{"a": "1", "b": "3"}, sso.Baggage)
+
+	forwarded := StartSpanOptions{}
+	appliedStartSpanOptions(sso).Apply(&forwarded)
+	assert.Equal(t, sso.Baggage, forwarded.Baggage)
+}
+
+// errorableTestTracer 在操作名为空时返回错误
+type errorableTestTracer struct {
+	testTracer
+}
+
+var errEmptyOperationName = errors.New("empty operation name")
+
+func (e errorableTestTracer) StartSpanE(operationName string, opts ...StartSpanOption) (Span, error) {
+	if operationName == "" {
+		return nil, errEmptyOperationName
+	}
+	return e.StartSpan(operationName, opts...), nil
+}
+
+func TestStartSpanE(t *testing.T) {
+	span, err := StartSpanE(testTracer{}, "", Tag{Key: "k", Value: "v"})
+	assert.NoError(t, err)
+	assert.Equal(t, "v", span.(testSpan).Tags["k"])
+
+	span, err = StartSpanE(errorableTestTracer{}, "op")
+	assert.NoError(t, err)
+	assert.Equal(t, "op", span.(testSpan).OperationName)
+
+	span, err = StartSpanE(errorableTestTracer{}, "")
+	assert.Equal(t, errEmptyOperationName, err)
+	assert.Nil(t, span)
+}
+
+var startSpanOptionsSink StartSpanOptions
+
+func BenchmarkStartSpanOptionsApply(b *testing.B) {
+	parent := noopSpanContext{}
+	opts := []StartSpanOption{
+		ChildOf(parent),
+		Tag{Key: "component", Value: "db"},
+		Tag{Key: "span.kind", Value: "client"},
+	}
+	b.Run("loop", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sso := StartSpanOptions{}
+			for _, o := range opts {
+				o.Apply(&sso)
+			}
+			startSpanOptionsSink = sso
+		}
+	})
+	b.Run("NewStartSpanOptions", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			startSpanOptionsSink = NewStartSpanOptions(opts...)
+		}
+	})
+}
+
+func TestNewStartSpanOptions(t *testing.T) {
+	parent := noopSpanContext{}
+	start := time.Now()
+	opts := []StartSpanOption{
+		ChildOf(parent),
+		ChildOf(nil),
+		FollowsFrom(parent),
+		Tag{Key: "a", Value: 1},
+		Tags{"b": 2, "a": 3},
+		StartTime(start),
+	}
+	want := StartSpanOptions{}
+	for _, o := range opts {
+		o.Apply(&want)
+	}
+	got := NewStartSpanOptions(opts...)
+	assert.Equal(t, want, got)
+	assert.Equal(t, 2, cap(got.References))
+
+	assert.Equal(t, StartSpanOptions{}, NewStartSpanOptions())
+}
+
+func TestNewStartSpanOptionsAllocs(t *testing.T) {
+	opts := []StartSpanOption{
+		ChildOf(noopSpanContext{}),
+		Tag{Key: "component", Value: "db"},
+		Tag{Key: "span.kind", Value: "client"},
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		startSpanOptionsSink = NewStartSpanOptions(opts...)
+	})
+	assert.Equal(t, 1.0, allocs, "only the References array should be allocated")
+}
+
+func TestStartSpanOptionsTagsMap(t *testing.T) {
+	sso := NewStartSpanOptions(Tag{Key: "a", Value: 1}, Tag{Key: "a", Value: 2})
+	assert.Nil(t, sso.Tags)
+	assert.Equal(t, map[string]interface{}{"a": 2}, sso.CloneTags())
+
+	sso = NewStartSpanOptions(SamplingPriority(1), Tag{Key: "a", Value: 2})
+	assert.Nil(t, sso.Tags)
+	want := map[string]interface{}{"a": 2, SamplingPriorityTagKey: uint16(1)}
+	assert.Equal(t, want, sso.CloneTags())
+	assert.Equal(t, "StartSpanOptions{refs=0[], start=<now>, tags={a=2, sampling.priority=1}}", sso.String())
+	priority, ok := sso.SamplingPriority()
+	assert.True(t, ok)
+	assert.Equal(t, uint16(1), priority)
+
+	// 之后通过 Apply 写入的 tag 覆盖暂存的 tag
+	Tag{Key: "a", Value: 3}.Apply(&sso)
+	want["a"] = 3
+	assert.Equal(t, want, sso.TagsMap())
+	assert.Equal(t, want, sso.Tags)
+	assert.Equal(t, 0, sso.numInline)
+
+	// 超过 maxInlineTags 个 tag 时直接写入 Tags
+	sso = NewStartSpanOptions(Tag{Key: "a", Value: 1}, Tag{Key: "b", Value: 2}, Tag{Key: "c", Value: 3})
+	assert.Equal(t, map[string]interface{}{"a": 1, "b": 2, "c": 3}, sso.Tags)
+
+	sso = NewStartSpanOptions()
+	assert.Nil(t, sso.TagsMap())
+	assert.Nil(t, sso.CloneTags())
+}
+
+func TestAppliedStartSpanOptionsInlineTags(t *testing.T) {
+	sso := NewStartSpanOptions(Tag{Key: "a", Value: 1}, Tag{Key: "b", Value: 2})
+	sso.Tags = map[string]interface{}{"b": 3}
+	got := StartSpanOptions{}
+	appliedStartSpanOptions(sso).Apply(&got)
+	assert.Equal(t, map[string]interface{}{"a": 1, "b": 3}, got.Tags)
+}
+
+func TestTypedTagsInline(t *testing.T) {
+	sso := NewStartSpanOptions(IntTag{"retry", -3}, Float64Tag{"ratio", 0.5})
+	assert.Nil(t, sso.Tags)
+	assert.Equal(t, map[string]interface{}{"retry": -3, "ratio": 0.5}, sso.TagsMap())
+
+	sso = NewStartSpanOptions(StringTag{"component", "db"}, BoolTag{"cache.hit", true})
+	assert.Equal(t, map[string]interface{}{"component": "db", "cache.hit": true}, sso.CloneTags())
+
+	retry := 1000
+	allocs := testing.AllocsPerRun(100, func() {
+		retry++
+		startSpanOptionsSink = NewStartSpanOptions(IntTag{"retry", retry})
+	})
+	assert.Equal(t, 1.0, allocs, "only the IntTag itself should be allocated")
+}
+
+func BenchmarkTypedTag(b *testing.B) {
+	b.Run("Tag", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			startSpanOptionsSink = NewStartSpanOptions(Tag{Key: "retry", Value: i + 1000})
+		}
+	})
+	b.Run("IntTag", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			startSpanOptionsSink = NewStartSpanOptions(IntTag{"retry", i + 1000})
+		}
+	})
+}
@@ -0,0 +1,97 @@
+// Package othttp provides net/http instrumentation: a server middleware that
+// continues the caller's trace and a RoundTripper that propagates the
+// current span to outgoing requests.
+package othttp
+
+import (
+	"net/http"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const componentName = "net/http"
+
+// MiddlewareOption configures Middleware.
+type MiddlewareOption func(*middlewareOptions)
+
+type middlewareOptions struct {
+	operationName func(r *http.Request) string
+}
+
+// OperationNameFunc sets the function computing the operation name of the
+// server span from the request. The default is "HTTP <method>".
+func OperationNameFunc(fn func(r *http.Request) string) MiddlewareOption {
+	return func(o *middlewareOptions) {
+		o.operationName = fn
+	}
+}
+
+func defaultOperationName(r *http.Request) string {
+	return "HTTP " + r.Method
+}
+
+// Middleware returns an http.Handler that extracts the caller's span context
+// from the request headers, starts a server span (span.kind=server) for every
+// request, makes it available to next via opentracing.SpanFromContext on the
+// request context, and finishes it once next returns.
+//
+// The span is tagged with http.method, http.url and http.status_code; status
+// codes of 500 and above also set error=true. A missing or corrupted upstream
+// span context results in a root span.
+func Middleware(tracer opentracing.Tracer, next http.Handler, opts ...MiddlewareOption) http.Handler {
+	options := middlewareOptions{operationName: defaultOperationName}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// On error parent is nil, which RPCServerOption ignores.
+		parent, _ := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(r.Header))
+		span := tracer.StartSpan(options.operationName(r), ext.RPCServerOption(parent))
+		defer span.Finish()
+		ext.Component.Set(span, componentName)
+		ext.HTTPMethod.Set(span, r.Method)
+		ext.HTTPUrl.Set(span, r.URL.String())
+
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r.WithContext(opentracing.ContextWithSpan(r.Context(), span)))
+		setStatusCode(span, sw.status)
+	})
+}
+
+// setStatusCode sets the http.status_code tag on span, and error=true for
+// server errors.
+func setStatusCode(span opentracing.Span, status int) {
+	ext.HTTPStatusCode.Set(span, uint16(status))
+	if status >= http.StatusInternalServerError {
+		ext.Error.Set(span, true)
+	}
+}
+
+// statusWriter records the status code written through it.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher if the underlying ResponseWriter does.
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.wroteHeader = true
+		f.Flush()
+	}
+}
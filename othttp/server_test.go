@@ -0,0 +1,61 @@
+package othttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+func TestMiddlewareWithoutUpstream(t *testing.T) {
+	tracer := mocktracer.New()
+	var handlerSpan opentracing.Span
+	handler := Middleware(tracer, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlerSpan = opentracing.SpanFromContext(r.Context())
+		w.Write([]byte("ok"))
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/users/1", nil))
+	assert.Equal(t, "ok", rec.Body.String())
+
+	spans := tracer.FinishedSpans()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, handlerSpan, span)
+	assert.Equal(t, "HTTP GET", span.OperationName)
+	assert.Equal(t, 0, span.ParentID)
+	assert.Equal(t, ext.SpanKindRPCServerEnum, span.Tag(string(ext.SpanKind)))
+	assert.Equal(t, "GET", span.Tag(string(ext.HTTPMethod)))
+	assert.Equal(t, "/users/1", span.Tag(string(ext.HTTPUrl)))
+	assert.Equal(t, uint16(200), span.Tag(string(ext.HTTPStatusCode)))
+	assert.Nil(t, span.Tag(string(ext.Error)))
+}
+
+func TestMiddlewareWithUpstream(t *testing.T) {
+	tracer := mocktracer.New()
+	upstream := tracer.StartSpan("client").(*mocktracer.MockSpan)
+	handler := Middleware(tracer, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.WriteHeader(http.StatusOK) // superfluous, must be ignored
+	}), OperationNameFunc(func(r *http.Request) string { return r.URL.Path }))
+
+	req := httptest.NewRequest("POST", "/orders", nil)
+	require.NoError(t, tracer.Inject(upstream.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(req.Header)))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := tracer.FinishedSpans()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "/orders", span.OperationName)
+	assert.Equal(t, upstream.SpanContext.TraceID, span.SpanContext.TraceID)
+	assert.Equal(t, upstream.SpanContext.SpanID, span.ParentID)
+	assert.Equal(t, uint16(503), span.Tag(string(ext.HTTPStatusCode)))
+	assert.Equal(t, true, span.Tag(string(ext.Error)))
+}
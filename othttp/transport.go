@@ -0,0 +1,89 @@
+package othttp
+
+import (
+	"net/http"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+// TransportOption configures NewTransport.
+type TransportOption func(*Transport)
+
+// TransportTracer sets the tracer used by the Transport. The default is the
+// global tracer at the time of each request, see opentracing.GlobalTracer.
+func TransportTracer(tracer opentracing.Tracer) TransportOption {
+	return func(t *Transport) {
+		t.tracer = tracer
+	}
+}
+
+// TransportOperationName sets the function computing the operation name of
+// the client span from the request. The default is "HTTP <method>".
+func TransportOperationName(fn func(r *http.Request) string) TransportOption {
+	return func(t *Transport) {
+		t.operationName = fn
+	}
+}
+
+// Transport is an http.RoundTripper that starts a client span
+// (span.kind=client) for every request, as a child of the span in the
+// request's context if there is one, and injects it into the request headers:
+//
+//     client := &http.Client{Transport: othttp.NewTransport(nil)}
+//     req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+//     resp, err := client.Do(req)
+//
+// The span is tagged with http.method, http.url and http.status_code, and
+// finished when RoundTrip returns. Status codes of 500 and above and
+// transport errors set error=true.
+type Transport struct {
+	base          http.RoundTripper
+	tracer        opentracing.Tracer
+	operationName func(r *http.Request) string
+}
+
+// NewTransport returns a Transport wrapping base. If base is nil,
+// http.DefaultTransport is used.
+func NewTransport(base http.RoundTripper, opts ...TransportOption) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	t := &Transport{base: base, operationName: defaultOperationName}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// RoundTrip implements http.RoundTripper. The request passed to the base
+// RoundTripper is a clone of req carrying the injected headers; req itself is
+// not modified.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	tracer := t.tracer
+	if tracer == nil {
+		tracer = opentracing.GlobalTracer()
+	}
+	var parent opentracing.SpanContext
+	if sp := opentracing.SpanFromContext(req.Context()); sp != nil {
+		parent = sp.Context()
+	}
+	span := tracer.StartSpan(t.operationName(req), opentracing.ChildOf(parent), ext.SpanKindRPCClient)
+	defer span.Finish()
+	ext.Component.Set(span, componentName)
+	ext.HTTPMethod.Set(span, req.Method)
+	ext.HTTPUrl.Set(span, req.URL.String())
+
+	req = req.Clone(req.Context())
+	if err := tracer.Inject(span.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(req.Header)); err != nil {
+		span.LogKV("event", "inject.error", "error.object", err)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		ext.LogError(span, err)
+		return nil, err
+	}
+	setStatusCode(span, resp.StatusCode)
+	return resp, nil
+}
@@ -0,0 +1,71 @@
+package othttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestTransport(t *testing.T) {
+	tracer := mocktracer.New()
+	server := httptest.NewServer(Middleware(tracer, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})))
+	defer server.Close()
+
+	parent := tracer.StartSpan("parent")
+	ctx := opentracing.ContextWithSpan(context.Background(), parent)
+	req, err := http.NewRequest("GET", server.URL+"/pot", nil)
+	require.NoError(t, err)
+	req = req.WithContext(ctx)
+
+	client := &http.Client{Transport: NewTransport(nil, TransportTracer(tracer))}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Empty(t, req.Header, "the caller's request must not be modified")
+
+	spans := tracer.FinishedSpans()
+	require.Len(t, spans, 2)
+	serverSpan, clientSpan := spans[0], spans[1]
+	assert.Equal(t, "HTTP GET", clientSpan.OperationName)
+	assert.Equal(t, parent.(*mocktracer.MockSpan).SpanContext.SpanID, clientSpan.ParentID)
+	assert.Equal(t, ext.SpanKindRPCClientEnum, clientSpan.Tag(string(ext.SpanKind)))
+	assert.Equal(t, uint16(http.StatusTeapot), clientSpan.Tag(string(ext.HTTPStatusCode)))
+	assert.Equal(t, clientSpan.SpanContext.SpanID, serverSpan.ParentID)
+	assert.Equal(t, clientSpan.SpanContext.TraceID, serverSpan.SpanContext.TraceID)
+}
+
+func TestTransportError(t *testing.T) {
+	tracer := mocktracer.New()
+	errRefused := errors.New("connection refused")
+	transport := NewTransport(roundTripperFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errRefused
+	}), TransportTracer(tracer), TransportOperationName(func(r *http.Request) string { return r.URL.Host }))
+
+	req, err := http.NewRequest("GET", "http://example.com/", nil)
+	require.NoError(t, err)
+	_, err = transport.RoundTrip(req)
+	assert.Equal(t, errRefused, err)
+
+	spans := tracer.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "example.com", spans[0].OperationName)
+	assert.Equal(t, 0, spans[0].ParentID)
+	assert.Equal(t, true, spans[0].Tag(string(ext.Error)))
+}
@@ -1,8 +1,16 @@
 package opentracing
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strings"
 )
 
 ///////////////////////////////////////////////////////////////////////////////
@@ -27,6 +35,34 @@ var (
 	ErrSpanContextCorrupted = errors.New("opentracing: SpanContext data corrupted in Extract carrier")
 )
 
+// Tracer 的实现可以通过 fmt.Errorf("%w", ...) 等方式包装上面的错误以附加上下文信息（例如具体的格式或损坏的键），
+// 因此调用方应该使用下面的帮助函数（或 errors.Is）而不是 `==` 来判断错误的类型。
+
+// IsUnsupportedFormat 判断 err 是否为（或包装了） ErrUnsupportedFormat。
+func IsUnsupportedFormat(err error) bool {
+	return errors.Is(err, ErrUnsupportedFormat)
+}
+
+// IsSpanContextNotFound 判断 err 是否为（或包装了） ErrSpanContextNotFound。
+func IsSpanContextNotFound(err error) bool {
+	return errors.Is(err, ErrSpanContextNotFound)
+}
+
+// IsInvalidSpanContext 判断 err 是否为（或包装了） ErrInvalidSpanContext。
+func IsInvalidSpanContext(err error) bool {
+	return errors.Is(err, ErrInvalidSpanContext)
+}
+
+// IsInvalidCarrier 判断 err 是否为（或包装了） ErrInvalidCarrier。
+func IsInvalidCarrier(err error) bool {
+	return errors.Is(err, ErrInvalidCarrier)
+}
+
+// IsSpanContextCorrupted 判断 err 是否为（或包装了） ErrSpanContextCorrupted。
+func IsSpanContextCorrupted(err error) bool {
+	return errors.Is(err, ErrSpanContextCorrupted)
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 // 内置传播格式(BUILTIN PROPAGATION FORMATS):
 ///////////////////////////////////////////////////////////////////////////////
@@ -75,6 +111,19 @@ const (
 	//        opentracing.HTTPHeaders, carrier)
 	//
 	HTTPHeaders
+
+	// W3CTraceContext 表示 SpanContext 以 W3C Trace Context 规范（https://www.w3.org/TR/trace-context/）
+	// 中的`traceparent`和`tracestate`两个 HTTP header 的形式传播，以便与 OpenTelemetry 等其他系统互通。
+	// 与 HTTPHeaders 不同，它的键名是固定的，不由 Tracer 的实现决定。
+	//
+	// 对于 W3CTraceContext 格式，Inject() 和 Extract() 的`carrier`必须是 W3CTraceContextCarrier，
+	// Tracer 的实现可以使用它的 TraceParent/SetTraceParent 和 TraceState/SetTraceState 方法：
+	//
+	//    carrier := opentracing.W3CTraceContextCarrier(httpReq.Header)
+	//    clientContext, err := tracer.Extract(
+	//        opentracing.W3CTraceContext, carrier)
+	//
+	W3CTraceContext
 )
 
 // TextMapWriter 是 Inject() 需要的载体 TextMap 的内置传播格式。调用者可以用它来编码一个 SpanContext 用于传播。编码类型是unicode字符串组成的map
@@ -98,6 +147,51 @@ type TextMapReader interface {
 	ForeachKey(handler func(key, val string) error) error
 }
 
+// TextMapReaderLookup 是 TextMapReader 可以选择实现的接口，它允许 Extract() 的实现直接查找已知的键，
+// 而不必通过 ForeachKey 遍历所有的键值对（例如带有上百个 header 的 HTTP 请求）。
+// 使用者应该通过 TextMapGet 调用它，以兼容没有实现该接口的载体。
+type TextMapReaderLookup interface {
+	// Get 返回`key`对应的值；如果有多个值，返回第一个。键不存在时第二个返回值为 false。
+	Get(key string) (string, bool)
+}
+
+// TextMapGet 返回`r`中`key`对应的值。如果`r`实现了 TextMapReaderLookup，会直接调用它的 Get，
+// 否则通过 ForeachKey 查找第一个与`key`完全相同的键。
+func TextMapGet(r TextMapReader, key string) (string, bool) {
+	if l, ok := r.(TextMapReaderLookup); ok {
+		return l.Get(key)
+	}
+	var (
+		val   string
+		found bool
+	)
+	_ = r.ForeachKey(func(k, v string) error {
+		if k != key {
+			return nil
+		}
+		val, found = v, true
+		return errTextMapKeyFound
+	})
+	return val, found
+}
+
+// errTextMapKeyFound 用于在 TextMapGet 找到键之后提前终止 ForeachKey。
+var errTextMapKeyFound = errors.New("opentracing: text map key found")
+
+// TextMapKeys 返回`r`中所有的键（不保证顺序），有多个值的键只会出现一次。
+func TextMapKeys(r TextMapReader) []string {
+	var keys []string
+	seen := make(map[string]struct{})
+	_ = r.ForeachKey(func(k, _ string) error {
+		if _, ok := seen[k]; !ok {
+			seen[k] = struct{}{}
+			keys = append(keys, k)
+		}
+		return nil
+	})
+	return keys
+}
+
 // TextMapCarrier 提供了对 TextMapWriter 和 TextMapReader 使用的常规的 map[string]string
 type TextMapCarrier map[string]string
 
@@ -116,6 +210,12 @@ func (c TextMapCarrier) Set(key, val string) {
 	c[key] = val
 }
 
+// Get 实现 TextMapReaderLookup 接口。
+func (c TextMapCarrier) Get(key string) (string, bool) {
+	v, ok := c[key]
+	return v, ok
+}
+
 // HTTPHeadersCarrier 同时满足 TextMapWriter 和 TextMapReader 接口。
 //
 // 服务端用例:
@@ -139,6 +239,12 @@ func (c HTTPHeadersCarrier) Set(key, val string) {
 	h.Set(key, val)
 }
 
+// Add 把 val 追加到 key 已有的值之后，而不是像 Set 那样覆盖它们；key 会按 http.CanonicalHeaderKey 进行规范化。
+func (c HTTPHeadersCarrier) Add(key, val string) {
+	h := http.Header(c)
+	h.Add(key, val)
+}
+
 // ForeachKey 实现 TextMapReader 接口。
 func (c HTTPHeadersCarrier) ForeachKey(handler func(key, val string) error) error {
 	for k, vals := range c {
@@ -150,3 +256,610 @@ func (c HTTPHeadersCarrier) ForeachKey(handler func(key, val string) error) erro
 	}
 	return nil
 }
+
+// Get 实现 TextMapReaderLookup 接口。key 会先按 http.CanonicalHeaderKey 进行规范化，
+// 找不到时再按原样查找，以兼容没有经过规范化就写入的键。
+func (c HTTPHeadersCarrier) Get(key string) (string, bool) {
+	vals, ok := c[http.CanonicalHeaderKey(key)]
+	if !ok {
+		vals, ok = c[key]
+	}
+	if !ok || len(vals) == 0 {
+		return "", false
+	}
+	return vals[0], true
+}
+
+// ValidatingHTTPHeadersCarrier 与 HTTPHeadersCarrier 一样同时满足 TextMapWriter 和 TextMapReader 接口，
+// 但它的 Set 会检查键是否是合法的 HTTP header 名（即 RFC 7230 中定义的 token）。
+//
+// 不合法的键不会被写入 Header，由于 Set 没有返回值，这些错误会被累积起来并通过 Err() 暴露：
+//
+//     carrier := opentracing.NewValidatingHTTPHeadersCarrier(httpReq.Header)
+//     if err := tracer.Inject(span.Context(), opentracing.HTTPHeaders, carrier); err != nil {
+//         ...
+//     }
+//     if err := carrier.Err(); err != nil {
+//         // Tracer 试图写入不合法的 header
+//     }
+//
+type ValidatingHTTPHeadersCarrier struct {
+	Header      http.Header
+	invalidKeys []string
+}
+
+// NewValidatingHTTPHeadersCarrier 返回一个使用 h 进行存储的 ValidatingHTTPHeadersCarrier。
+func NewValidatingHTTPHeadersCarrier(h http.Header) *ValidatingHTTPHeadersCarrier {
+	return &ValidatingHTTPHeadersCarrier{Header: h}
+}
+
+// Set 实现 TextMapWriter 接口。
+func (c *ValidatingHTTPHeadersCarrier) Set(key, val string) {
+	if !isHTTPToken(key) {
+		c.invalidKeys = append(c.invalidKeys, key)
+		return
+	}
+	c.Header.Set(key, val)
+}
+
+// ForeachKey 实现 TextMapReader 接口。
+func (c *ValidatingHTTPHeadersCarrier) ForeachKey(handler func(key, val string) error) error {
+	return HTTPHeadersCarrier(c.Header).ForeachKey(handler)
+}
+
+// Get 实现 TextMapReaderLookup 接口，与 HTTPHeadersCarrier.Get 相同。
+func (c *ValidatingHTTPHeadersCarrier) Get(key string) (string, bool) {
+	return HTTPHeadersCarrier(c.Header).Get(key)
+}
+
+// Err 返回所有 Set 调用中遇到的不合法的键组成的错误，该错误包装了 ErrInvalidCarrier；如果所有的键都合法则返回nil。
+func (c *ValidatingHTTPHeadersCarrier) Err() error {
+	if len(c.invalidKeys) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: invalid HTTP header keys %q", ErrInvalidCarrier, c.invalidKeys)
+}
+
+// CommaSplitHTTPHeadersCarrier 与 HTTPHeadersCarrier 一样同时满足 TextMapWriter 和 TextMapReader 接口，
+// 但对于指定的一组键，ForeachKey 会把每个值按逗号拆分，去掉每段首尾的空白后逐个回调 handler，
+// 用于还原被反向代理合并成单个逗号分隔的值的多个同名 header：
+//
+//     carrier := opentracing.NewCommaSplitHTTPHeadersCarrier(httpReq.Header, "x-custom")
+//     clientContext, err := tracer.Extract(opentracing.HTTPHeaders, carrier)
+//
+// 键的比较不区分大小写（按 http.CanonicalHeaderKey），拆分后为空的段会被跳过，不在列表中的键保持原样。
+type CommaSplitHTTPHeadersCarrier struct {
+	Header    http.Header
+	splitKeys map[string]struct{}
+}
+
+// NewCommaSplitHTTPHeadersCarrier 返回一个使用 h 进行存储的 CommaSplitHTTPHeadersCarrier，
+// 在 ForeachKey 时会拆分 keys 中的键的值。
+func NewCommaSplitHTTPHeadersCarrier(h http.Header, keys ...string) *CommaSplitHTTPHeadersCarrier {
+	splitKeys := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		splitKeys[http.CanonicalHeaderKey(k)] = struct{}{}
+	}
+	return &CommaSplitHTTPHeadersCarrier{Header: h, splitKeys: splitKeys}
+}
+
+// Set 实现 TextMapWriter 接口。
+func (c *CommaSplitHTTPHeadersCarrier) Set(key, val string) {
+	c.Header.Set(key, val)
+}
+
+// ForeachKey 实现 TextMapReader 接口。
+func (c *CommaSplitHTTPHeadersCarrier) ForeachKey(handler func(key, val string) error) error {
+	for k, vals := range c.Header {
+		_, split := c.splitKeys[http.CanonicalHeaderKey(k)]
+		for _, v := range vals {
+			if !split {
+				if err := handler(k, v); err != nil {
+					return err
+				}
+				continue
+			}
+			for _, part := range strings.Split(v, ",") {
+				part = strings.TrimSpace(part)
+				if part == "" {
+					continue
+				}
+				if err := handler(k, part); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Get 实现 TextMapReaderLookup 接口。键的查找与 HTTPHeadersCarrier.Get 相同；
+// 对于需要拆分的键，返回 ForeachKey 会回调的第一段。
+func (c *CommaSplitHTTPHeadersCarrier) Get(key string) (string, bool) {
+	if _, split := c.splitKeys[http.CanonicalHeaderKey(key)]; !split {
+		return HTTPHeadersCarrier(c.Header).Get(key)
+	}
+	vals, ok := c.Header[http.CanonicalHeaderKey(key)]
+	if !ok {
+		vals = c.Header[key]
+	}
+	for _, v := range vals {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				return part, true
+			}
+		}
+	}
+	return "", false
+}
+
+// HTTPHeadersCarrierOption 调整 NewHTTPHeadersCarrier 返回的载体的写入行为。
+type HTTPHeadersCarrierOption func(*ConfigurableHTTPHeadersCarrier)
+
+// PreserveHeaderCase 使载体按原样写入键，不再按 http.CanonicalHeaderKey 进行规范化，
+// 用于对 header 名大小写敏感的代理或后端。注意这样写入的 header 无法再通过 http.Header.Get 读取。
+func PreserveHeaderCase() HTTPHeadersCarrierOption {
+	return func(c *ConfigurableHTTPHeadersCarrier) {
+		c.preserveCase = true
+	}
+}
+
+// AppendHeaderValues 使载体的 Set 与 Add 相同，即追加而不是覆盖已有的值，以保留多值的 header。
+func AppendHeaderValues() HTTPHeadersCarrierOption {
+	return func(c *ConfigurableHTTPHeadersCarrier) {
+		c.appendValues = true
+	}
+}
+
+// ConfigurableHTTPHeadersCarrier 与 HTTPHeadersCarrier 一样同时满足 TextMapWriter 和 TextMapReader 接口，
+// 但可以通过 HTTPHeadersCarrierOption 控制键的大小写以及 Set 是否覆盖已有的值：
+//
+//     carrier := opentracing.NewHTTPHeadersCarrier(httpReq.Header,
+//         opentracing.PreserveHeaderCase(), opentracing.AppendHeaderValues())
+//     err := tracer.Inject(span.Context(), opentracing.HTTPHeaders, carrier)
+//
+// 不带任何选项时，它的行为与 HTTPHeadersCarrier 完全相同。
+type ConfigurableHTTPHeadersCarrier struct {
+	Header       http.Header
+	preserveCase bool
+	appendValues bool
+}
+
+// NewHTTPHeadersCarrier 返回一个使用 h 进行存储、按 opts 配置的 ConfigurableHTTPHeadersCarrier。
+func NewHTTPHeadersCarrier(h http.Header, opts ...HTTPHeadersCarrierOption) *ConfigurableHTTPHeadersCarrier {
+	c := &ConfigurableHTTPHeadersCarrier{Header: h}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// Set 实现 TextMapWriter 接口。如果使用了 AppendHeaderValues，它与 Add 相同。
+func (c *ConfigurableHTTPHeadersCarrier) Set(key, val string) {
+	if c.appendValues {
+		c.Add(key, val)
+		return
+	}
+	if c.preserveCase {
+		c.Header[key] = []string{val}
+		return
+	}
+	c.Header.Set(key, val)
+}
+
+// Add 把 val 追加到 key 已有的值之后。
+func (c *ConfigurableHTTPHeadersCarrier) Add(key, val string) {
+	if c.preserveCase {
+		c.Header[key] = append(c.Header[key], val)
+		return
+	}
+	c.Header.Add(key, val)
+}
+
+// ForeachKey 实现 TextMapReader 接口。
+func (c *ConfigurableHTTPHeadersCarrier) ForeachKey(handler func(key, val string) error) error {
+	return HTTPHeadersCarrier(c.Header).ForeachKey(handler)
+}
+
+// Get 实现 TextMapReaderLookup 接口，与 HTTPHeadersCarrier.Get 相同，
+// 因此也能找到使用 PreserveHeaderCase 写入的键。
+func (c *ConfigurableHTTPHeadersCarrier) Get(key string) (string, bool) {
+	return HTTPHeadersCarrier(c.Header).Get(key)
+}
+
+// isHTTPToken 判断 s 是否为 RFC 7230 中定义的 token，即一个或多个 tchar：
+//
+//     tchar = "!" / "#" / "$" / "%" / "&" / "'" / "*" / "+" / "-" / "." /
+//             "^" / "_" / "`" / "|" / "~" / DIGIT / ALPHA
+//
+func isHTTPToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isTokenChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isTokenChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0
+}
+
+// TextMapReadWriter 同时满足 TextMapWriter 和 TextMapReader 接口，例如 TextMapCarrier 和 HTTPHeadersCarrier。
+type TextMapReadWriter interface {
+	TextMapWriter
+	TextMapReader
+}
+
+// PrefixedTextMapCarrier 是对 TextMapReadWriter 的装饰，它让多个使用者可以在同一个底层载体中通过各自的前缀区分自己的键值对。
+//
+// Set 会自动在键前加上 Prefix；ForeachKey 只会对带有 Prefix 的键调用`handler`，并且传给`handler`的键已去掉了前缀。
+// 由于 HTTPHeaders 格式不保证键的大小写，前缀的匹配是大小写不敏感的。
+//
+// 例如：
+//
+//     carrier := opentracing.PrefixedTextMapCarrier{
+//         Prefix:  "myprefix-",
+//         Carrier: opentracing.HTTPHeadersCarrier(httpReq.Header),
+//     }
+//     carrier.Set("traceid", "42") // 实际写入的键为 "myprefix-traceid"
+//
+type PrefixedTextMapCarrier struct {
+	Prefix  string
+	Carrier TextMapReadWriter
+}
+
+// Set 实现 TextMapWriter 接口。
+func (c PrefixedTextMapCarrier) Set(key, val string) {
+	c.Carrier.Set(c.Prefix+key, val)
+}
+
+// ForeachKey 实现 TextMapReader 接口。
+func (c PrefixedTextMapCarrier) ForeachKey(handler func(key, val string) error) error {
+	return c.Carrier.ForeachKey(func(key, val string) error {
+		if len(key) < len(c.Prefix) || !strings.EqualFold(key[:len(c.Prefix)], c.Prefix) {
+			return nil
+		}
+		return handler(key[len(c.Prefix):], val)
+	})
+}
+
+// CanInject 判断 sc 能否被 tracer 的 Inject 接受：如果 tracer 实现了 SpanContextValidator 则使用它的判断，
+// 否则乐观地返回 true。空(nil)的 sc 总是返回 false。
+func CanInject(tracer Tracer, sc SpanContext) bool {
+	if sc == nil {
+		return false
+	}
+	if v, ok := tracer.(SpanContextValidator); ok {
+		return v.ValidSpanContext(sc)
+	}
+	return true
+}
+
+// InjectContext 在 tracer 实现了 ContextualPropagator 时调用它的 InjectContext，否则回退到 tracer.Inject。
+//
+// 回退时，如果`ctx`已经被取消，将直接返回`ctx.Err()`而不调用 tracer.Inject。
+func InjectContext(ctx context.Context, tracer Tracer, sc SpanContext, format interface{}, carrier interface{}) error {
+	if p, ok := tracer.(ContextualPropagator); ok {
+		return p.InjectContext(ctx, sc, format, carrier)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return tracer.Inject(sc, format, carrier)
+}
+
+// ExtractContext 在 tracer 实现了 ContextualPropagator 时调用它的 ExtractContext，否则回退到 tracer.Extract。
+//
+// 回退时，如果`ctx`已经被取消，将直接返回`ctx.Err()`而不调用 tracer.Extract。
+func ExtractContext(ctx context.Context, tracer Tracer, format interface{}, carrier interface{}) (SpanContext, error) {
+	if p, ok := tracer.(ContextualPropagator); ok {
+		return p.ExtractContext(ctx, format, carrier)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return tracer.Extract(format, carrier)
+}
+
+// RecordedKeyValue 是 RecordingTextMapWriter 或 RecordingTextMapReader 记录的一个键值对。
+type RecordedKeyValue struct {
+	Key   string
+	Value string
+}
+
+// RecordingTextMapWriter 是一个 TextMapWriter，它把每一次 Set 按顺序记录下来，然后委托给 Writer。
+// 这可以用于调试 Tracer 实际写入载体(carrier)中的内容，见 DebugInject。
+//
+// 调用 Reset 之后可以在多次 Inject 中复用同一个 RecordingTextMapWriter。
+type RecordingTextMapWriter struct {
+	Writer TextMapWriter
+	pairs  []RecordedKeyValue
+}
+
+// NewRecordingTextMapWriter 返回一个委托给 w 的 RecordingTextMapWriter。
+func NewRecordingTextMapWriter(w TextMapWriter) *RecordingTextMapWriter {
+	return &RecordingTextMapWriter{Writer: w}
+}
+
+// Set 实现 TextMapWriter 接口
+func (w *RecordingTextMapWriter) Set(key, val string) {
+	w.pairs = append(w.pairs, RecordedKeyValue{Key: key, Value: val})
+	w.Writer.Set(key, val)
+}
+
+// Recorded 按调用顺序返回自上一次 Reset 以来记录的键值对的副本。
+func (w *RecordingTextMapWriter) Recorded() []RecordedKeyValue {
+	return append([]RecordedKeyValue(nil), w.pairs...)
+}
+
+// Reset 清空已经记录的键值对，不会影响 Writer。
+func (w *RecordingTextMapWriter) Reset() {
+	w.pairs = nil
+}
+
+// RecordingTextMapReader 是一个 TextMapReader，它把 ForeachKey 传递给`handler`的每一个键值对按顺序记录下来。
+// Reader 和`handler`返回的错误会被原样返回。
+//
+// 调用 Reset 之后可以在多次 Extract 中复用同一个 RecordingTextMapReader。
+type RecordingTextMapReader struct {
+	Reader TextMapReader
+	pairs  []RecordedKeyValue
+}
+
+// NewRecordingTextMapReader 返回一个委托给 r 的 RecordingTextMapReader。
+func NewRecordingTextMapReader(r TextMapReader) *RecordingTextMapReader {
+	return &RecordingTextMapReader{Reader: r}
+}
+
+// ForeachKey 实现 TextMapReader 接口
+func (r *RecordingTextMapReader) ForeachKey(handler func(key, val string) error) error {
+	return r.Reader.ForeachKey(func(key, val string) error {
+		r.pairs = append(r.pairs, RecordedKeyValue{Key: key, Value: val})
+		return handler(key, val)
+	})
+}
+
+// Recorded 按访问顺序返回自上一次 Reset 以来记录的键值对的副本。
+func (r *RecordingTextMapReader) Recorded() []RecordedKeyValue {
+	return append([]RecordedKeyValue(nil), r.pairs...)
+}
+
+// Reset 清空已经记录的键值对，不会影响 Reader。
+func (r *RecordingTextMapReader) Reset() {
+	r.pairs = nil
+}
+
+// DebugInject 调用 tracer.Inject(sc, format, carrier)，并返回 Tracer 写入 carrier 的所有键值对，
+// 对同一个键多次写入时以最后一次为准。carrier 仍然会收到所有写入的内容。
+//
+// carrier 必须是一个 TextMapWriter，否则返回 ErrInvalidCarrier。
+func DebugInject(tracer Tracer, sc SpanContext, format interface{}, carrier interface{}) (map[string]string, error) {
+	writer, ok := carrier.(TextMapWriter)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T is not a TextMapWriter", ErrInvalidCarrier, carrier)
+	}
+	recorder := NewRecordingTextMapWriter(writer)
+	if err := tracer.Inject(sc, format, recorder); err != nil {
+		return nil, err
+	}
+	injected := make(map[string]string, len(recorder.pairs))
+	for _, kv := range recorder.pairs {
+		injected[kv.Key] = kv.Value
+	}
+	return injected, nil
+}
+
+// SpanContextToString 以 TextMap 格式调用 tracer.Inject(sc, ...)，并把写入的键值对编码为一个不透明的、
+// URL 安全的字符串（base64url 编码的 URL 查询串），便于把 SpanContext 保存在数据库的字段、命令行参数
+// 或者任务的负载中，之后再用 SpanContextFromString 还原：
+//
+//     s, err := opentracing.SpanContextToString(tracer, span.Context())
+//     ...
+//     sc, err := opentracing.SpanContextFromString(tracer, s)
+//
+// Inject 返回的错误会被原样返回。
+func SpanContextToString(tracer Tracer, sc SpanContext) (string, error) {
+	values := url.Values{}
+	if err := tracer.Inject(sc, TextMap, textMapValuesCarrier(values)); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(values.Encode())), nil
+}
+
+// SpanContextFromString 解码由 SpanContextToString 生成的字符串，并以 TextMap 格式调用 tracer.Extract。
+//
+// 空字符串返回 ErrSpanContextNotFound；无法解码的字符串返回包装了 ErrSpanContextCorrupted 的错误；
+// Extract 返回的错误会被原样返回。
+func SpanContextFromString(tracer Tracer, s string) (SpanContext, error) {
+	if s == "" {
+		return nil, ErrSpanContextNotFound
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSpanContextCorrupted, err)
+	}
+	values, err := url.ParseQuery(string(raw))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSpanContextCorrupted, err)
+	}
+	return tracer.Extract(TextMap, textMapValuesCarrier(values))
+}
+
+// textMapValuesCarrier 让 url.Values 同时满足 TextMapWriter 和 TextMapReader 接口，键保持原样。
+type textMapValuesCarrier url.Values
+
+func (c textMapValuesCarrier) Set(key, val string) {
+	c[key] = []string{val}
+}
+
+func (c textMapValuesCarrier) ForeachKey(handler func(key, val string) error) error {
+	for k, vals := range c {
+		for _, v := range vals {
+			if err := handler(k, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ReaderTextMapCarrier 从`io.Reader`中按行读取`key=value`或`key: value`格式的文本，满足 TextMapReader 接口，
+// 适用于追踪上下文保存在文件或管道中的场景（例如日志重放工具）。
+// 如果设置了 Writer，它同时满足 TextMapWriter 接口，Set 会向 Writer 写入一行`key=value`。
+//
+// 空行和以`#`开头的注释行会被忽略，键和值两边的空白字符会被去掉。
+// 没有分隔符的畸形行默认被跳过；如果 Strict 为true，ForeachKey 会返回一个包装了 ErrSpanContextCorrupted 的错误。
+//
+// Reader 只会在第一次调用 ForeachKey 时被读取，解析的结果会被缓存，以便多次调用 ForeachKey。
+type ReaderTextMapCarrier struct {
+	Reader io.Reader
+	Writer io.Writer
+	Strict bool
+
+	parsed   bool
+	pairs    []RecordedKeyValue
+	parseErr error
+	writeErr error
+}
+
+// NewReaderTextMapCarrier 返回一个从 r 读取的 ReaderTextMapCarrier。
+func NewReaderTextMapCarrier(r io.Reader) *ReaderTextMapCarrier {
+	return &ReaderTextMapCarrier{Reader: r}
+}
+
+// ForeachKey 实现 TextMapReader 接口
+func (c *ReaderTextMapCarrier) ForeachKey(handler func(key, val string) error) error {
+	if !c.parsed {
+		c.parsed = true
+		c.pairs, c.parseErr = c.parse()
+	}
+	if c.parseErr != nil {
+		return c.parseErr
+	}
+	for _, kv := range c.pairs {
+		if err := handler(kv.Key, kv.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *ReaderTextMapCarrier) parse() ([]RecordedKeyValue, error) {
+	if c.Reader == nil {
+		return nil, nil
+	}
+	var pairs []RecordedKeyValue
+	scanner := bufio.NewScanner(c.Reader)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.IndexAny(line, "=:")
+		if i <= 0 {
+			if c.Strict {
+				return nil, fmt.Errorf("%w: malformed line %d: %q", ErrSpanContextCorrupted, n, line)
+			}
+			continue
+		}
+		pairs = append(pairs, RecordedKeyValue{
+			Key:   strings.TrimSpace(line[:i]),
+			Value: strings.TrimSpace(line[i+1:]),
+		})
+	}
+	return pairs, scanner.Err()
+}
+
+// Set 实现 TextMapWriter 接口。如果没有设置 Writer，Set 不会做任何事。
+// 写入的错误可以通过 Err 获得。
+func (c *ReaderTextMapCarrier) Set(key, val string) {
+	if c.Writer == nil || c.writeErr != nil {
+		return
+	}
+	_, c.writeErr = fmt.Fprintf(c.Writer, "%s=%s\n", key, val)
+}
+
+// Err 返回 Set 第一次写入 Writer 失败时的错误。
+func (c *ReaderTextMapCarrier) Err() error {
+	return c.writeErr
+}
+
+// MeasuringTextMapWriter 是一个 TextMapWriter，它把 Set 委托给 Writer，并统计写入的键值对的条数和字节数
+// （每次 Set 计为`len(key)+len(val)`字节），可以用于监控每次 Inject 传播的数据量，例如防止携带数据(baggage)膨胀。
+type MeasuringTextMapWriter struct {
+	Writer TextMapWriter
+	count  int
+	bytes  int
+}
+
+// NewMeasuringTextMapWriter 返回一个委托给 w 的 MeasuringTextMapWriter。
+func NewMeasuringTextMapWriter(w TextMapWriter) *MeasuringTextMapWriter {
+	return &MeasuringTextMapWriter{Writer: w}
+}
+
+// Set 实现 TextMapWriter 接口
+func (w *MeasuringTextMapWriter) Set(key, val string) {
+	w.count++
+	w.bytes += len(key) + len(val)
+	w.Writer.Set(key, val)
+}
+
+// Count 返回 Set 被调用的次数。
+func (w *MeasuringTextMapWriter) Count() int {
+	return w.count
+}
+
+// Bytes 返回所有 Set 写入的键和值的字节数之和。
+func (w *MeasuringTextMapWriter) Bytes() int {
+	return w.bytes
+}
+
+// BinaryCarrier 同时满足 Binary 格式要求的`io.Writer`和`io.Reader`接口，它使用一个字节缓冲区进行存储，
+// 便于通过消息队列的 header 或 protobuf 的 bytes 字段之类的地方传递二进制的 SpanContext：
+//
+//     carrier := opentracing.NewBinaryCarrier(nil)
+//     err := tracer.Inject(span.Context(), opentracing.Binary, carrier)
+//     msg.TraceContext = carrier.Bytes()
+//
+//     clientContext, err := tracer.Extract(opentracing.Binary, opentracing.NewBinaryCarrier(msg.TraceContext))
+//
+// BinaryCarrier 的零值是一个可以直接使用的空载体。
+type BinaryCarrier struct {
+	buf bytes.Buffer
+}
+
+// NewBinaryCarrier 返回一个以 b 为初始内容的 BinaryCarrier，b 会被拷贝。
+func NewBinaryCarrier(b []byte) *BinaryCarrier {
+	c := &BinaryCarrier{}
+	c.SetBytes(b)
+	return c
+}
+
+// Write 实现`io.Writer`接口，供 Inject() 使用。
+func (c *BinaryCarrier) Write(p []byte) (int, error) {
+	return c.buf.Write(p)
+}
+
+// Read 实现`io.Reader`接口，供 Extract() 使用。
+func (c *BinaryCarrier) Read(p []byte) (int, error) {
+	return c.buf.Read(p)
+}
+
+// Bytes 返回载体中尚未被读取的内容的一份拷贝。
+func (c *BinaryCarrier) Bytes() []byte {
+	return append([]byte(nil), c.buf.Bytes()...)
+}
+
+// SetBytes 丢弃载体现有的内容，并把 b 的一份拷贝作为新的内容。
+func (c *BinaryCarrier) SetBytes(b []byte) {
+	c.buf.Reset()
+	c.buf.Write(b)
+}
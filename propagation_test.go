@@ -1,8 +1,17 @@
 package opentracing
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"reflect"
+	"sort"
 	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -88,3 +97,585 @@ func TestHTTPHeaderExtract(t *testing.T) {
 		t.Errorf("Failed to read testprefix-fakeid correctly")
 	}
 }
+
+func TestPrefixedTextMapCarrier(t *testing.T) {
+	m := map[string]string{"NotOT": "blah"}
+	a := PrefixedTextMapCarrier{Prefix: "a-", Carrier: TextMapCarrier(m)}
+	b := PrefixedTextMapCarrier{Prefix: "b-", Carrier: TextMapCarrier(m)}
+	a.Set("id", "1")
+	b.Set("id", "2")
+
+	if len(m) != 3 {
+		t.Errorf("Unexpected map length: %v", len(m))
+	}
+	if m["a-id"] != "1" || m["b-id"] != "2" {
+		t.Errorf("Keys were not prefixed correctly: %v", m)
+	}
+
+	for _, test := range []struct {
+		carrier  PrefixedTextMapCarrier
+		expected string
+	}{
+		{a, "1"},
+		{b, "2"},
+	} {
+		got := make(map[string]string)
+		err := test.carrier.ForeachKey(func(key, val string) error {
+			got[key] = val
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 每个 carrier 都只能看到自己的键，并且前缀已经被去掉
+		if len(got) != 1 || got["id"] != test.expected {
+			t.Errorf("Unexpected keys for prefix %q: %v", test.carrier.Prefix, got)
+		}
+	}
+}
+
+func TestPrefixedTextMapCarrierHTTPHeaders(t *testing.T) {
+	h := http.Header{}
+	carrier := PrefixedTextMapCarrier{Prefix: "myprefix-", Carrier: HTTPHeadersCarrier(h)}
+	carrier.Set("fakeid", "42")
+
+	// http.Header 会把键规范化为 "Myprefix-Fakeid"
+	var found bool
+	err := carrier.ForeachKey(func(key, val string) error {
+		found = strings.EqualFold(key, "fakeid") && val == "42"
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !found {
+		t.Errorf("Failed to read prefixed key from canonicalized headers: %v", h)
+	}
+}
+
+func TestErrorPredicates(t *testing.T) {
+	predicates := []struct {
+		name     string
+		sentinel error
+		is       func(error) bool
+	}{
+		{"IsUnsupportedFormat", ErrUnsupportedFormat, IsUnsupportedFormat},
+		{"IsSpanContextNotFound", ErrSpanContextNotFound, IsSpanContextNotFound},
+		{"IsInvalidSpanContext", ErrInvalidSpanContext, IsInvalidSpanContext},
+		{"IsInvalidCarrier", ErrInvalidCarrier, IsInvalidCarrier},
+		{"IsSpanContextCorrupted", ErrSpanContextCorrupted, IsSpanContextCorrupted},
+	}
+	for _, p := range predicates {
+		if !p.is(p.sentinel) {
+			t.Errorf("%s should match the sentinel error", p.name)
+		}
+		wrapped := fmt.Errorf("format %v: %w", TextMap, p.sentinel)
+		if !p.is(wrapped) {
+			t.Errorf("%s should match the wrapped sentinel error", p.name)
+		}
+		if p.is(errors.New("unrelated")) || p.is(nil) {
+			t.Errorf("%s should not match unrelated errors", p.name)
+		}
+		for _, other := range predicates {
+			if other.name != p.name && p.is(other.sentinel) {
+				t.Errorf("%s should not match %v", p.name, other.sentinel)
+			}
+		}
+	}
+}
+
+func TestCommaSplitHTTPHeadersCarrier(t *testing.T) {
+	h := http.Header{}
+	h.Add("X-Custom", "v1, v2 ,,v3")
+	h.Add("X-Custom", "v4")
+	h.Add("X-Other", "a,b")
+	carrier := NewCommaSplitHTTPHeadersCarrier(h, "x-custom")
+
+	got := map[string][]string{}
+	err := carrier.ForeachKey(func(k, v string) error {
+		got[k] = append(got[k], v)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"v1", "v2", "v3", "v4"}; !reflect.DeepEqual(got["X-Custom"], want) {
+		t.Errorf("X-Custom: got %q, want %q", got["X-Custom"], want)
+	}
+	if want := []string{"a,b"}; !reflect.DeepEqual(got["X-Other"], want) {
+		t.Errorf("X-Other: got %q, want %q", got["X-Other"], want)
+	}
+
+	carrier.Set("x-trace", "1")
+	if v := h.Get("X-Trace"); v != "1" {
+		t.Errorf("Set: got %q, want %q", v, "1")
+	}
+
+	errStop := errors.New("stop")
+	calls := 0
+	err = NewCommaSplitHTTPHeadersCarrier(http.Header{"X-Custom": {"a,b,c"}}, "X-Custom").ForeachKey(func(k, v string) error {
+		calls++
+		return errStop
+	})
+	if err != errStop || calls != 1 {
+		t.Errorf("ForeachKey should stop at the first error: err=%v, calls=%d", err, calls)
+	}
+}
+
+func TestHTTPHeadersCarrierAdd(t *testing.T) {
+	h := http.Header{}
+	carrier := HTTPHeadersCarrier(h)
+	carrier.Add("x-multi", "a")
+	carrier.Add("X-Multi", "b")
+	if got := h[http.CanonicalHeaderKey("X-Multi")]; !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("Expected Add to append values, got %v", got)
+	}
+}
+
+func TestNewHTTPHeadersCarrier(t *testing.T) {
+	h := http.Header{}
+	carrier := NewHTTPHeadersCarrier(h)
+	carrier.Set("x-key", "a")
+	carrier.Set("x-key", "b")
+	if !reflect.DeepEqual(h, http.Header{"X-Key": {"b"}}) {
+		t.Errorf("Expected default carrier to canonicalize and overwrite, got %v", h)
+	}
+
+	h = http.Header{}
+	carrier = NewHTTPHeadersCarrier(h, PreserveHeaderCase(), AppendHeaderValues())
+	carrier.Set("x-key", "a")
+	carrier.Set("x-key", "b")
+	if !reflect.DeepEqual(h, http.Header{"x-key": {"a", "b"}}) {
+		t.Errorf("Expected carrier to preserve case and append, got %v", h)
+	}
+
+	h = http.Header{}
+	carrier = NewHTTPHeadersCarrier(h, PreserveHeaderCase())
+	carrier.Set("x-key", "a")
+	carrier.Add("x-key", "b")
+	carrier.Set("x-other", "c")
+	carrier.Set("x-other", "d")
+	if !reflect.DeepEqual(h, http.Header{"x-key": {"a", "b"}, "x-other": {"d"}}) {
+		t.Errorf("Unexpected headers: %v", h)
+	}
+	var n int
+	_ = carrier.ForeachKey(func(key, val string) error {
+		n++
+		return nil
+	})
+	if n != 3 {
+		t.Errorf("Expected ForeachKey to visit 3 values, got %d", n)
+	}
+}
+
+func TestTextMapGet(t *testing.T) {
+	var _ TextMapReaderLookup = TextMapCarrier{}
+	var _ TextMapReaderLookup = HTTPHeadersCarrier{}
+
+	h := http.Header{}
+	h.Add("X-Key", "a")
+	h.Add("X-Key", "b")
+	h["lower"] = []string{"c"}
+	readers := map[string]TextMapReader{
+		"TextMapCarrier":     TextMapCarrier{"X-Key": "a"},
+		"HTTPHeadersCarrier": HTTPHeadersCarrier(h),
+		"ForeachKey only":    NewRecordingTextMapReader(TextMapCarrier{"X-Key": "a"}),
+	}
+	for name, r := range readers {
+		if v, ok := TextMapGet(r, "X-Key"); !ok || v != "a" {
+			t.Errorf("%s: Expected X-Key=a, got %q, %v", name, v, ok)
+		}
+		if _, ok := TextMapGet(r, "missing"); ok {
+			t.Errorf("%s: Expected missing key not to be found", name)
+		}
+	}
+	if v, ok := TextMapGet(HTTPHeadersCarrier(h), "x-key"); !ok || v != "a" {
+		t.Errorf("Expected HTTPHeadersCarrier.Get to canonicalize the key, got %q, %v", v, ok)
+	}
+	if v, ok := TextMapGet(HTTPHeadersCarrier(h), "lower"); !ok || v != "c" {
+		t.Errorf("Expected HTTPHeadersCarrier.Get to find non-canonical keys, got %q, %v", v, ok)
+	}
+
+	keys := TextMapKeys(HTTPHeadersCarrier(h))
+	sort.Strings(keys)
+	if !reflect.DeepEqual(keys, []string{"X-Key", "lower"}) {
+		t.Errorf("Unexpected keys: %v", keys)
+	}
+}
+
+func TestHTTPHeadersCarriersGet(t *testing.T) {
+	h := http.Header{}
+	h.Add("X-Key", "a")
+	h.Add("X-Key", "b")
+	h["lower"] = []string{"c"}
+	h.Set("X-List", " , d, e")
+	readers := map[string]TextMapReaderLookup{
+		"HTTPHeadersCarrier":             HTTPHeadersCarrier(h),
+		"ConfigurableHTTPHeadersCarrier": NewHTTPHeadersCarrier(h, PreserveHeaderCase()),
+		"CommaSplitHTTPHeadersCarrier":   NewCommaSplitHTTPHeadersCarrier(h, "x-list"),
+		"ValidatingHTTPHeadersCarrier":   NewValidatingHTTPHeadersCarrier(h),
+	}
+	for name, r := range readers {
+		for _, key := range []string{"X-Key", "x-key", "X-KEY"} {
+			if v, ok := r.Get(key); !ok || v != "a" {
+				t.Errorf("%s: Expected %s=a, got %q, %v", name, key, v, ok)
+			}
+		}
+		if v, ok := r.Get("lower"); !ok || v != "c" {
+			t.Errorf("%s: Expected lower=c, got %q, %v", name, v, ok)
+		}
+		if _, ok := r.Get("missing"); ok {
+			t.Errorf("%s: Expected missing key not to be found", name)
+		}
+	}
+	if v, ok := readers["CommaSplitHTTPHeadersCarrier"].Get("x-list"); !ok || v != "d" {
+		t.Errorf("Expected CommaSplitHTTPHeadersCarrier.Get to return the first segment, got %q, %v", v, ok)
+	}
+	if v, ok := readers["HTTPHeadersCarrier"].Get("x-list"); !ok || v != " , d, e" {
+		t.Errorf("Expected HTTPHeadersCarrier.Get to return the raw value, got %q, %v", v, ok)
+	}
+}
+
+func TestSpanContextString(t *testing.T) {
+	tracer := testTracer{}
+	sc := testSpanContext{FakeID: 42}
+	s, err := SpanContextToString(tracer, sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.ContainsAny(s, "+/=&?% ") {
+		t.Errorf("Expected a URL-safe string, got %q", s)
+	}
+	extracted, err := SpanContextFromString(tracer, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if extracted.(testSpanContext).FakeID != 42 {
+		t.Errorf("Failed to round trip through a string: %+v", extracted)
+	}
+
+	if _, err := SpanContextFromString(tracer, ""); err != ErrSpanContextNotFound {
+		t.Errorf("Expected ErrSpanContextNotFound for an empty string, got %v", err)
+	}
+	if _, err := SpanContextFromString(tracer, "not base64!"); !IsSpanContextCorrupted(err) {
+		t.Errorf("Expected ErrSpanContextCorrupted for an invalid string, got %v", err)
+	}
+	if _, err := SpanContextToString(NoopTracer{}, noopSpanContext{}); err != nil {
+		t.Errorf("Unexpected error for NoopTracer: %v", err)
+	}
+}
+
+func TestValidatingHTTPHeadersCarrier(t *testing.T) {
+	h := http.Header{}
+	carrier := NewValidatingHTTPHeadersCarrier(h)
+	carrier.Set("testprefix-fakeid", "42")
+	if err := carrier.Err(); err != nil {
+		t.Errorf("Unexpected error for valid key: %v", err)
+	}
+
+	carrier.Set("bad key", "1")
+	carrier.Set("bad:key", "2")
+	carrier.Set("", "3")
+	carrier.Set("键", "4")
+	if len(h) != 1 || h.Get("testprefix-fakeid") != "42" {
+		t.Errorf("Invalid keys must not be written: %v", h)
+	}
+	err := carrier.Err()
+	if err == nil {
+		t.Fatal("Expected an error for invalid keys")
+	}
+	if !IsInvalidCarrier(err) {
+		t.Errorf("Expected error to wrap ErrInvalidCarrier: %v", err)
+	}
+	if !strings.Contains(err.Error(), `"bad key"`) || !strings.Contains(err.Error(), `"bad:key"`) {
+		t.Errorf("Expected error to list the invalid keys: %v", err)
+	}
+
+	// 与 testTracer 往返
+	tracer := testTracer{}
+	span := tracer.StartSpan("someSpan")
+	carrier = NewValidatingHTTPHeadersCarrier(http.Header{})
+	if err := tracer.Inject(span.Context(), HTTPHeaders, carrier); err != nil {
+		t.Fatal(err)
+	}
+	extracted, err := tracer.Extract(HTTPHeaders, carrier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if extracted.(testSpanContext).FakeID != span.Context().(testSpanContext).FakeID {
+		t.Errorf("Failed to round trip through ValidatingHTTPHeadersCarrier")
+	}
+}
+
+// contextualTestTracer 是实现了 ContextualPropagator 的 testTracer
+type contextualTestTracer struct {
+	testTracer
+	calls int
+}
+
+func (c *contextualTestTracer) InjectContext(ctx context.Context, sc SpanContext, format interface{}, carrier interface{}) error {
+	c.calls++
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.Inject(sc, format, carrier)
+}
+
+func (c *contextualTestTracer) ExtractContext(ctx context.Context, format interface{}, carrier interface{}) (SpanContext, error) {
+	c.calls++
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.Extract(format, carrier)
+}
+
+func TestInjectExtractContext(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, tracer := range []Tracer{testTracer{}, &contextualTestTracer{}} {
+		span := tracer.StartSpan("someSpan")
+		carrier := TextMapCarrier{}
+		if err := InjectContext(context.Background(), tracer, span.Context(), TextMap, carrier); err != nil {
+			t.Fatal(err)
+		}
+		sc, err := ExtractContext(context.Background(), tracer, TextMap, carrier)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sc.(testSpanContext).FakeID != span.Context().(testSpanContext).FakeID {
+			t.Errorf("Failed to round trip with %T", tracer)
+		}
+
+		if err := InjectContext(canceled, tracer, span.Context(), TextMap, TextMapCarrier{}); err != context.Canceled {
+			t.Errorf("Expected context.Canceled from InjectContext with %T, got %v", tracer, err)
+		}
+		if _, err := ExtractContext(canceled, tracer, TextMap, carrier); err != context.Canceled {
+			t.Errorf("Expected context.Canceled from ExtractContext with %T, got %v", tracer, err)
+		}
+		if c, ok := tracer.(*contextualTestTracer); ok && c.calls != 4 {
+			t.Errorf("Expected the contextual methods to be called 4 times, got %d", c.calls)
+		}
+	}
+}
+
+func TestRecordingTextMapWriter(t *testing.T) {
+	underlying := TextMapCarrier{}
+	w := NewRecordingTextMapWriter(underlying)
+	w.Set("b", "1")
+	w.Set("a", "2")
+	w.Set("b", "3")
+
+	expected := []RecordedKeyValue{{"b", "1"}, {"a", "2"}, {"b", "3"}}
+	if got := w.Recorded(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	if !reflect.DeepEqual(underlying, TextMapCarrier{"a": "2", "b": "3"}) {
+		t.Errorf("Underlying carrier did not receive everything: %v", underlying)
+	}
+
+	w.Reset()
+	if got := w.Recorded(); len(got) != 0 {
+		t.Errorf("Expected no pairs after Reset, got %v", got)
+	}
+	w.Set("c", "4")
+	if got := w.Recorded(); !reflect.DeepEqual(got, []RecordedKeyValue{{"c", "4"}}) {
+		t.Errorf("Unexpected pairs after reuse: %v", got)
+	}
+}
+
+func TestRecordingTextMapReader(t *testing.T) {
+	r := NewRecordingTextMapReader(HTTPHeadersCarrier(http.Header{"A": {"1", "2"}}))
+	var seen []string
+	if err := r.ForeachKey(func(key, val string) error {
+		seen = append(seen, key+"="+val)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	expected := []RecordedKeyValue{{"A", "1"}, {"A", "2"}}
+	if got := r.Recorded(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	if len(seen) != 2 {
+		t.Errorf("Handler was not called for every pair: %v", seen)
+	}
+
+	r.Reset()
+	stop := errors.New("stop")
+	if err := r.ForeachKey(func(key, val string) error { return stop }); err != stop {
+		t.Errorf("Expected handler error to be returned, got %v", err)
+	}
+	if got := r.Recorded(); len(got) != 1 {
+		t.Errorf("Expected a single pair before the error, got %v", got)
+	}
+}
+
+func TestDebugInject(t *testing.T) {
+	tracer := testTracer{}
+	span := tracer.StartSpan("someSpan")
+	carrier := TextMapCarrier{}
+	injected, err := DebugInject(tracer, span.Context(), TextMap, carrier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"testprefix-fakeid": strconv.Itoa(span.Context().(testSpanContext).FakeID)}
+	if !reflect.DeepEqual(injected, expected) {
+		t.Errorf("Expected %v, got %v", expected, injected)
+	}
+	if !reflect.DeepEqual(map[string]string(carrier), expected) {
+		t.Errorf("Underlying carrier did not receive the injected pairs: %v", carrier)
+	}
+
+	if _, err := DebugInject(tracer, span.Context(), Binary, &bytes.Buffer{}); !IsInvalidCarrier(err) {
+		t.Errorf("Expected ErrInvalidCarrier, got %v", err)
+	}
+	if _, err := DebugInject(tracer, span.Context(), Binary, carrier); !IsUnsupportedFormat(err) {
+		t.Errorf("Expected the Inject error to be returned, got %v", err)
+	}
+}
+
+func TestReaderTextMapCarrier(t *testing.T) {
+	input := `
+# 从日志中导出的追踪上下文
+testprefix-fakeid: 17
+
+malformed line
+ot-baggage-user = a=b:c
+`
+	carrier := NewReaderTextMapCarrier(strings.NewReader(input))
+	var got []RecordedKeyValue
+	collect := func(key, val string) error {
+		got = append(got, RecordedKeyValue{key, val})
+		return nil
+	}
+	if err := carrier.ForeachKey(collect); err != nil {
+		t.Fatal(err)
+	}
+	expected := []RecordedKeyValue{{"testprefix-fakeid", "17"}, {"ot-baggage-user", "a=b:c"}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	// 解析结果被缓存，可以再次遍历
+	got = nil
+	if err := carrier.ForeachKey(collect); err != nil || len(got) != 2 {
+		t.Errorf("Expected a second ForeachKey to see the same pairs, got %v, %v", got, err)
+	}
+
+	sc, err := testTracer{}.Extract(TextMap, NewReaderTextMapCarrier(strings.NewReader(input)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.(testSpanContext).FakeID != 17 {
+		t.Errorf("Unexpected extracted context: %v", sc)
+	}
+
+	strict := &ReaderTextMapCarrier{Reader: strings.NewReader(input), Strict: true}
+	if err := strict.ForeachKey(collect); !IsSpanContextCorrupted(err) || !strings.Contains(err.Error(), "line 5") {
+		t.Errorf("Expected ErrSpanContextCorrupted for the malformed line, got %v", err)
+	}
+}
+
+func TestReaderTextMapCarrierWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := &ReaderTextMapCarrier{Writer: &buf}
+	span := testTracer{}.StartSpan("someSpan")
+	if err := span.Tracer().Inject(span.Context(), TextMap, w); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Err(); err != nil {
+		t.Fatal(err)
+	}
+	sc, err := testTracer{}.Extract(TextMap, NewReaderTextMapCarrier(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.(testSpanContext).FakeID != span.Context().(testSpanContext).FakeID {
+		t.Errorf("Failed to round trip through ReaderTextMapCarrier")
+	}
+}
+
+func TestMeasuringTextMapWriter(t *testing.T) {
+	underlying := TextMapCarrier{}
+	w := NewMeasuringTextMapWriter(underlying)
+	w.Set("a", "1")
+	w.Set("key", "value")
+	w.Set("用户", "")
+	if w.Count() != 3 {
+		t.Errorf("Expected 3 keys, got %d", w.Count())
+	}
+	if expected := 2 + 8 + len("用户"); w.Bytes() != expected {
+		t.Errorf("Expected %d bytes, got %d", expected, w.Bytes())
+	}
+	if len(underlying) != 3 || underlying["key"] != "value" {
+		t.Errorf("Underlying carrier did not receive everything: %v", underlying)
+	}
+
+	w = NewMeasuringTextMapWriter(TextMapCarrier{})
+	span := testTracer{}.StartSpan("someSpan")
+	if err := span.Tracer().Inject(span.Context(), TextMap, w); err != nil {
+		t.Fatal(err)
+	}
+	fakeID := strconv.Itoa(span.Context().(testSpanContext).FakeID)
+	if w.Count() != 1 || w.Bytes() != len("testprefix-fakeid")+len(fakeID) {
+		t.Errorf("Unexpected measurements after Inject: %d keys, %d bytes", w.Count(), w.Bytes())
+	}
+}
+
+// validatingTestTracer 只接受 testSpanContext
+type validatingTestTracer struct {
+	testTracer
+}
+
+func (validatingTestTracer) ValidSpanContext(sc SpanContext) bool {
+	_, ok := sc.(testSpanContext)
+	return ok
+}
+
+func TestCanInject(t *testing.T) {
+	var tracer Tracer = validatingTestTracer{}
+	if !CanInject(tracer, testSpanContext{}) {
+		t.Error("testSpanContext should be accepted by validatingTestTracer")
+	}
+	if CanInject(tracer, noopSpanContext{}) {
+		t.Error("noopSpanContext should be rejected by validatingTestTracer")
+	}
+
+	tracer = testTracer{}
+	if !CanInject(tracer, noopSpanContext{}) {
+		t.Error("tracers without SpanContextValidator should accept any context")
+	}
+	if CanInject(tracer, nil) {
+		t.Error("nil contexts should never be accepted")
+	}
+}
+
+func TestBinaryCarrier(t *testing.T) {
+	var carrier BinaryCarrier
+	if _, err := io.WriteString(&carrier, "context"); err != nil {
+		t.Fatal(err)
+	}
+	data := carrier.Bytes()
+	if string(data) != "context" {
+		t.Errorf("Bytes: got %q, want %q", data, "context")
+	}
+	data[0] = 'X'
+
+	extracted := NewBinaryCarrier(carrier.Bytes())
+	read, err := ioutil.ReadAll(extracted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(read) != "context" {
+		t.Errorf("Read: got %q, want %q", read, "context")
+	}
+	if len(extracted.Bytes()) != 0 {
+		t.Errorf("Bytes should only return unread data, got %q", extracted.Bytes())
+	}
+
+	extracted.SetBytes([]byte("other"))
+	if string(extracted.Bytes()) != "other" {
+		t.Errorf("SetBytes: got %q, want %q", extracted.Bytes(), "other")
+	}
+}
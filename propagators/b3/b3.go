@@ -0,0 +1,287 @@
+// Package b3 encodes and decodes Zipkin B3 propagation headers
+// (https://github.com/openzipkin/b3-propagation) on top of
+// opentracing.TextMapWriter and opentracing.TextMapReader, so that Tracer
+// implementations can support B3 interop by composition:
+//
+//     func (t *myTracer) Inject(sc opentracing.SpanContext, format interface{}, carrier interface{}) error {
+//         ...
+//         return b3.InjectMulti(b3.Context{TraceID: ..., SpanID: ..., Sampling: b3.Accept}, w)
+//     }
+//
+// Tracers whose SpanContext is an opentracing.BasicSpanContext can convert
+// with FromBasic and Context.ToBasic.
+//
+// Both the multi-header format (X-B3-TraceId, X-B3-SpanId, ...) and the
+// single "b3" header are supported.
+package b3
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+// Header names used by the B3 formats. Lookups are case-insensitive.
+const (
+	TraceIDHeader      = "X-B3-TraceId"
+	SpanIDHeader       = "X-B3-SpanId"
+	ParentSpanIDHeader = "X-B3-ParentSpanId"
+	SampledHeader      = "X-B3-Sampled"
+	FlagsHeader        = "X-B3-Flags"
+	SingleHeader       = "b3"
+)
+
+// Sampling is the sampling decision carried by B3 headers.
+type Sampling int
+
+const (
+	// Defer means no sampling decision was made; the receiver decides.
+	Defer Sampling = iota
+	// Deny means the trace must not be reported.
+	Deny
+	// Accept means the trace should be reported.
+	Accept
+	// Debug means the trace should be reported regardless of sampling.
+	Debug
+)
+
+// Context holds the fields of a B3 propagated span context. IDs are
+// lowercase hex strings: TraceID is 16 or 32 characters long, SpanID and
+// ParentSpanID 16. A Context with an empty TraceID carries only a sampling
+// decision, which the single header format allows (e.g. "b3: 0").
+type Context struct {
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
+	Sampling     Sampling
+}
+
+// FromBasic returns the B3 context of sc, with Sampling Accept or Deny. B3
+// does not propagate baggage, so the baggage of sc is dropped.
+func FromBasic(sc opentracing.BasicSpanContext) Context {
+	c := Context{TraceID: sc.TraceIDString(), SpanID: sc.SpanIDString(), Sampling: Deny}
+	if sc.Sampled {
+		c.Sampling = Accept
+	}
+	return c
+}
+
+// ToBasic returns c as an opentracing.BasicSpanContext. Only Deny makes it
+// unsampled: with Defer the receiver decides, and the BasicSpanContext has
+// no way to leave the decision open.
+//
+// It returns opentracing.ErrSpanContextNotFound if c carries only a sampling
+// decision, and an error wrapping opentracing.ErrSpanContextCorrupted if its
+// IDs are malformed.
+func (c Context) ToBasic() (opentracing.BasicSpanContext, error) {
+	if err := c.validate(); err != nil {
+		return opentracing.BasicSpanContext{}, corrupted("%v", err)
+	}
+	if c.TraceID == "" {
+		return opentracing.BasicSpanContext{}, opentracing.ErrSpanContextNotFound
+	}
+	sc := opentracing.BasicSpanContext{Sampled: c.Sampling != Deny}
+	traceID := c.TraceID
+	if len(traceID) == 32 {
+		sc.TraceIDHigh, _ = strconv.ParseUint(traceID[:16], 16, 64)
+		traceID = traceID[16:]
+	}
+	// validate checked that the IDs are lowercase hex of the right length
+	sc.TraceID, _ = strconv.ParseUint(traceID, 16, 64)
+	sc.SpanID, _ = strconv.ParseUint(c.SpanID, 16, 64)
+	return sc, nil
+}
+
+// validate checks the IDs of c: either both TraceID and SpanID are set and
+// well-formed, or neither is and there is no ParentSpanID.
+func (c Context) validate() error {
+	if c.TraceID == "" && c.SpanID == "" && c.ParentSpanID == "" {
+		return nil
+	}
+	if len(c.TraceID) != 16 && len(c.TraceID) != 32 || !isLowerHex(c.TraceID) {
+		return fmt.Errorf("invalid trace id %q", c.TraceID)
+	}
+	if len(c.SpanID) != 16 || !isLowerHex(c.SpanID) {
+		return fmt.Errorf("invalid span id %q", c.SpanID)
+	}
+	if c.ParentSpanID != "" && (len(c.ParentSpanID) != 16 || !isLowerHex(c.ParentSpanID)) {
+		return fmt.Errorf("invalid parent span id %q", c.ParentSpanID)
+	}
+	return nil
+}
+
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// InjectMulti writes c to w using the multi-header format. It returns an
+// error wrapping opentracing.ErrInvalidSpanContext if c is not well-formed.
+func InjectMulti(c Context, w opentracing.TextMapWriter) error {
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("%w: b3: %v", opentracing.ErrInvalidSpanContext, err)
+	}
+	if c.TraceID != "" {
+		w.Set(TraceIDHeader, c.TraceID)
+		w.Set(SpanIDHeader, c.SpanID)
+		if c.ParentSpanID != "" {
+			w.Set(ParentSpanIDHeader, c.ParentSpanID)
+		}
+	}
+	switch c.Sampling {
+	case Deny:
+		w.Set(SampledHeader, "0")
+	case Accept:
+		w.Set(SampledHeader, "1")
+	case Debug:
+		w.Set(FlagsHeader, "1")
+	}
+	return nil
+}
+
+// InjectSingle writes c to w as the single "b3" header:
+//
+//     {TraceId}-{SpanId}-{SamplingState}-{ParentSpanId}
+//
+// where the last two parts are optional, or just {SamplingState} if c has no
+// IDs. The format only allows a parent span id after a sampling state, so
+// ParentSpanID is omitted when Sampling is Defer. It returns an error wrapping opentracing.ErrInvalidSpanContext if c is
+// not well-formed or carries neither IDs nor a sampling decision.
+func InjectSingle(c Context, w opentracing.TextMapWriter) error {
+	if err := c.validate(); err != nil {
+		return fmt.Errorf("%w: b3: %v", opentracing.ErrInvalidSpanContext, err)
+	}
+	var sampling string
+	switch c.Sampling {
+	case Deny:
+		sampling = "0"
+	case Accept:
+		sampling = "1"
+	case Debug:
+		sampling = "d"
+	}
+	if c.TraceID == "" {
+		if sampling == "" {
+			return fmt.Errorf("%w: b3: empty context", opentracing.ErrInvalidSpanContext)
+		}
+		w.Set(SingleHeader, sampling)
+		return nil
+	}
+	v := c.TraceID + "-" + c.SpanID
+	if sampling != "" {
+		v += "-" + sampling
+	}
+	if c.ParentSpanID != "" && sampling != "" {
+		v += "-" + c.ParentSpanID
+	}
+	w.Set(SingleHeader, v)
+	return nil
+}
+
+// Extract reads a B3 context from r. The single "b3" header takes precedence
+// over the multi-header format when both are present.
+//
+// It returns opentracing.ErrSpanContextNotFound if there are no B3 headers,
+// and an error wrapping opentracing.ErrSpanContextCorrupted if they are
+// malformed.
+func Extract(r opentracing.TextMapReader) (Context, error) {
+	var single string
+	multi := map[string]string{}
+	err := r.ForeachKey(func(key, val string) error {
+		switch k := strings.ToLower(key); k {
+		case SingleHeader:
+			single = val
+		case strings.ToLower(TraceIDHeader), strings.ToLower(SpanIDHeader),
+			strings.ToLower(ParentSpanIDHeader), strings.ToLower(SampledHeader), strings.ToLower(FlagsHeader):
+			multi[k] = val
+		}
+		return nil
+	})
+	if err != nil {
+		return Context{}, err
+	}
+	if single != "" {
+		return ParseSingle(single)
+	}
+	if len(multi) == 0 {
+		return Context{}, opentracing.ErrSpanContextNotFound
+	}
+	c := Context{
+		TraceID:      multi[strings.ToLower(TraceIDHeader)],
+		SpanID:       multi[strings.ToLower(SpanIDHeader)],
+		ParentSpanID: multi[strings.ToLower(ParentSpanIDHeader)],
+	}
+	switch multi[strings.ToLower(SampledHeader)] {
+	case "":
+	case "0", "false":
+		c.Sampling = Deny
+	case "1", "true":
+		c.Sampling = Accept
+	default:
+		return Context{}, corrupted("invalid %s %q", SampledHeader, multi[strings.ToLower(SampledHeader)])
+	}
+	if multi[strings.ToLower(FlagsHeader)] == "1" {
+		c.Sampling = Debug
+	}
+	if err := c.validate(); err != nil {
+		return Context{}, corrupted("%v", err)
+	}
+	return c, nil
+}
+
+// ParseSingle parses the value of a single "b3" header. Errors wrap
+// opentracing.ErrSpanContextCorrupted.
+func ParseSingle(v string) (Context, error) {
+	parts := strings.Split(v, "-")
+	var c Context
+	if len(parts) == 1 {
+		// Only a sampling decision.
+		s, err := parseSingleSampling(parts[0])
+		if err != nil {
+			return Context{}, err
+		}
+		c.Sampling = s
+		return c, nil
+	}
+	if len(parts) > 4 {
+		return Context{}, corrupted("malformed b3 header %q", v)
+	}
+	c.TraceID, c.SpanID = parts[0], parts[1]
+	if len(parts) > 2 {
+		s, err := parseSingleSampling(parts[2])
+		if err != nil {
+			return Context{}, err
+		}
+		c.Sampling = s
+	}
+	if len(parts) > 3 {
+		c.ParentSpanID = parts[3]
+	}
+	if err := c.validate(); err != nil {
+		return Context{}, corrupted("%v", err)
+	}
+	return c, nil
+}
+
+func parseSingleSampling(s string) (Sampling, error) {
+	switch s {
+	case "0":
+		return Deny, nil
+	case "1":
+		return Accept, nil
+	case "d":
+		return Debug, nil
+	}
+	return Defer, corrupted("invalid b3 sampling state %q", s)
+}
+
+func corrupted(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: b3: %s", opentracing.ErrSpanContextCorrupted, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,115 @@
+package b3
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+const (
+	traceID  = "463ac35c9f6413ad48485a3953bb6124"
+	spanID   = "a2fb4a1d1a96d312"
+	parentID = "0020000000000001"
+)
+
+func TestMultiRoundTrip(t *testing.T) {
+	for _, c := range []Context{
+		{TraceID: traceID, SpanID: spanID, ParentSpanID: parentID, Sampling: Accept},
+		{TraceID: traceID[16:], SpanID: spanID, Sampling: Deny},
+		{TraceID: traceID, SpanID: spanID, Sampling: Debug},
+		{TraceID: traceID, SpanID: spanID},
+		{Sampling: Deny},
+	} {
+		h := http.Header{}
+		require.NoError(t, InjectMulti(c, opentracing.HTTPHeadersCarrier(h)))
+		got, err := Extract(opentracing.HTTPHeadersCarrier(h))
+		require.NoError(t, err)
+		assert.Equal(t, c, got)
+	}
+}
+
+func TestSingleRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		c      Context
+		header string
+	}{
+		{Context{TraceID: traceID, SpanID: spanID, ParentSpanID: parentID, Sampling: Accept}, traceID + "-" + spanID + "-1-" + parentID},
+		{Context{TraceID: traceID, SpanID: spanID, Sampling: Debug}, traceID + "-" + spanID + "-d"},
+		{Context{TraceID: traceID, SpanID: spanID}, traceID + "-" + spanID},
+		{Context{Sampling: Deny}, "0"},
+	} {
+		carrier := opentracing.TextMapCarrier{}
+		require.NoError(t, InjectSingle(tc.c, carrier))
+		assert.Equal(t, opentracing.TextMapCarrier{"b3": tc.header}, carrier)
+		got, err := Extract(carrier)
+		require.NoError(t, err)
+		assert.Equal(t, tc.c, got)
+	}
+
+	// A parent span id may only follow a sampling state.
+	carrier := opentracing.TextMapCarrier{}
+	require.NoError(t, InjectSingle(Context{TraceID: traceID, SpanID: spanID, ParentSpanID: parentID}, carrier))
+	assert.Equal(t, traceID+"-"+spanID, carrier["b3"])
+}
+
+func TestExtractPrefersSingleHeader(t *testing.T) {
+	h := http.Header{}
+	h.Set("X-B3-TraceId", traceID)
+	h.Set("X-B3-SpanId", spanID)
+	h.Set("B3", "0")
+	got, err := Extract(opentracing.HTTPHeadersCarrier(h))
+	require.NoError(t, err)
+	assert.Equal(t, Context{Sampling: Deny}, got)
+}
+
+func TestExtractErrors(t *testing.T) {
+	_, err := Extract(opentracing.TextMapCarrier{"other": "x"})
+	assert.Equal(t, opentracing.ErrSpanContextNotFound, err)
+
+	for _, carrier := range []opentracing.TextMapCarrier{
+		{"b3": "x"},
+		{"b3": traceID + "-" + spanID + "-2"},
+		{"b3": traceID + "-short"},
+		{"b3": "a-b-c-d-e"},
+		{"x-b3-traceid": traceID},
+		{"x-b3-traceid": "ABC", "x-b3-spanid": spanID},
+		{"x-b3-traceid": traceID, "x-b3-spanid": spanID, "x-b3-sampled": "yes"},
+	} {
+		_, err := Extract(carrier)
+		assert.True(t, opentracing.IsSpanContextCorrupted(err), "%v: %v", carrier, err)
+	}
+
+	err = InjectMulti(Context{TraceID: "bad", SpanID: spanID}, opentracing.TextMapCarrier{})
+	assert.True(t, opentracing.IsInvalidSpanContext(err))
+	err = InjectSingle(Context{}, opentracing.TextMapCarrier{})
+	assert.True(t, opentracing.IsInvalidSpanContext(err))
+}
+
+func TestBasicConversion(t *testing.T) {
+	sc := opentracing.BasicSpanContext{TraceIDHigh: 0x463ac35c9f6413ad, TraceID: 0x48485a3953bb6124, SpanID: 0xa2fb4a1d1a96d312, Sampled: true}
+	c := FromBasic(sc)
+	assert.Equal(t, Context{TraceID: traceID, SpanID: spanID, Sampling: Accept}, c)
+	got, err := c.ToBasic()
+	require.NoError(t, err)
+	assert.Equal(t, sc, got)
+
+	sc = opentracing.BasicSpanContext{TraceID: 1, SpanID: 2}.WithBaggageItem("dropped", "x")
+	c = FromBasic(sc)
+	assert.Equal(t, Context{TraceID: "0000000000000001", SpanID: "0000000000000002", Sampling: Deny}, c)
+	got, err = c.ToBasic()
+	require.NoError(t, err)
+	assert.Equal(t, opentracing.BasicSpanContext{TraceID: 1, SpanID: 2}, got)
+
+	got, err = Context{TraceID: traceID, SpanID: spanID}.ToBasic()
+	require.NoError(t, err)
+	assert.True(t, got.Sampled, "Defer is treated as sampled")
+
+	_, err = Context{Sampling: Accept}.ToBasic()
+	assert.Equal(t, opentracing.ErrSpanContextNotFound, err)
+	_, err = Context{TraceID: "xyz", SpanID: spanID}.ToBasic()
+	assert.ErrorIs(t, err, opentracing.ErrSpanContextCorrupted)
+}
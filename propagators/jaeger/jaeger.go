@@ -0,0 +1,235 @@
+// Package jaeger encodes and decodes the Jaeger propagation format
+// (https://www.jaegertracing.io/docs/latest/client-libraries/#propagation-format)
+// on top of opentracing.TextMapWriter and opentracing.TextMapReader, so that
+// Tracer implementations can interoperate with Jaeger-instrumented services
+// by composition:
+//
+//     func (t *myTracer) Inject(sc opentracing.SpanContext, format interface{}, carrier interface{}) error {
+//         ...
+//         return jaeger.Inject(jaeger.Context{TraceIDLow: ..., SpanID: ..., Flags: jaeger.FlagSampled}, w)
+//     }
+//
+// Tracers whose SpanContext is an opentracing.BasicSpanContext can convert
+// with FromBasic and Context.ToBasic.
+//
+// The span context travels in the "uber-trace-id" header and every baggage
+// item in an "uberctx-<key>" header. Extract also understands the
+// "jaeger-debug-id" and "jaeger-baggage" headers Jaeger clients accept from
+// non-instrumented callers such as curl.
+package jaeger
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+// Header names and prefixes used by the Jaeger format. Lookups are
+// case-insensitive.
+const (
+	TraceContextHeader = "uber-trace-id"
+	BaggagePrefix      = "uberctx-"
+	DebugIDHeader      = "jaeger-debug-id"
+	BaggageHeader      = "jaeger-baggage"
+)
+
+// Flags is the flags byte of the uber-trace-id header.
+type Flags byte
+
+const (
+	// FlagSampled means the trace is sampled.
+	FlagSampled Flags = 1 << 0
+	// FlagDebug means the trace was force-sampled, e.g. for debugging.
+	FlagDebug Flags = 1 << 1
+	// FlagFirehose means the trace is in firehose mode.
+	FlagFirehose Flags = 1 << 3
+)
+
+// Context holds the fields of a Jaeger propagated span context. A Context
+// without a trace ID carries only baggage and/or a debug ID, which Jaeger
+// clients use to start a new trace.
+type Context struct {
+	// TraceIDHigh is the high 64 bits of a 128-bit trace ID, 0 for 64-bit
+	// trace IDs.
+	TraceIDHigh uint64
+	TraceIDLow  uint64
+	SpanID      uint64
+	// ParentSpanID is deprecated in the Jaeger format and usually 0.
+	ParentSpanID uint64
+	Flags        Flags
+	// DebugID is the value of the jaeger-debug-id header. It is only read by
+	// Extract, never written by Inject.
+	DebugID string
+	Baggage map[string]string
+}
+
+// FromBasic returns the Jaeger context of sc, with FlagSampled set if sc is
+// sampled. The baggage map is shared with sc.
+func FromBasic(sc opentracing.BasicSpanContext) Context {
+	c := Context{TraceIDHigh: sc.TraceIDHigh, TraceIDLow: sc.TraceID, SpanID: sc.SpanID, Baggage: sc.Baggage}
+	if sc.Sampled {
+		c.Flags = FlagSampled
+	}
+	return c
+}
+
+// ToBasic returns c as an opentracing.BasicSpanContext, sampled if
+// FlagSampled is set. ParentSpanID, the other flags and DebugID have no
+// counterpart and are dropped; the baggage map is shared with c.
+func (c Context) ToBasic() opentracing.BasicSpanContext {
+	return opentracing.BasicSpanContext{
+		TraceID:     c.TraceIDLow,
+		TraceIDHigh: c.TraceIDHigh,
+		SpanID:      c.SpanID,
+		Sampled:     c.IsSampled(),
+		Baggage:     c.Baggage,
+	}
+}
+
+// IsSampled returns whether FlagSampled is set.
+func (c Context) IsSampled() bool {
+	return c.Flags&FlagSampled != 0
+}
+
+// IsDebug returns whether FlagDebug is set.
+func (c Context) IsDebug() bool {
+	return c.Flags&FlagDebug != 0
+}
+
+// String returns the value of the uber-trace-id header for c:
+//
+//     {trace-id}:{span-id}:{parent-span-id}:{flags}
+//
+// with all parts in lowercase hex without leading zeros.
+func (c Context) String() string {
+	traceID := strconv.FormatUint(c.TraceIDLow, 16)
+	if c.TraceIDHigh != 0 {
+		traceID = fmt.Sprintf("%x%016x", c.TraceIDHigh, c.TraceIDLow)
+	}
+	return fmt.Sprintf("%s:%x:%x:%x", traceID, c.SpanID, c.ParentSpanID, byte(c.Flags))
+}
+
+// Inject writes c to w. It returns an error wrapping
+// opentracing.ErrInvalidSpanContext if c has no trace ID or span ID.
+// Baggage values are URL-escaped, as Jaeger clients expect.
+func Inject(c Context, w opentracing.TextMapWriter) error {
+	if c.TraceIDHigh == 0 && c.TraceIDLow == 0 || c.SpanID == 0 {
+		return fmt.Errorf("%w: jaeger: trace id and span id must not be zero", opentracing.ErrInvalidSpanContext)
+	}
+	w.Set(TraceContextHeader, c.String())
+	for k, v := range c.Baggage {
+		w.Set(BaggagePrefix+k, url.QueryEscape(v))
+	}
+	return nil
+}
+
+// Extract reads a Jaeger context from r. Baggage keys are lowercased, since
+// HTTP headers do not preserve their case; items from uberctx- headers take
+// precedence over the jaeger-baggage header.
+//
+// It returns opentracing.ErrSpanContextNotFound if there are no Jaeger
+// headers at all, and an error wrapping opentracing.ErrSpanContextCorrupted
+// if they are malformed.
+func Extract(r opentracing.TextMapReader) (Context, error) {
+	var c Context
+	var found bool
+	var headerBaggage string
+	err := r.ForeachKey(func(key, val string) error {
+		switch k := strings.ToLower(key); {
+		case k == TraceContextHeader:
+			unescaped, err := url.QueryUnescape(val)
+			if err != nil {
+				return corrupted("invalid %s %q", TraceContextHeader, val)
+			}
+			baggage, debugID := c.Baggage, c.DebugID
+			if c, err = Parse(unescaped); err != nil {
+				return err
+			}
+			c.Baggage, c.DebugID = baggage, debugID
+			found = true
+		case strings.HasPrefix(k, BaggagePrefix):
+			v, err := url.QueryUnescape(val)
+			if err != nil {
+				return corrupted("invalid baggage value in %q", key)
+			}
+			c.setBaggage(k[len(BaggagePrefix):], v)
+			found = true
+		case k == DebugIDHeader:
+			c.DebugID = val
+			found = true
+		case k == BaggageHeader:
+			headerBaggage = val
+			found = true
+		}
+		return nil
+	})
+	if err != nil {
+		return Context{}, err
+	}
+	if !found {
+		return Context{}, opentracing.ErrSpanContextNotFound
+	}
+	for _, item := range strings.Split(headerBaggage, ",") {
+		kv := strings.SplitN(strings.TrimSpace(item), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		if k := strings.ToLower(strings.TrimSpace(kv[0])); c.Baggage[k] == "" {
+			c.setBaggage(k, strings.TrimSpace(kv[1]))
+		}
+	}
+	return c, nil
+}
+
+func (c *Context) setBaggage(k, v string) {
+	if c.Baggage == nil {
+		c.Baggage = make(map[string]string)
+	}
+	c.Baggage[k] = v
+}
+
+// Parse parses the value of an uber-trace-id header. Errors wrap
+// opentracing.ErrSpanContextCorrupted.
+func Parse(v string) (Context, error) {
+	parts := strings.Split(v, ":")
+	if len(parts) != 4 {
+		return Context{}, corrupted("malformed %s %q", TraceContextHeader, v)
+	}
+	var c Context
+	traceID := parts[0]
+	if len(traceID) == 0 || len(traceID) > 32 {
+		return Context{}, corrupted("invalid trace id %q", traceID)
+	}
+	var err error
+	if len(traceID) > 16 {
+		if c.TraceIDHigh, err = strconv.ParseUint(traceID[:len(traceID)-16], 16, 64); err != nil {
+			return Context{}, corrupted("invalid trace id %q", traceID)
+		}
+		traceID = traceID[len(traceID)-16:]
+	}
+	if c.TraceIDLow, err = strconv.ParseUint(traceID, 16, 64); err != nil {
+		return Context{}, corrupted("invalid trace id %q", parts[0])
+	}
+	if c.SpanID, err = strconv.ParseUint(parts[1], 16, 64); err != nil {
+		return Context{}, corrupted("invalid span id %q", parts[1])
+	}
+	if c.ParentSpanID, err = strconv.ParseUint(parts[2], 16, 64); err != nil {
+		return Context{}, corrupted("invalid parent span id %q", parts[2])
+	}
+	flags, err := strconv.ParseUint(parts[3], 16, 8)
+	if err != nil {
+		return Context{}, corrupted("invalid flags %q", parts[3])
+	}
+	c.Flags = Flags(flags)
+	if c.TraceIDHigh == 0 && c.TraceIDLow == 0 || c.SpanID == 0 {
+		return Context{}, corrupted("trace id and span id must not be zero in %q", v)
+	}
+	return c, nil
+}
+
+func corrupted(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: jaeger: %s", opentracing.ErrSpanContextCorrupted, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,87 @@
+package jaeger
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+func TestRoundTrip(t *testing.T) {
+	for _, c := range []Context{
+		{TraceIDLow: 0x463ac35c9f6413ad, SpanID: 0xa2fb4a1d1a96d312, Flags: FlagSampled},
+		{TraceIDHigh: 0x463ac35c9f6413ad, TraceIDLow: 0x48485a3953bb6124, SpanID: 1, ParentSpanID: 2, Flags: FlagSampled | FlagDebug},
+		{TraceIDLow: 1, SpanID: 2, Baggage: map[string]string{"tenant": "acme corp"}},
+	} {
+		h := http.Header{}
+		require.NoError(t, Inject(c, opentracing.HTTPHeadersCarrier(h)))
+		got, err := Extract(opentracing.HTTPHeadersCarrier(h))
+		require.NoError(t, err)
+		assert.Equal(t, c, got)
+	}
+}
+
+func TestString(t *testing.T) {
+	c := Context{TraceIDLow: 0xabc, SpanID: 0x1f, Flags: FlagSampled}
+	assert.Equal(t, "abc:1f:0:1", c.String())
+	assert.True(t, c.IsSampled())
+	assert.False(t, c.IsDebug())
+
+	c.TraceIDHigh = 1
+	assert.Equal(t, "10000000000000abc:1f:0:1", c.String())
+}
+
+func TestExtract(t *testing.T) {
+	c, err := Extract(opentracing.TextMapCarrier{
+		"Uber-Trace-Id":   "abc%3A1f%3A0%3A3",
+		"uberctx-User":    "alice",
+		"jaeger-baggage":  "user=bob, region = eu,malformed",
+		"jaeger-debug-id": "curl-1",
+		"unrelated":       "x",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, Context{
+		TraceIDLow: 0xabc,
+		SpanID:     0x1f,
+		Flags:      FlagSampled | FlagDebug,
+		DebugID:    "curl-1",
+		Baggage:    map[string]string{"user": "alice", "region": "eu"},
+	}, c)
+
+	c, err = Extract(opentracing.TextMapCarrier{"jaeger-debug-id": "curl-1"})
+	require.NoError(t, err)
+	assert.Equal(t, Context{DebugID: "curl-1"}, c)
+}
+
+func TestExtractErrors(t *testing.T) {
+	_, err := Extract(opentracing.TextMapCarrier{"other": "x"})
+	assert.Equal(t, opentracing.ErrSpanContextNotFound, err)
+
+	for _, v := range []string{
+		"abc:1f:0",
+		"xyz:1f:0:1",
+		"abc:1f:0:100",
+		"0:1f:0:1",
+		"abc:0:0:1",
+		"123456789012345678901234567890123:1:0:1",
+		"%zz",
+	} {
+		_, err := Extract(opentracing.TextMapCarrier{TraceContextHeader: v})
+		assert.ErrorIs(t, err, opentracing.ErrSpanContextCorrupted, v)
+	}
+
+	assert.ErrorIs(t, Inject(Context{SpanID: 1}, opentracing.TextMapCarrier{}), opentracing.ErrInvalidSpanContext)
+}
+
+func TestBasicConversion(t *testing.T) {
+	sc := opentracing.BasicSpanContext{TraceIDHigh: 1, TraceID: 2, SpanID: 3, Sampled: true, Baggage: map[string]string{"k": "v"}}
+	c := FromBasic(sc)
+	assert.Equal(t, Context{TraceIDHigh: 1, TraceIDLow: 2, SpanID: 3, Flags: FlagSampled, Baggage: map[string]string{"k": "v"}}, c)
+	assert.Equal(t, sc, c.ToBasic())
+
+	c = Context{TraceIDLow: 2, SpanID: 3, ParentSpanID: 4, Flags: FlagDebug, DebugID: "d"}
+	assert.Equal(t, opentracing.BasicSpanContext{TraceID: 2, SpanID: 3}, c.ToBasic())
+}
@@ -0,0 +1,141 @@
+// Package recorder defines the contract between a tracer implementation and
+// the code that exports its finished spans: the tracer converts each finished
+// span into a RawSpan and hands it to a SpanRecorder.
+//
+// InMemoryRecorder and ChannelRecorder are ready-made recorders, for tests
+// and for batching exporters running in their own goroutine respectively.
+package recorder
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+// RawSpan is the data of a finished span, independent of the tracer that
+// recorded it.
+type RawSpan struct {
+	// Context is the span's own context: its trace and span IDs, sampling
+	// state and baggage at the time the span finished.
+	Context opentracing.BasicSpanContext
+
+	// ParentSpanID is the span ID of the parent (the first ChildOf reference,
+	// or the first FollowsFrom reference), or 0 for a root span.
+	ParentSpanID uint64
+
+	// Operation is the operation name at the time the span finished.
+	Operation string
+
+	// Start is the span's start time and Duration the time until it finished.
+	Start    time.Time
+	Duration time.Duration
+
+	// Tags are the span's tags, set at start and through SetTag.
+	Tags opentracing.Tags
+
+	// Logs are the span's log records in the order they were recorded,
+	// including the ones passed to FinishWithOptions.
+	Logs []opentracing.LogRecord
+
+	// References are the references the span was started with.
+	References []opentracing.SpanReference
+}
+
+// SpanRecorder receives the finished spans of a tracer. RecordSpan may be
+// called concurrently from many goroutines and should return quickly: it is
+// called from Span.Finish.
+type SpanRecorder interface {
+	RecordSpan(span RawSpan)
+}
+
+// SpanRecorderFunc adapts a function to a SpanRecorder.
+type SpanRecorderFunc func(span RawSpan)
+
+// RecordSpan implements SpanRecorder.
+func (f SpanRecorderFunc) RecordSpan(span RawSpan) {
+	f(span)
+}
+
+// InMemoryRecorder is a SpanRecorder that keeps all recorded spans in
+// memory, mainly for tests. The zero value is ready to use.
+type InMemoryRecorder struct {
+	mu    sync.Mutex
+	spans []RawSpan
+}
+
+// NewInMemoryRecorder returns an empty InMemoryRecorder.
+func NewInMemoryRecorder() *InMemoryRecorder {
+	return &InMemoryRecorder{}
+}
+
+// RecordSpan implements SpanRecorder.
+func (r *InMemoryRecorder) RecordSpan(span RawSpan) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, span)
+}
+
+// GetSpans returns a copy of the recorded spans, in the order they were
+// recorded.
+func (r *InMemoryRecorder) GetSpans() []RawSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	spans := make([]RawSpan, len(r.spans))
+	copy(spans, r.spans)
+	return spans
+}
+
+// Reset discards the recorded spans.
+func (r *InMemoryRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = nil
+}
+
+// ChannelRecorder is a SpanRecorder that sends the recorded spans to a
+// buffered channel, from which an exporter can read them in batches in its
+// own goroutine:
+//
+//     rec := recorder.NewChannelRecorder(1024)
+//     go func() {
+//         for span := range rec.Spans() {
+//             batch = append(batch, span)
+//             ...
+//         }
+//     }()
+//
+// RecordSpan never blocks: spans recorded while the buffer is full are
+// dropped and counted, see Dropped.
+type ChannelRecorder struct {
+	// dropped is accessed atomically and must stay first so that it is
+	// 64-bit aligned on 32-bit platforms.
+	dropped uint64
+	spans   chan RawSpan
+}
+
+// NewChannelRecorder returns a ChannelRecorder buffering up to size spans.
+func NewChannelRecorder(size int) *ChannelRecorder {
+	return &ChannelRecorder{spans: make(chan RawSpan, size)}
+}
+
+// RecordSpan implements SpanRecorder.
+func (r *ChannelRecorder) RecordSpan(span RawSpan) {
+	select {
+	case r.spans <- span:
+	default:
+		atomic.AddUint64(&r.dropped, 1)
+	}
+}
+
+// Spans returns the channel the recorded spans are sent to. It is never
+// closed.
+func (r *ChannelRecorder) Spans() <-chan RawSpan {
+	return r.spans
+}
+
+// Dropped returns the number of spans dropped because the buffer was full.
+func (r *ChannelRecorder) Dropped() uint64 {
+	return atomic.LoadUint64(&r.dropped)
+}
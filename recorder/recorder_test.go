@@ -0,0 +1,58 @@
+package recorder
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+func rawSpan(op string) RawSpan {
+	return RawSpan{Context: opentracing.NewSpanContext(1, 2), Operation: op}
+}
+
+func TestInMemoryRecorder(t *testing.T) {
+	rec := NewInMemoryRecorder()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec.RecordSpan(rawSpan("op"))
+		}()
+	}
+	wg.Wait()
+
+	spans := rec.GetSpans()
+	assert.Len(t, spans, 10)
+	spans[0].Operation = "modified"
+	assert.Equal(t, "op", rec.GetSpans()[0].Operation, "GetSpans must return a copy")
+
+	rec.Reset()
+	assert.Empty(t, rec.GetSpans())
+}
+
+func TestChannelRecorder(t *testing.T) {
+	rec := NewChannelRecorder(2)
+	rec.RecordSpan(rawSpan("a"))
+	rec.RecordSpan(rawSpan("b"))
+	rec.RecordSpan(rawSpan("c"))
+
+	assert.Equal(t, uint64(1), rec.Dropped())
+	assert.Equal(t, "a", (<-rec.Spans()).Operation)
+	assert.Equal(t, "b", (<-rec.Spans()).Operation)
+
+	rec.RecordSpan(rawSpan("d"))
+	assert.Equal(t, "d", (<-rec.Spans()).Operation)
+}
+
+func TestSpanRecorderFunc(t *testing.T) {
+	var got []string
+	var rec SpanRecorder = SpanRecorderFunc(func(span RawSpan) {
+		got = append(got, span.Operation)
+	})
+	rec.RecordSpan(rawSpan("x"))
+	assert.Equal(t, []string{"x"}, got)
+}
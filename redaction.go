@@ -0,0 +1,317 @@
+package opentracing
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/opentracing/opentracing-go/log"
+)
+
+// RedactedValue 是 KeyListRedactor 用于替换敏感值的字符串。
+const RedactedValue = "[REDACTED]"
+
+// Redactor 决定 WrapTracerWithRedaction 如何处理 tag、日志字段和携带数据(baggage)。
+type Redactor interface {
+	// RedactTag 返回 key 对应的 tag 应该被记录的值；如果返回的 bool 为 false，该 tag 会被完全丢弃。
+	// 携带数据也使用该方法处理，返回值会通过 fmt.Sprint 转换为字符串。
+	RedactTag(key string, value interface{}) (interface{}, bool)
+
+	// RedactLogField 返回应该被记录的日志字段；如果返回的 bool 为 false，该字段会被完全丢弃。
+	RedactLogField(f log.Field) (log.Field, bool)
+}
+
+// KeyListRedactor 是一个 Redactor，它把指定的键（大小写不敏感）的值替换为 RedactedValue，
+// 其他的值保持不变。
+type KeyListRedactor struct {
+	rules ruleRedactor
+}
+
+// NewKeyListRedactor 返回一个对 keys 中的键（大小写不敏感）进行脱敏的 KeyListRedactor，
+// 与 NewRuleRedactor(RedactKeys(keys...)) 的行为相同。
+func NewKeyListRedactor(keys ...string) *KeyListRedactor {
+	return &KeyListRedactor{rules: ruleRedactor{RedactKeys(keys...)}}
+}
+
+// RedactTag 实现 Redactor 接口。
+func (r *KeyListRedactor) RedactTag(key string, value interface{}) (interface{}, bool) {
+	return r.rules.RedactTag(key, value)
+}
+
+// RedactLogField 实现 Redactor 接口。
+//
+// 对于 log.Lazy 创建的字段，键只有在调用 LazyLogger 时才能知道，因此会在调用时进行脱敏。
+func (r *KeyListRedactor) RedactLogField(f log.Field) (log.Field, bool) {
+	return r.rules.RedactLogField(f)
+}
+
+// redactingEncoder 把敏感键的值替换为 RedactedValue 后传递给底层的 Encoder。
+type redactingEncoder struct {
+	log.Encoder
+	redactor ruleRedactor
+}
+
+func (e redactingEncoder) emit(key string, emit func()) {
+	if e.redactor.sensitive(key) {
+		e.Encoder.EmitString(key, RedactedValue)
+		return
+	}
+	emit()
+}
+
+func (e redactingEncoder) EmitString(key, value string) {
+	e.emit(key, func() { e.Encoder.EmitString(key, e.redactor.mask(value)) })
+}
+func (e redactingEncoder) EmitBool(key string, value bool) {
+	e.emit(key, func() { e.Encoder.EmitBool(key, value) })
+}
+func (e redactingEncoder) EmitInt(key string, value int) {
+	e.emit(key, func() { e.Encoder.EmitInt(key, value) })
+}
+func (e redactingEncoder) EmitInt32(key string, value int32) {
+	e.emit(key, func() { e.Encoder.EmitInt32(key, value) })
+}
+func (e redactingEncoder) EmitInt64(key string, value int64) {
+	e.emit(key, func() { e.Encoder.EmitInt64(key, value) })
+}
+func (e redactingEncoder) EmitUint32(key string, value uint32) {
+	e.emit(key, func() { e.Encoder.EmitUint32(key, value) })
+}
+func (e redactingEncoder) EmitUint64(key string, value uint64) {
+	e.emit(key, func() { e.Encoder.EmitUint64(key, value) })
+}
+func (e redactingEncoder) EmitFloat32(key string, value float32) {
+	e.emit(key, func() { e.Encoder.EmitFloat32(key, value) })
+}
+func (e redactingEncoder) EmitFloat64(key string, value float64) {
+	e.emit(key, func() { e.Encoder.EmitFloat64(key, value) })
+}
+func (e redactingEncoder) EmitObject(key string, value interface{}) {
+	e.emit(key, func() { e.Encoder.EmitObject(key, value) })
+}
+func (e redactingEncoder) EmitLazyLogger(value log.LazyLogger) {
+	value(e)
+}
+
+// RedactRule 是 NewRuleRedactor 使用的一条脱敏规则，通过 RedactKeys、RedactKeysMatching
+// 或 RedactValuesMatching 创建。
+type RedactRule struct {
+	keys         map[string]struct{}
+	keyPattern   *regexp.Regexp
+	valuePattern *regexp.Regexp
+}
+
+// RedactKeys 返回一条规则，它把指定的键（大小写不敏感）的值替换为 RedactedValue，
+// 例如 "authorization" 或 "password"。
+func RedactKeys(keys ...string) RedactRule {
+	r := RedactRule{keys: make(map[string]struct{}, len(keys))}
+	for _, k := range keys {
+		r.keys[strings.ToLower(k)] = struct{}{}
+	}
+	return r
+}
+
+// RedactKeysMatching 返回一条规则，它把键匹配 re 的值替换为 RedactedValue。
+func RedactKeysMatching(re *regexp.Regexp) RedactRule {
+	return RedactRule{keyPattern: re}
+}
+
+// RedactValuesMatching 返回一条规则，它把字符串值中匹配 re 的部分替换为 RedactedValue，
+// 例如信用卡号或 "Bearer xxx" 形式的认证信息。
+//
+// 只有字符串类型的值会被检查，其他类型的值保持不变。
+func RedactValuesMatching(re *regexp.Regexp) RedactRule {
+	return RedactRule{valuePattern: re}
+}
+
+func (r RedactRule) sensitive(key string) bool {
+	if _, ok := r.keys[strings.ToLower(key)]; ok {
+		return true
+	}
+	return r.keyPattern != nil && r.keyPattern.MatchString(key)
+}
+
+// NewRuleRedactor 返回一个按照 rules 对 tag、日志字段和携带数据(baggage)的值进行脱敏的 Redactor，
+// 任意一条规则命中即进行替换。它通常与 WrapTracerWithRedaction 一起使用：
+//
+//     tracer := opentracing.WrapTracerWithRedaction(tracer, opentracing.NewRuleRedactor(
+//         opentracing.RedactKeys("authorization", "password"),
+//         opentracing.RedactValuesMatching(regexp.MustCompile(`\b\d(?:[ -]?\d){12,15}\b`)),
+//     ))
+//
+// 对于 log.Lazy 创建的字段，会在调用 LazyLogger 时进行脱敏。
+func NewRuleRedactor(rules ...RedactRule) Redactor {
+	return ruleRedactor(rules)
+}
+
+// ruleRedactor 是由一组 RedactRule 组成的 Redactor。
+type ruleRedactor []RedactRule
+
+func (r ruleRedactor) sensitive(key string) bool {
+	for _, rule := range r {
+		if rule.sensitive(key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r ruleRedactor) mask(value string) string {
+	for _, rule := range r {
+		if rule.valuePattern != nil {
+			value = rule.valuePattern.ReplaceAllLiteralString(value, RedactedValue)
+		}
+	}
+	return value
+}
+
+func (r ruleRedactor) RedactTag(key string, value interface{}) (interface{}, bool) {
+	if r.sensitive(key) {
+		return RedactedValue, true
+	}
+	if s, ok := value.(string); ok {
+		return r.mask(s), true
+	}
+	return value, true
+}
+
+func (r ruleRedactor) RedactLogField(f log.Field) (log.Field, bool) {
+	if ll, ok := f.Value().(log.LazyLogger); ok {
+		return log.Lazy(func(enc log.Encoder) {
+			ll(redactingEncoder{Encoder: enc, redactor: r})
+		}), true
+	}
+	if r.sensitive(f.Key()) {
+		return log.String(f.Key(), RedactedValue), true
+	}
+	if s, ok := f.Value().(string); ok {
+		if masked := r.mask(s); masked != s {
+			return log.String(f.Key(), masked), true
+		}
+	}
+	return f, true
+}
+
+// WrapTracerWithRedaction 返回一个包装了 tracer 的 Tracer，它在敏感数据到达链路追踪的后端之前用 redactor 进行处理。
+// 脱敏会作用于 StartSpan 选项中的tag和携带数据(baggage)（见 WithBaggage）、SetTag、SetBaggageItem、LogFields、LogKV，
+// 以及 FinishWithOptions 中的 LogRecords 和（已废弃的） BulkLogData。
+//
+// 已废弃的 LogEvent、LogEventWithPayload 和 Log 会被转换为 LogFields 调用（Log 的时间戳会被丢弃）。
+//
+// Inject 和 Extract 会直接委托给 tracer。
+func WrapTracerWithRedaction(tracer Tracer, redactor Redactor) Tracer {
+	return &redactingTracer{tracer: tracer, redactor: redactor}
+}
+
+type redactingTracer struct {
+	tracer   Tracer
+	redactor Redactor
+}
+
+func (t *redactingTracer) StartSpan(operationName string, opts ...StartSpanOption) Span {
+	sso := NewStartSpanOptions(opts...)
+	if original := sso.TagsMap(); original != nil {
+		tags := make(map[string]interface{}, len(original))
+		for k, v := range original {
+			if v, ok := t.redactor.RedactTag(k, v); ok {
+				tags[k] = v
+			}
+		}
+		sso.Tags = tags
+	}
+	if sso.Baggage != nil {
+		// WithBaggage 中的值与 SetBaggageItem 一样需要脱敏，不能把原始的选项传递下去
+		baggage := make(map[string]string, len(sso.Baggage))
+		for k, v := range sso.Baggage {
+			if v, ok := t.redactor.RedactTag(k, v); ok {
+				baggage[k] = fmt.Sprint(v)
+			}
+		}
+		sso.Baggage = baggage
+	}
+	return &redactingSpan{Span: t.tracer.StartSpan(operationName, appliedStartSpanOptions(sso)), tracer: t}
+}
+
+func (t *redactingTracer) Inject(sc SpanContext, format interface{}, carrier interface{}) error {
+	return t.tracer.Inject(sc, format, carrier)
+}
+
+func (t *redactingTracer) Extract(format interface{}, carrier interface{}) (SpanContext, error) {
+	return t.tracer.Extract(format, carrier)
+}
+
+func (t *redactingTracer) redactFields(fields []log.Field) []log.Field {
+	redacted := make([]log.Field, 0, len(fields))
+	for _, f := range fields {
+		if f, ok := t.redactor.RedactLogField(f); ok {
+			redacted = append(redacted, f)
+		}
+	}
+	return redacted
+}
+
+type redactingSpan struct {
+	Span
+	tracer *redactingTracer
+}
+
+func (s *redactingSpan) Tracer() Tracer { return s.tracer }
+
+func (s *redactingSpan) SetOperationName(operationName string) Span {
+	s.Span.SetOperationName(operationName)
+	return s
+}
+
+func (s *redactingSpan) SetTag(key string, value interface{}) Span {
+	if value, ok := s.tracer.redactor.RedactTag(key, value); ok {
+		s.Span.SetTag(key, value)
+	}
+	return s
+}
+
+func (s *redactingSpan) SetBaggageItem(restrictedKey, value string) Span {
+	if v, ok := s.tracer.redactor.RedactTag(restrictedKey, value); ok {
+		s.Span.SetBaggageItem(restrictedKey, fmt.Sprint(v))
+	}
+	return s
+}
+
+func (s *redactingSpan) LogFields(fields ...log.Field) {
+	s.Span.LogFields(s.tracer.redactFields(fields)...)
+}
+
+func (s *redactingSpan) LogKV(alternatingKeyValues ...interface{}) {
+	fields, err := log.InterleavedKVToFields(alternatingKeyValues...)
+	if err != nil {
+		// 不能把无法解析的键值对原样传递下去，否则可能泄露敏感数据
+		s.Span.LogFields(log.Error(err), log.String("function", "LogKV"))
+		return
+	}
+	s.LogFields(fields...)
+}
+
+func (s *redactingSpan) LogEvent(event string) {
+	s.LogFields(log.String("event", event))
+}
+
+func (s *redactingSpan) LogEventWithPayload(event string, payload interface{}) {
+	s.LogFields(log.String("event", event), log.Object("payload", payload))
+}
+
+func (s *redactingSpan) Log(data LogData) {
+	s.LogFields(data.ToLogRecord().Fields...)
+}
+
+func (s *redactingSpan) FinishWithOptions(opts FinishOptions) {
+	records := make([]LogRecord, 0, len(opts.LogRecords)+len(opts.BulkLogData))
+	for _, lr := range opts.LogRecords {
+		records = append(records, LogRecord{Timestamp: lr.Timestamp, Fields: s.tracer.redactFields(lr.Fields)})
+	}
+	for _, ld := range opts.BulkLogData {
+		lr := ld.ToLogRecord()
+		records = append(records, LogRecord{Timestamp: lr.Timestamp, Fields: s.tracer.redactFields(lr.Fields)})
+	}
+	opts.LogRecords = records
+	opts.BulkLogData = nil
+	s.Span.FinishWithOptions(opts)
+}
@@ -0,0 +1,142 @@
+package opentracing_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+func logValues(lr mocktracer.MockLogRecord) map[string]string {
+	values := make(map[string]string, len(lr.Fields))
+	for _, f := range lr.Fields {
+		values[f.Key] = f.ValueString
+	}
+	return values
+}
+
+func TestWrapTracerWithRedaction(t *testing.T) {
+	inner := mocktracer.New()
+	tracer := opentracing.WrapTracerWithRedaction(inner, opentracing.NewKeyListRedactor("Password", "token"))
+
+	span := tracer.StartSpan("login", opentracing.Tags{"password": "hunter2", "user": "alice"})
+	assert.Equal(t, tracer, span.Tracer())
+	assert.Equal(t, span, span.SetTag("TOKEN", "abc"))
+	span.SetBaggageItem("token", "abc")
+	span.LogFields(log.String("password", "hunter2"), log.Int("attempt", 1))
+	span.LogKV("token", "abc", "event", "ok")
+	span.LogKV("odd")
+	span.LogFields(log.Lazy(func(enc log.Encoder) { enc.EmitString("password", "hunter2") }))
+	now := time.Now()
+	span.FinishWithOptions(opentracing.FinishOptions{
+		LogRecords:  []opentracing.LogRecord{{Timestamp: now, Fields: []log.Field{log.String("token", "abc")}}},
+		BulkLogData: []opentracing.LogData{{Timestamp: now, Event: "done", Payload: "ok"}},
+	})
+
+	spans := inner.FinishedSpans()
+	require.Len(t, spans, 1)
+	sp := spans[0]
+	assert.Equal(t, map[string]interface{}{
+		"password": opentracing.RedactedValue,
+		"user":     "alice",
+		"TOKEN":    opentracing.RedactedValue,
+	}, sp.Tags())
+	assert.Equal(t, opentracing.RedactedValue, sp.BaggageItem("token"))
+
+	logs := sp.Logs()
+	require.Len(t, logs, 6)
+	assert.Equal(t, map[string]string{"password": opentracing.RedactedValue, "attempt": "1"}, logValues(logs[0]))
+	assert.Equal(t, map[string]string{"token": opentracing.RedactedValue, "event": "ok"}, logValues(logs[1]))
+	assert.NotContains(t, logValues(logs[2]), "odd")
+	assert.Equal(t, map[string]string{"password": opentracing.RedactedValue}, logValues(logs[3]))
+	assert.Equal(t, map[string]string{"token": opentracing.RedactedValue}, logValues(logs[4]))
+	assert.Equal(t, map[string]string{"event": "done", "payload": "ok"}, logValues(logs[5]))
+}
+
+type dropRedactor struct{}
+
+func (dropRedactor) RedactTag(key string, value interface{}) (interface{}, bool) {
+	return value, key != "secret"
+}
+
+func (dropRedactor) RedactLogField(f log.Field) (log.Field, bool) {
+	return f, f.Key() != "secret"
+}
+
+func TestWrapTracerWithRedactionDrop(t *testing.T) {
+	inner := mocktracer.New()
+	tracer := opentracing.WrapTracerWithRedaction(inner, dropRedactor{})
+
+	span := tracer.StartSpan("op", opentracing.Tag{Key: "secret", Value: 1})
+	span.SetTag("secret", 2)
+	span.LogFields(log.String("secret", "x"), log.String("kept", "y"))
+	span.Finish()
+
+	sp := inner.FinishedSpans()[0]
+	assert.Empty(t, sp.Tags())
+	require.Len(t, sp.Logs(), 1)
+	assert.Equal(t, map[string]string{"kept": "y"}, logValues(sp.Logs()[0]))
+}
+
+func TestNewRuleRedactor(t *testing.T) {
+	inner := mocktracer.New()
+	tracer := opentracing.WrapTracerWithRedaction(inner, opentracing.NewRuleRedactor(
+		opentracing.RedactKeys("Authorization"),
+		opentracing.RedactKeysMatching(regexp.MustCompile(`^x-secret-`)),
+		opentracing.RedactValuesMatching(regexp.MustCompile(`\b\d(?:[ -]?\d){12,15}\b`)),
+	))
+
+	span := tracer.StartSpan("pay", opentracing.Tags{"authorization": "Bearer abc", "amount": 10})
+	span.SetTag("x-secret-id", "s3")
+	span.SetTag("note", "card 4111 1111 1111 1111 ok")
+	span.SetBaggageItem("card", "4111111111111111")
+	span.LogFields(log.String("msg", "paid with 4111-1111-1111-1111"), log.Int("x-secret-n", 7))
+	span.LogFields(
+		log.Lazy(func(enc log.Encoder) { enc.EmitString("AUTHORIZATION", "Bearer abc") }),
+		log.Lazy(func(enc log.Encoder) { enc.EmitString("msg", "4111111111111111") }),
+	)
+	span.Finish()
+
+	sp := inner.FinishedSpans()[0]
+	assert.Equal(t, map[string]interface{}{
+		"authorization": opentracing.RedactedValue,
+		"amount":        10,
+		"x-secret-id":   opentracing.RedactedValue,
+		"note":          "card " + opentracing.RedactedValue + " ok",
+	}, sp.Tags())
+	assert.Equal(t, opentracing.RedactedValue, sp.BaggageItem("card"))
+
+	logs := sp.Logs()
+	require.Len(t, logs, 2)
+	assert.Equal(t, map[string]string{
+		"msg":        "paid with " + opentracing.RedactedValue,
+		"x-secret-n": opentracing.RedactedValue,
+	}, logValues(logs[0]))
+	assert.Equal(t, map[string]string{
+		"AUTHORIZATION": opentracing.RedactedValue,
+		"msg":           opentracing.RedactedValue,
+	}, logValues(logs[1]))
+}
+
+func TestWrapTracerWithRedactionStartBaggage(t *testing.T) {
+	inner := mocktracer.New()
+	tracer := opentracing.WrapTracerWithRedaction(inner, opentracing.NewKeyListRedactor("password"))
+
+	span := tracer.StartSpan("login", opentracing.WithBaggage(map[string]string{"password": "hunter2", "user": "alice"}))
+	span.Finish()
+
+	sp := inner.FinishedSpans()[0]
+	assert.Equal(t, map[string]string{"password": opentracing.RedactedValue, "user": "alice"}, sp.SpanContext.Baggage)
+
+	inner.Reset()
+	span = opentracing.WrapTracerWithRedaction(inner, dropRedactor{}).StartSpan("op",
+		opentracing.WithBaggage(map[string]string{"secret": "s", "kept": "k"}))
+	span.Finish()
+	assert.Equal(t, map[string]string{"kept": "k"}, inner.FinishedSpans()[0].SpanContext.Baggage)
+}
@@ -0,0 +1,71 @@
+package opentracing
+
+import "github.com/opentracing/opentracing-go/log"
+
+// SampledSpanContext 是一个可选的接口，SpanContext 的实现可以通过它报告 Span 是否被采样。
+// 见 IsSampled。
+type SampledSpanContext interface {
+	SpanContext
+
+	// IsSampled 返回该 SpanContext 所属的 Span 是否被采样
+	IsSampled() bool
+}
+
+// SamplingDecisionSpanContext 是一个可选的接口，适用于采样决定可能尚未作出的 SpanContext
+// （例如延迟采样的 Tracer）。Sampled 返回 Span 是否被采样；known 为 false 表示 Tracer 还没有作出决定，
+// 此时 sampled 没有意义。
+type SamplingDecisionSpanContext interface {
+	SpanContext
+
+	// Sampled 返回该 SpanContext 所属的 Span 是否被采样，以及采样决定是否已经作出
+	Sampled() (sampled, known bool)
+}
+
+// IsSampled 返回 sc 是否被采样。sc 实现了 SamplingDecisionSpanContext 时直接使用它的结果；
+// 否则只有当 sc 实现了 SampledSpanContext 时 known 才为 true，
+// 否则采样状态是未知的，sampled 为 false。
+func IsSampled(sc SpanContext) (sampled, known bool) {
+	if s, ok := sc.(SamplingDecisionSpanContext); ok {
+		return s.Sampled()
+	}
+	if s, ok := sc.(SampledSpanContext); ok {
+		return s.IsSampled(), true
+	}
+	return false, false
+}
+
+// LogFieldsIfSampled 只在 span 没有确定未被采样时调用 span.LogFields(fields...)：
+// 即 IsSampled(span.Context()) 报告已被采样，或者采样状态未知（保守起见，此时仍然记录）。
+//
+// 与 log.Lazy 一起使用时，未被采样的 Span 可以完全省去日志值的计算。
+func LogFieldsIfSampled(span Span, fields ...log.Field) {
+	if sampled, known := IsSampled(span.Context()); known && !sampled {
+		return
+	}
+	span.LogFields(fields...)
+}
+
+// SamplingPriorityTagKey 是 OpenTracing 语义约定中表示采样优先级的 tag 的键，与 ext.SamplingPriority 相同。
+const SamplingPriorityTagKey = "sampling.priority"
+
+// SamplingPriority 返回一个 StartSpanOption，它以与 Tracer 实现无关的方式请求新Span的采样决定：
+//
+//   - priority 为 0 表示请求 Tracer 不要采样该链路；
+//   - priority 大于 0 表示请求 Tracer 强制采样该链路（例如调试用的链路），数值越大优先级越高。
+//
+// 该选项把 priority 作为`sampling.priority` tag（uint16 类型）添加到选项中，已经支持该语义约定的 Tracer 不需要任何修改；
+// Tracer 的实现可以通过 StartSpanOptions.SamplingPriority 读取它。这只是一个提示，Tracer 可以不遵从它。
+//
+//    span := tracer.StartSpan("checkout", opentracing.SamplingPriority(1))
+//
+func SamplingPriority(priority uint16) StartSpanOption {
+	return Tag{Key: SamplingPriorityTagKey, Value: priority}
+}
+
+// SamplingPriority 返回通过 SamplingPriority 选项（或者一个类型为 uint16 的`sampling.priority` tag）请求的采样优先级，
+// 如果没有请求，第二个返回值为 false。
+func (o StartSpanOptions) SamplingPriority() (uint16, bool) {
+	v, _ := o.tag(SamplingPriorityTagKey)
+	p, ok := v.(uint16)
+	return p, ok
+}
@@ -0,0 +1,96 @@
+package opentracing_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+// unknownSamplingSpan 的 SpanContext 没有实现 SampledSpanContext
+type unknownSamplingSpan struct {
+	*mocktracer.MockSpan
+}
+
+func (s unknownSamplingSpan) Context() opentracing.SpanContext {
+	return opentracing.ExportedTestTracer.StartSpan("x").Context()
+}
+
+func TestIsSampled(t *testing.T) {
+	sampled, known := opentracing.IsSampled(opentracing.NewSpanContext(1, 2))
+	assert.True(t, sampled)
+	assert.True(t, known)
+
+	sampled, known = opentracing.IsSampled(opentracing.BasicSpanContext{})
+	assert.False(t, sampled)
+	assert.True(t, known)
+
+	sampled, known = opentracing.IsSampled(opentracing.NoopTracer{}.StartSpan("x").Context())
+	assert.False(t, sampled)
+	assert.False(t, known)
+}
+
+func TestLogFieldsIfSampled(t *testing.T) {
+	tracer := mocktracer.New()
+
+	sampled := tracer.StartSpan("sampled").(*mocktracer.MockSpan)
+	opentracing.LogFieldsIfSampled(sampled, log.Event("e"))
+	assert.Len(t, sampled.Logs(), 1)
+
+	notSampled := tracer.StartSpan("not sampled").(*mocktracer.MockSpan)
+	ext.SamplingPriority.Set(notSampled, 0)
+	opentracing.LogFieldsIfSampled(notSampled, log.Lazy(func(log.Encoder) {
+		t.Error("the lazy logger of an unsampled span must not be called")
+	}))
+	assert.Len(t, notSampled.Logs(), 0)
+
+	unknown := unknownSamplingSpan{tracer.StartSpan("unknown").(*mocktracer.MockSpan)}
+	opentracing.LogFieldsIfSampled(unknown, log.Event("e"))
+	assert.Len(t, unknown.Logs(), 1)
+}
+
+func TestSamplingPriorityOption(t *testing.T) {
+	assert.Equal(t, string(ext.SamplingPriority), opentracing.SamplingPriorityTagKey)
+
+	opts := opentracing.NewStartSpanOptions(opentracing.SamplingPriority(2))
+	priority, ok := opts.SamplingPriority()
+	assert.True(t, ok)
+	assert.Equal(t, uint16(2), priority)
+
+	_, ok = opentracing.NewStartSpanOptions().SamplingPriority()
+	assert.False(t, ok)
+
+	tracer := mocktracer.New()
+	parent := tracer.StartSpan("parent", opentracing.SamplingPriority(0))
+	sampled, known := opentracing.IsSampled(parent.Context())
+	assert.False(t, sampled)
+	assert.True(t, known)
+	assert.Empty(t, parent.(*mocktracer.MockSpan).Tags())
+
+	child := tracer.StartSpan("child", opentracing.ChildOf(parent.Context()), opentracing.SamplingPriority(1))
+	sampled, _ = opentracing.IsSampled(child.Context())
+	assert.True(t, sampled)
+}
+
+type deferredSamplingContext struct {
+	opentracing.SpanContext
+	decided bool
+}
+
+func (c deferredSamplingContext) Sampled() (bool, bool) { return c.decided, c.decided }
+
+func TestIsSampledDecision(t *testing.T) {
+	base := opentracing.NoopTracer{}.StartSpan("x").Context()
+
+	sampled, known := opentracing.IsSampled(deferredSamplingContext{base, false})
+	assert.False(t, sampled)
+	assert.False(t, known)
+
+	sampled, known = opentracing.IsSampled(deferredSamplingContext{base, true})
+	assert.True(t, sampled)
+	assert.True(t, known)
+}
@@ -0,0 +1,75 @@
+package opentracing
+
+import "sync"
+
+// NewSharedSpan 返回一个包装了 span 的 Span，它可以被 refs 个持有者（例如扇出的多个 goroutine）共同使用：
+// 每个持有者用完后都调用一次 Finish（或 FinishWithOptions），只有最后一次调用才会真正结束 span。
+// refs 小于 1 时按 1 处理。
+//
+// 最后一次调用的 FinishOptions 决定 span 的结束时间；之前每次调用 FinishWithOptions 传入的 LogRecords
+// 都会被保留下来，与最后一次调用的 LogRecords 一起传递给 span。所有持有者都调用过 Finish 之后，再次调用会被忽略。
+// 其余方法（包括 Context() 和 Tracer()）都会原样委托给 span，并且可以被多个 goroutine 并发调用，
+// 只要 span 本身的实现是并发安全的。
+//
+//    shared := opentracing.NewSharedSpan(span, len(tasks))
+//    for _, task := range tasks {
+//        go func(task Task) {
+//            defer shared.Finish()
+//            shared.LogKV("task", task.Name)
+//            ...
+//        }(task)
+//    }
+//
+func NewSharedSpan(span Span, refs int) Span {
+	if refs < 1 {
+		refs = 1
+	}
+	return &sharedSpan{Span: span, refs: refs}
+}
+
+type sharedSpan struct {
+	Span
+
+	mu         sync.Mutex
+	refs       int
+	logRecords []LogRecord
+}
+
+func (s *sharedSpan) Finish() {
+	s.FinishWithOptions(FinishOptions{})
+}
+
+func (s *sharedSpan) FinishWithOptions(opts FinishOptions) {
+	s.mu.Lock()
+	if s.refs == 0 {
+		s.mu.Unlock()
+		return
+	}
+	s.refs--
+	if s.refs > 0 {
+		s.logRecords = append(s.logRecords, opts.LogRecords...)
+		s.mu.Unlock()
+		return
+	}
+	if len(s.logRecords) > 0 {
+		opts.LogRecords = append(s.logRecords, opts.LogRecords...)
+		s.logRecords = nil
+	}
+	s.mu.Unlock()
+	s.Span.FinishWithOptions(opts)
+}
+
+func (s *sharedSpan) SetOperationName(operationName string) Span {
+	s.Span.SetOperationName(operationName)
+	return s
+}
+
+func (s *sharedSpan) SetTag(key string, value interface{}) Span {
+	s.Span.SetTag(key, value)
+	return s
+}
+
+func (s *sharedSpan) SetBaggageItem(restrictedKey, value string) Span {
+	s.Span.SetBaggageItem(restrictedKey, value)
+	return s
+}
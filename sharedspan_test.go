@@ -0,0 +1,69 @@
+package opentracing_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+func TestNewSharedSpan(t *testing.T) {
+	tracer := mocktracer.New()
+	span := opentracing.NewSharedSpan(tracer.StartSpan("fan-out"), 3)
+
+	span.Finish()
+	span.FinishWithOptions(opentracing.FinishOptions{
+		LogRecords: []opentracing.LogRecord{
+			{Timestamp: time.Now(), Fields: []log.Field{log.String("event", "second")}},
+		},
+	})
+	assert.Empty(t, tracer.FinishedSpans())
+
+	finishTime := time.Now().Add(time.Second)
+	span.FinishWithOptions(opentracing.FinishOptions{
+		FinishTime: finishTime,
+		LogRecords: []opentracing.LogRecord{
+			{Timestamp: time.Now(), Fields: []log.Field{log.String("event", "last")}},
+		},
+	})
+	span.Finish() // 多余的调用被忽略
+
+	finished := tracer.FinishedSpans()
+	if assert.Len(t, finished, 1) {
+		assert.Equal(t, finishTime, finished[0].FinishTime)
+		logs := finished[0].Logs()
+		if assert.Len(t, logs, 2) {
+			assert.Equal(t, "second", logs[0].Fields[0].ValueString)
+			assert.Equal(t, "last", logs[1].Fields[0].ValueString)
+		}
+	}
+}
+
+func TestNewSharedSpanConcurrent(t *testing.T) {
+	tracer := mocktracer.New()
+	const workers = 20
+	span := opentracing.NewSharedSpan(tracer.StartSpan("fan-out"), workers)
+	assert.Equal(t, span, span.SetTag("k", "v"), "SetTag must return the wrapper")
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer span.Finish()
+			span.LogKV("worker", i)
+		}(i)
+	}
+	wg.Wait()
+
+	finished := tracer.FinishedSpans()
+	if assert.Len(t, finished, 1) {
+		assert.Len(t, finished[0].Logs(), workers)
+		assert.Equal(t, "v", finished[0].Tag("k"))
+	}
+}
@@ -0,0 +1,54 @@
+package opentracing
+
+import "github.com/opentracing/opentracing-go/log"
+
+// ContextShimSpan 返回一个只读的 Span，它的 Context() 返回 sc，Tracer() 返回 tracer，
+// BaggageItem 读取 sc 中的携带数据(baggage)，其余的操作（SetTag、LogFields、Finish 等）都是空操作。
+//
+// 它适用于只拿到了 Extract 的结果，但不想开始一个新的 Span 的场景，例如只是为了继续 Inject 给下游。
+// 把它放入 ContextWithSpan 中之后，StartSpanFromContext 创建的 Span 会以 sc 为父节点：
+//
+//    sc, _ := tracer.Extract(opentracing.HTTPHeaders, carrier)
+//    ctx = opentracing.ContextWithSpan(ctx, opentracing.ContextShimSpan(tracer, sc))
+//
+func ContextShimSpan(tracer Tracer, sc SpanContext) Span {
+	return newContextOnlySpan(tracer, sc)
+}
+
+// contextOnlySpan 是一个除了 Context() 和 Tracer() 之外所有操作都是空操作的 Span。
+type contextOnlySpan struct {
+	sc     SpanContext
+	tracer Tracer
+}
+
+func newContextOnlySpan(tracer Tracer, sc SpanContext) Span {
+	return &contextOnlySpan{sc: sc, tracer: tracer}
+}
+
+func (s *contextOnlySpan) Context() SpanContext                                  { return s.sc }
+func (s *contextOnlySpan) Tracer() Tracer                                        { return s.tracer }
+func (s *contextOnlySpan) SetBaggageItem(key, val string) Span                   { return s }
+func (s *contextOnlySpan) SetTag(key string, value interface{}) Span             { return s }
+func (s *contextOnlySpan) LogFields(fields ...log.Field)                         {}
+func (s *contextOnlySpan) LogKV(keyVals ...interface{})                          {}
+func (s *contextOnlySpan) Finish()                                               {}
+func (s *contextOnlySpan) FinishWithOptions(opts FinishOptions)                  {}
+func (s *contextOnlySpan) SetOperationName(operationName string) Span            { return s }
+func (s *contextOnlySpan) LogEvent(event string)                                 {}
+func (s *contextOnlySpan) LogEventWithPayload(event string, payload interface{}) {}
+func (s *contextOnlySpan) Log(data LogData)                                      {}
+
+// BaggageItem 返回 SpanContext 中的携带数据(baggage)
+func (s *contextOnlySpan) BaggageItem(key string) string {
+	value := emptyString
+	if s.sc != nil {
+		s.sc.ForeachBaggageItem(func(k, v string) bool {
+			if k == key {
+				value = v
+				return false
+			}
+			return true
+		})
+	}
+	return value
+}
@@ -0,0 +1,30 @@
+package opentracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextShimSpan(t *testing.T) {
+	tracer := testTracer{}
+	sc := testSpanContext{FakeID: 42}
+	shim := ContextShimSpan(tracer, sc)
+	assert.Equal(t, sc, shim.Context())
+	assert.Equal(t, tracer, shim.Tracer())
+	assert.Equal(t, shim, shim.SetTag("k", "v"))
+	shim.LogKV("event", "ignored")
+	shim.Finish()
+
+	ctx := ContextWithSpan(context.Background(), shim)
+	child, _ := StartSpanFromContextWithTracer(ctx, tracer, "child")
+	childCtx := child.Context().(testSpanContext)
+	assert.True(t, childCtx.HasParent)
+	assert.Equal(t, 42, childCtx.FakeID)
+
+	baggage := ContextShimSpan(tracer, baggageSpanContext{"user_id": "1"})
+	assert.Equal(t, "1", baggage.BaggageItem("user_id"))
+	assert.Equal(t, "", baggage.BaggageItem("missing"))
+	assert.Equal(t, "", ContextShimSpan(tracer, nil).BaggageItem("user_id"))
+}
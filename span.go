@@ -138,6 +138,28 @@ type FinishOptions struct {
 
 	// BulkLogData 是废弃(DEPRECATED)的。
 	BulkLogData []LogData
+
+	// Relinquish 为 true 时，表示调用者承诺在 FinishWithOptions() 返回之后不再以任何方式使用该 Span
+	// （包括 Context()、BaggageItem() 以及把它放入 context 中），Tracer 可以回收并复用该 Span 对象。
+	// 见 FinishAndRelinquish 和 ReusableSpanPool。
+	//
+	// 不支持复用的 Tracer 可以忽略该字段。
+	Relinquish bool
+}
+
+// MigrateBulkLogData 把（已废弃的） BulkLogData 中的每一条 LogData 通过 LogData.ToLogRecord 转换为 LogRecord，
+// 追加到 LogRecords 之后，然后清空 BulkLogData。这样 Tracer 的实现只需要处理 LogRecords。
+//
+// 时间戳为零值的 LogData 会使用 FinishTime，如果 FinishTime 也是零值则使用 time.Now()。
+// 该方法是幂等的：迁移之后再次调用不会重复追加。
+func (o *FinishOptions) MigrateBulkLogData() {
+	for _, ld := range o.BulkLogData {
+		if ld.Timestamp.IsZero() {
+			ld.Timestamp = o.FinishTime
+		}
+		o.LogRecords = append(o.LogRecords, ld.ToLogRecord())
+	}
+	o.BulkLogData = nil
 }
 
 // LogData 已弃用
@@ -0,0 +1,41 @@
+package opentracing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opentracing/opentracing-go/log"
+)
+
+func TestFinishOptionsMigrateBulkLogData(t *testing.T) {
+	t1 := time.Unix(100, 0)
+	finish := time.Unix(200, 0)
+	opts := FinishOptions{
+		FinishTime: finish,
+		LogRecords: []LogRecord{{Timestamp: t1, Fields: []log.Field{log.Event("new")}}},
+		BulkLogData: []LogData{
+			{Timestamp: t1, Event: "old", Payload: 42},
+			{Event: "no timestamp"},
+		},
+	}
+
+	opts.MigrateBulkLogData()
+	assert.Nil(t, opts.BulkLogData)
+	require.Len(t, opts.LogRecords, 3)
+	assert.Equal(t, []log.Field{log.Event("new")}, opts.LogRecords[0].Fields)
+	assert.Equal(t, LogRecord{Timestamp: t1, Fields: []log.Field{log.String("event", "old"), log.Object("payload", 42)}}, opts.LogRecords[1])
+	assert.Equal(t, LogRecord{Timestamp: finish, Fields: []log.Field{log.String("event", "no timestamp")}}, opts.LogRecords[2])
+
+	// 幂等
+	opts.MigrateBulkLogData()
+	assert.Len(t, opts.LogRecords, 3)
+
+	opts = FinishOptions{BulkLogData: []LogData{{Event: "now"}}}
+	before := time.Now()
+	opts.MigrateBulkLogData()
+	require.Len(t, opts.LogRecords, 1)
+	assert.False(t, opts.LogRecords[0].Timestamp.Before(before))
+}
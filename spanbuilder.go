@@ -0,0 +1,76 @@
+package opentracing
+
+import (
+	"context"
+	"time"
+)
+
+// SpanBuilder 以链式调用的方式收集开始一个 Span 所需的参数，是 StartSpanOption 之外另一种更容易被发现（例如被 IDE 补全）的写法：
+//
+//    span := opentracing.Build("query").
+//        ChildOf(parent.Context()).
+//        Tag("db.type", "sql").
+//        StartTime(start).
+//        Start(tracer)
+//
+// 它最终都会被转换为 StartSpanOption，所以两种写法的效果完全相同；没有对应方法的选项可以通过 Option 传入。
+// SpanBuilder 不是并发安全的，也不应该在多次 Start 之间复用。
+type SpanBuilder struct {
+	operationName string
+	opts          []StartSpanOption
+}
+
+// Build 返回一个以 operationName 为操作名的 SpanBuilder。
+func Build(operationName string) *SpanBuilder {
+	return &SpanBuilder{operationName: operationName}
+}
+
+// ChildOf 添加一个 ChildOfRef 引用，空(nil)的 sc 会被忽略，见 ChildOf。
+func (b *SpanBuilder) ChildOf(sc SpanContext) *SpanBuilder {
+	return b.Option(ChildOf(sc))
+}
+
+// FollowsFrom 添加一个 FollowsFromRef 引用，空(nil)的 sc 会被忽略，见 FollowsFrom。
+func (b *SpanBuilder) FollowsFrom(sc SpanContext) *SpanBuilder {
+	return b.Option(FollowsFrom(sc))
+}
+
+// Tag 为 Span 设置一个开始时的 tag，见 Tag。
+func (b *SpanBuilder) Tag(key string, value interface{}) *SpanBuilder {
+	return b.Option(Tag{Key: key, Value: value})
+}
+
+// Tags 为 Span 设置多个开始时的 tag，见 Tags。
+func (b *SpanBuilder) Tags(tags Tags) *SpanBuilder {
+	return b.Option(tags)
+}
+
+// StartTime 设置 Span 的开始时间，见 StartTime。
+func (b *SpanBuilder) StartTime(t time.Time) *SpanBuilder {
+	return b.Option(StartTime(t))
+}
+
+// Option 把 opts 原样追加到选项中。
+func (b *SpanBuilder) Option(opts ...StartSpanOption) *SpanBuilder {
+	b.opts = append(b.opts, opts...)
+	return b
+}
+
+// Options 返回目前收集的 StartSpanOption。
+func (b *SpanBuilder) Options() []StartSpanOption {
+	return b.opts
+}
+
+// Start 用 tracer 开始 Span，tracer 为空(nil)时使用 GlobalTracer()。
+func (b *SpanBuilder) Start(tracer Tracer) Span {
+	if tracer == nil {
+		tracer = GlobalTracer()
+	}
+	return tracer.StartSpan(b.operationName, b.opts...)
+}
+
+// StartFromContext 与 StartSpanFromContext 相同：以`ctx`中的 Span 为父级开始 Span，
+// 并返回新的 Span 和包含它的context。
+func (b *SpanBuilder) StartFromContext(ctx context.Context) (Span, context.Context) {
+	return StartSpanFromContext(ctx, b.operationName, b.opts...)
+}
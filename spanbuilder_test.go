@@ -0,0 +1,52 @@
+package opentracing_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+func TestSpanBuilder(t *testing.T) {
+	tracer := mocktracer.New()
+	parent := tracer.StartSpan("parent")
+	start := time.Now().Add(-time.Minute)
+
+	span := opentracing.Build("query").
+		ChildOf(parent.Context()).
+		Tag("db.type", "sql").
+		Tags(opentracing.Tags{"retry": 1}).
+		StartTime(start).
+		Start(tracer).(*mocktracer.MockSpan)
+
+	assert.Equal(t, "query", span.OperationName)
+	assert.Equal(t, parent.Context().(mocktracer.MockSpanContext).SpanID, span.ParentID)
+	assert.Equal(t, map[string]interface{}{"db.type": "sql", "retry": 1}, span.Tags())
+	assert.Equal(t, start, span.StartTime)
+
+	follower := opentracing.Build("async").FollowsFrom(span.Context()).Start(tracer).(*mocktracer.MockSpan)
+	if assert.Len(t, follower.References(), 1) {
+		assert.Equal(t, opentracing.FollowsFromRef, follower.References()[0].Type)
+	}
+}
+
+func TestSpanBuilderStartFromContext(t *testing.T) {
+	tracer := mocktracer.New()
+	defer opentracing.SetGlobalTracerWithRestore(tracer)()
+
+	parent := tracer.StartSpan("parent")
+	ctx := opentracing.ContextWithSpan(context.Background(), parent)
+	span, ctx := opentracing.Build("child").Tag("k", "v").StartFromContext(ctx)
+
+	assert.Equal(t, span, opentracing.SpanFromContext(ctx))
+	child := span.(*mocktracer.MockSpan)
+	assert.Equal(t, parent.Context().(mocktracer.MockSpanContext).SpanID, child.ParentID)
+	assert.Equal(t, "v", child.Tag("k"))
+
+	root := opentracing.Build("root").Start(nil).(*mocktracer.MockSpan)
+	assert.Equal(t, 0, root.ParentID)
+}
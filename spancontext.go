@@ -0,0 +1,145 @@
+package opentracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// TraceIdentifiable 是一个 SpanContext 的实现可以选择实现的扩展接口，
+// 它以与 Tracer 实现无关的方式暴露 trace id 和 span id，例如用于日志的关联。
+type TraceIdentifiable interface {
+	// TraceIDString 返回 trace id 的字符串形式
+	TraceIDString() string
+	// SpanIDString 返回 span id 的字符串形式
+	SpanIDString() string
+}
+
+// TraceIDFromContext 返回`ctx`中的活跃 Span 的 trace id，如果没有活跃的 Span，则使用 ContextWithSpanContext 放入的 SpanContext。
+// 如果都没有，或者该 SpanContext 没有实现 TraceIdentifiable，第二个返回值为 false。
+//
+// 它可以在不依赖具体 Tracer 实现的情况下把 trace id 关联到日志中：
+//
+//    if traceID, ok := opentracing.TraceIDFromContext(ctx); ok {
+//        logger = logger.With("trace_id", traceID)
+//    }
+//
+func TraceIDFromContext(ctx context.Context) (string, bool) {
+	if ids, ok := traceIdentifiableFromContext(ctx); ok {
+		return ids.TraceIDString(), true
+	}
+	return "", false
+}
+
+// SpanIDFromContext 与 TraceIDFromContext 相同，但返回 span id。
+func SpanIDFromContext(ctx context.Context) (string, bool) {
+	if ids, ok := traceIdentifiableFromContext(ctx); ok {
+		return ids.SpanIDString(), true
+	}
+	return "", false
+}
+
+func traceIdentifiableFromContext(ctx context.Context) (TraceIdentifiable, bool) {
+	var sc SpanContext
+	if sp := SpanFromContext(ctx); sp != nil {
+		sc = sp.Context()
+	} else {
+		sc = SpanContextFromContext(ctx)
+	}
+	ids, ok := sc.(TraceIdentifiable)
+	return ids, ok
+}
+
+// BasicSpanContext 是一个简单的 SpanContext 的值类型实现，
+// 可用于编写载体(carrier)或传播相关的测试，以及简单的 Tracer 实现。
+// basictracer 使用它作为 Span 的 SpanContext，propagators/b3、propagators/jaeger 和 TraceParent
+// 都提供了与它相互转换的方法。
+//
+// BasicSpanContext 应该被视为不可变的：WithBaggageItem 总是返回一个新的副本，
+// 因此不能通过子Span修改共享的父 SpanContext。
+type BasicSpanContext struct {
+	// TraceID 是 trace id（的低64位）。
+	TraceID uint64
+	// TraceIDHigh 是128位 trace id 的高64位，64位的 trace id 为0。
+	TraceIDHigh uint64
+	SpanID      uint64
+	Sampled     bool
+	Baggage     map[string]string
+}
+
+// NewSpanContext 返回一个带有指定 traceID 和 spanID 的 BasicSpanContext，它是被采样的，并且没有携带数据。
+func NewSpanContext(traceID, spanID uint64) BasicSpanContext {
+	return BasicSpanContext{TraceID: traceID, SpanID: spanID, Sampled: true}
+}
+
+// ForeachBaggageItem 实现 SpanContext 接口。
+func (c BasicSpanContext) ForeachBaggageItem(handler func(k, v string) bool) {
+	for k, v := range c.Baggage {
+		if !handler(k, v) {
+			break
+		}
+	}
+}
+
+// IsSampled 实现 SampledSpanContext 接口。
+func (c BasicSpanContext) IsSampled() bool {
+	return c.Sampled
+}
+
+// TraceIDString 实现 TraceIdentifiable 接口，以16位小写十六进制数的形式返回 trace id，
+// 128位的 trace id 为32位。
+func (c BasicSpanContext) TraceIDString() string {
+	if c.TraceIDHigh != 0 {
+		return fmt.Sprintf("%016x%016x", c.TraceIDHigh, c.TraceID)
+	}
+	return fmt.Sprintf("%016x", c.TraceID)
+}
+
+// SpanIDString 实现 TraceIdentifiable 接口，以16位小写十六进制数的形式返回 span id。
+func (c BasicSpanContext) SpanIDString() string {
+	return fmt.Sprintf("%016x", c.SpanID)
+}
+
+// WithBaggageItem 返回一个添加了一个携带数据的新 BasicSpanContext，原来的 BasicSpanContext 不会被修改。
+func (c BasicSpanContext) WithBaggageItem(key, value string) BasicSpanContext {
+	baggage := make(map[string]string, len(c.Baggage)+1)
+	for k, v := range c.Baggage {
+		baggage[k] = v
+	}
+	baggage[key] = value
+	c.Baggage = baggage
+	return c
+}
+
+// spanContextJSON 是 SpanContextToJSON 输出的结构。
+type spanContextJSON struct {
+	TraceID string            `json:"trace_id,omitempty"`
+	SpanID  string            `json:"span_id,omitempty"`
+	Baggage map[string]string `json:"baggage"`
+}
+
+// SpanContextToJSON 把 sc 序列化为一个用于调试的 JSON 字符串，例如：
+//
+//     {"trace_id":"1","span_id":"2","baggage":{"user_id":"42"}}
+//
+// 它通过 ForeachBaggageItem 收集所有携带数据(baggage)，
+// 如果 sc 实现了 TraceIdentifiable，输出中还会包含 trace_id 和 span_id。
+// baggage 的键会被排序，因此对于相同的 sc 输出是稳定的。
+//
+// 如果 sc 为空(nil)，将会返回一个错误。
+func SpanContextToJSON(sc SpanContext) (string, error) {
+	if sc == nil {
+		return "", errors.New("opentracing: cannot serialize a nil SpanContext to JSON")
+	}
+	out := spanContextJSON{Baggage: baggageMap(sc)}
+	if ids, ok := sc.(TraceIdentifiable); ok {
+		out.TraceID = ids.TraceIDString()
+		out.SpanID = ids.SpanIDString()
+	}
+	b, err := json.Marshal(out)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
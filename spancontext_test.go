@@ -0,0 +1,104 @@
+package opentracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSpanContext(t *testing.T) {
+	sc := NewSpanContext(1, 2)
+	assert.Equal(t, BasicSpanContext{TraceID: 1, SpanID: 2, Sampled: true}, sc)
+
+	// nil 的携带数据可以被安全的遍历
+	sc.ForeachBaggageItem(func(k, v string) bool {
+		t.Errorf("Unexpected baggage item %s=%s", k, v)
+		return true
+	})
+}
+
+func TestBasicSpanContextWithBaggageItem(t *testing.T) {
+	parent := NewSpanContext(1, 2).WithBaggageItem("user_id", "1")
+	child := parent.WithBaggageItem("tenant", "a")
+	overwritten := child.WithBaggageItem("user_id", "2")
+
+	assert.Equal(t, map[string]string{"user_id": "1"}, parent.Baggage)
+	assert.Equal(t, map[string]string{"user_id": "1", "tenant": "a"}, child.Baggage)
+	assert.Equal(t, map[string]string{"user_id": "2", "tenant": "a"}, overwritten.Baggage)
+	assert.Equal(t, parent.TraceID, overwritten.TraceID)
+	assert.Equal(t, parent.SpanID, overwritten.SpanID)
+
+	visited := 0
+	child.ForeachBaggageItem(func(k, v string) bool {
+		visited++
+		return false // 提前结束
+	})
+	assert.Equal(t, 1, visited)
+}
+
+// identifiableSpanContext 是一个实现了 TraceIdentifiable 的 SpanContext。
+type identifiableSpanContext struct {
+	baggageSpanContext
+	traceID, spanID string
+}
+
+func (c identifiableSpanContext) TraceIDString() string { return c.traceID }
+func (c identifiableSpanContext) SpanIDString() string  { return c.spanID }
+
+var (
+	_ TraceIdentifiable = identifiableSpanContext{}
+	_ TraceIdentifiable = BasicSpanContext{}
+)
+
+func TestSpanContextToJSON(t *testing.T) {
+	s, err := SpanContextToJSON(baggageSpanContext{"user_id": "42", "tenant": "a"})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"baggage":{"tenant":"a","user_id":"42"}}`, s)
+
+	s, err = SpanContextToJSON(identifiableSpanContext{
+		baggageSpanContext: baggageSpanContext{"user_id": "42"},
+		traceID:            "abc",
+		spanID:             "def",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"trace_id":"abc","span_id":"def","baggage":{"user_id":"42"}}`, s)
+}
+
+func TestSpanContextToJSONWithoutBaggage(t *testing.T) {
+	s, err := SpanContextToJSON(baggageSpanContext{})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"baggage":{}}`, s)
+
+	s, err = SpanContextToJSON(NewSpanContext(1, 0xab))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"trace_id":"0000000000000001","span_id":"00000000000000ab","baggage":{}}`, s)
+
+	s, err = SpanContextToJSON(identifiableSpanContext{traceID: "abc", spanID: "def"})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"trace_id":"abc","span_id":"def","baggage":{}}`, s)
+
+	_, err = SpanContextToJSON(nil)
+	assert.Error(t, err)
+}
+
+func TestTraceIDFromContext(t *testing.T) {
+	_, ok := TraceIDFromContext(context.Background())
+	assert.False(t, ok)
+
+	ids := identifiableSpanContext{traceID: "abc", spanID: "def"}
+	ctx := ContextWithSpanContext(context.Background(), ids)
+	traceID, ok := TraceIDFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "abc", traceID)
+	spanID, ok := SpanIDFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "def", spanID)
+
+	// 活跃的 Span 优先，testSpanContext 没有实现 TraceIdentifiable
+	ctx = ContextWithSpan(ctx, testSpan{})
+	_, ok = TraceIDFromContext(ctx)
+	assert.False(t, ok)
+	_, ok = SpanIDFromContext(ctx)
+	assert.False(t, ok)
+}
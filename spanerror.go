@@ -0,0 +1,79 @@
+package opentracing
+
+import (
+	"context"
+	"runtime/debug"
+	"time"
+
+	"github.com/opentracing/opentracing-go/log"
+)
+
+// SetError 以 OpenTracing 语义约定的方式把 err 记录到 span 上：设置 tag `error=true`，
+// 并记录一条包含`event=error`、`error.object=err`、`message=err.Error()`和`stack`（当前goroutine的调用栈）的日志。
+// err 为 nil 时什么也不做。
+//
+//    if err := doWork(ctx); err != nil {
+//        opentracing.SetError(span, err)
+//        return err
+//    }
+//
+// 与 ext.LogError 不同，它总是记录调用栈；只需要 tag 和错误本身时可以使用 ext.LogError。
+func SetError(span Span, err error) {
+	if err == nil {
+		return
+	}
+	setErrorFields(span, err.Error(), err)
+}
+
+// WithSpanError 返回一个 StartSpanOption，当 err 不为 nil 时为新的 Span 设置 tag `error=true`，
+// 用于在 Span 开始之前就已经知道失败的操作，例如事后根据日志或回调补录的 Span。err 为 nil 时该选项不做任何事。
+//
+// StartSpanOptions 无法携带日志，所以错误的详细信息需要在 Span 开始之后用 SetError 记录。
+func WithSpanError(err error) StartSpanOption {
+	if err == nil {
+		return Tags(nil)
+	}
+	return Tag{Key: "error", Value: true}
+}
+
+// DeadlineOverrunLogKey 是 FinishWithContext 记录截止时间超出多久（time.Duration 的字符串形式）的日志字段的键。
+const DeadlineOverrunLogKey = "deadline.overrun"
+
+// FinishWithContext 结束(Finish) span。如果此时 ctx 已经被取消或者超过了截止时间，会先在 span 上设置`error=true`，
+// 并记录一条包含`event=error`、`error.object=ctx.Err()`和`message`的日志；
+// 如果 ctx 有截止时间并且已经超过，日志中还会包含`deadline.overrun`，即结束时超出截止时间多久。
+//
+//    ctx, cancel := context.WithTimeout(ctx, time.Second)
+//    defer cancel()
+//    span, ctx := opentracing.StartSpanFromContext(ctx, "query")
+//    defer opentracing.FinishWithContext(ctx, span)
+//
+func FinishWithContext(ctx context.Context, span Span) {
+	err := ctx.Err()
+	if err == nil {
+		span.Finish()
+		return
+	}
+	now := time.Now()
+	fields := []log.Field{
+		log.Event("error"),
+		log.Error(err),
+		log.Message(err.Error()),
+	}
+	if deadline, ok := ctx.Deadline(); ok && now.After(deadline) {
+		fields = append(fields, log.String(DeadlineOverrunLogKey, now.Sub(deadline).String()))
+	}
+	span.SetTag("error", true)
+	span.LogFields(fields...)
+	span.FinishWithOptions(FinishOptions{FinishTime: now})
+}
+
+// setErrorFields 设置`error=true`并记录错误日志，SetError 和 FinishWithRecover 共用它。
+func setErrorFields(span Span, message string, object interface{}) {
+	span.SetTag("error", true)
+	span.LogFields(
+		log.Event("error"),
+		log.Message(message),
+		log.Object(log.ErrorObjectKey, object),
+		log.Stack(string(debug.Stack())))
+}
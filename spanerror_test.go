@@ -0,0 +1,88 @@
+package opentracing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+func TestSetError(t *testing.T) {
+	tracer := mocktracer.New()
+	span := tracer.StartSpan("op")
+	err := errors.New("boom")
+
+	opentracing.SetError(span, nil)
+	opentracing.SetError(span, err)
+	span.Finish()
+
+	finished := tracer.FinishedSpans()[0]
+	assert.Equal(t, true, finished.Tag("error"))
+	logs := finished.Logs()
+	if assert.Len(t, logs, 1) {
+		fields := map[string]mocktracer.MockKeyValue{}
+		for _, f := range logs[0].Fields {
+			fields[f.Key] = f
+		}
+		assert.Equal(t, "error", fields["event"].ValueString)
+		assert.Equal(t, "boom", fields["message"].ValueString)
+		assert.Equal(t, "boom", fields[log.ErrorObjectKey].ValueString)
+		assert.Contains(t, fields["stack"].ValueString, "TestSetError")
+	}
+}
+
+func TestWithSpanError(t *testing.T) {
+	tracer := mocktracer.New()
+	failed := tracer.StartSpan("failed", opentracing.WithSpanError(errors.New("boom"))).(*mocktracer.MockSpan)
+	assert.Equal(t, true, failed.Tag("error"))
+
+	ok := tracer.StartSpan("ok", opentracing.WithSpanError(nil)).(*mocktracer.MockSpan)
+	assert.Empty(t, ok.Tags())
+}
+
+func TestFinishWithContext(t *testing.T) {
+	tracer := mocktracer.New()
+
+	opentracing.FinishWithContext(context.Background(), tracer.StartSpan("ok"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	opentracing.FinishWithContext(ctx, tracer.StartSpan("canceled"))
+
+	ctx, cancel = context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	opentracing.FinishWithContext(ctx, tracer.StartSpan("late"))
+
+	finished := tracer.FinishedSpans()
+	if !assert.Len(t, finished, 3) {
+		return
+	}
+	assert.Empty(t, finished[0].Tags())
+	assert.Empty(t, finished[0].Logs())
+
+	fields := func(span *mocktracer.MockSpan) map[string]string {
+		m := map[string]string{}
+		for _, f := range span.Logs()[0].Fields {
+			m[f.Key] = f.ValueString
+		}
+		return m
+	}
+
+	assert.Equal(t, true, finished[1].Tag("error"))
+	canceled := fields(finished[1])
+	assert.Equal(t, context.Canceled.Error(), canceled[log.ErrorObjectKey])
+	assert.NotContains(t, canceled, opentracing.DeadlineOverrunLogKey)
+
+	assert.Equal(t, true, finished[2].Tag("error"))
+	late := fields(finished[2])
+	assert.Equal(t, context.DeadlineExceeded.Error(), late["message"])
+	overrun, err := time.ParseDuration(late[opentracing.DeadlineOverrunLogKey])
+	assert.NoError(t, err)
+	assert.True(t, overrun >= time.Second)
+}
@@ -0,0 +1,188 @@
+package opentracing
+
+import (
+	"regexp"
+	"strings"
+)
+
+// SpanFilterRule 描述了 WrapTracerWithSpanFilter 如何根据操作名(operationName)处理新的 Span。
+//
+// 匹配条件只会使用 Exact、Prefix、Regexp 中按此顺序第一个非空的值。
+// 匹配成功后：如果 Drop 为 true，则丢弃该 Span；否则如果 Rename 非空则重命名该 Span，
+// 并把 Tags 添加到该 Span 上。
+type SpanFilterRule struct {
+	// Exact 匹配与之完全相同的操作名
+	Exact string
+	// Prefix 匹配带有该前缀的操作名
+	Prefix string
+	// Regexp 匹配满足该正则表达式的操作名
+	Regexp *regexp.Regexp
+
+	// Drop 表示丢弃匹配的 Span
+	Drop bool
+	// Rename 是匹配的 Span 的新名字。当使用 Regexp 匹配时，Rename 是 Regexp.ReplaceAllString 的模板，
+	// 例如 `^/users/\d+$` 和 "/users/{id}" 可以把每个URL各自的操作名合并为路由模板。
+	Rename string
+	// Tags 会被添加到匹配的 Span 上
+	Tags Tags
+}
+
+func (r SpanFilterRule) match(operationName string) bool {
+	switch {
+	case r.Exact != "":
+		return operationName == r.Exact
+	case r.Prefix != "":
+		return strings.HasPrefix(operationName, r.Prefix)
+	case r.Regexp != nil:
+		return r.Regexp.MatchString(operationName)
+	}
+	return false
+}
+
+func (r SpanFilterRule) rename(operationName string) string {
+	if r.Exact == "" && r.Prefix == "" && r.Regexp != nil {
+		return r.Regexp.ReplaceAllString(operationName, r.Rename)
+	}
+	return r.Rename
+}
+
+// WrapTracerWithSpanFilter 返回一个包装了 tracer 的 Tracer，它在 StartSpan 时按顺序对操作名应用所有匹配的 rules，
+// 这样就可以在不修改每一个调用点的情况下全局地丢弃、重命名 Span 或为 Span 添加固定的tag。
+// 规则只会在 StartSpan 时对操作名进行匹配，之后的 SetOperationName 调用不受影响。
+//
+// 被丢弃的 Span 的所有操作都是空操作，但它的 Context() 会返回它最近的一个未被丢弃的祖先的 SpanContext，
+// 因此以它为父级的子Span会被正确地关联到该祖先上；如果它没有祖先，它的子Span将成为根Span。
+//
+// Inject 和 Extract 会直接委托给 tracer。
+func WrapTracerWithSpanFilter(tracer Tracer, rules ...SpanFilterRule) Tracer {
+	return &spanFilterTracer{tracer: tracer, rules: rules}
+}
+
+// TracerWithSamplingFilter 返回一个包装了 inner 的 Tracer，它在 StartSpan 时以操作名和应用后的选项调用 keep，
+// 如果 keep 返回 false 则丢弃该 Span，否则委托给 inner。这可以用来在客户端丢弃健康检查、metrics 抓取之类的高频 Span：
+//
+//    tracer = opentracing.TracerWithSamplingFilter(tracer, func(op string, _ opentracing.StartSpanOptions) bool {
+//        return op != "GET /healthz"
+//    })
+//
+// 与 WrapTracerWithSpanFilter 一样，被丢弃的 Span 的所有操作都是空操作，但它的 Context() 会返回它的父级的 SpanContext，
+// 因此它的子Span仍然属于同一条链路。Inject 和 Extract 会直接委托给 inner。
+func TracerWithSamplingFilter(inner Tracer, keep func(operationName string, opts StartSpanOptions) bool) Tracer {
+	return &spanFilterTracer{tracer: inner, keep: keep}
+}
+
+type spanFilterTracer struct {
+	tracer Tracer
+	rules  []SpanFilterRule
+	keep   func(operationName string, opts StartSpanOptions) bool
+}
+
+// droppedRootSpanContext 是一个没有祖先的被丢弃的 Span 的 SpanContext。
+// 在引用它的 StartSpan 调用中，它会被移除。
+type droppedRootSpanContext struct {
+	noopSpanContext
+}
+
+func (t *spanFilterTracer) StartSpan(operationName string, opts ...StartSpanOption) Span {
+	sso := NewStartSpanOptions(opts...)
+	refs := sso.References[:0]
+	for _, ref := range sso.References {
+		if _, ok := ref.ReferencedContext.(droppedRootSpanContext); !ok {
+			refs = append(refs, ref)
+		}
+	}
+	sso.References = refs
+
+	if t.keep != nil {
+		// keep 可能直接读取 Tags 字段
+		sso.TagsMap()
+		if !t.keep(operationName, sso) {
+			return newContextOnlySpan(t, droppedParentContext(sso))
+		}
+	}
+	for _, rule := range t.rules {
+		if !rule.match(operationName) {
+			continue
+		}
+		if rule.Drop {
+			return newContextOnlySpan(t, droppedParentContext(sso))
+		}
+		if rule.Rename != "" {
+			operationName = rule.rename(operationName)
+		}
+		rule.Tags.Apply(&sso)
+	}
+	return &tracerOverrideSpan{Span: t.tracer.StartSpan(operationName, appliedStartSpanOptions(sso)), tracer: t}
+}
+
+// droppedParentContext 返回被丢弃的 Span 应该传递给子Span的 SpanContext：
+// 第一个 ChildOf 引用，或者第一个 FollowsFrom 引用。
+func droppedParentContext(sso StartSpanOptions) SpanContext {
+	if sso.IsRoot() {
+		return droppedRootSpanContext{}
+	}
+	parents := sso.ChildOfReferences()
+	if len(parents) == 0 {
+		parents = sso.FollowsFromReferences()
+	}
+	return parents[0]
+}
+
+func (t *spanFilterTracer) Inject(sc SpanContext, format interface{}, carrier interface{}) error {
+	if _, ok := sc.(droppedRootSpanContext); ok {
+		// 没有任何需要传播的内容
+		return nil
+	}
+	return t.tracer.Inject(sc, format, carrier)
+}
+
+func (t *spanFilterTracer) Extract(format interface{}, carrier interface{}) (SpanContext, error) {
+	return t.tracer.Extract(format, carrier)
+}
+
+// appliedStartSpanOptions 把一个已经应用过的 StartSpanOptions 整体作为一个 StartSpanOption，
+// 以便包装其他 Tracer 的实现在修改选项之后把它传递给被包装的 Tracer。
+type appliedStartSpanOptions StartSpanOptions
+
+// Apply 实现`StartSpanOption`接口.
+func (a appliedStartSpanOptions) Apply(o *StartSpanOptions) {
+	o.References = append(o.References, a.References...)
+	if !a.StartTime.IsZero() {
+		o.StartTime = a.StartTime
+	}
+	// 暂存的 tag 比 Tags 中的更早写入，所以先应用它们
+	for _, t := range a.inlineTags[:a.numInline] {
+		setStartSpanTag(o, t.key, t.Value())
+	}
+	if len(a.Tags) > 0 {
+		Tags(a.Tags).Apply(o)
+	}
+	if a.OperationNameCallback != nil {
+		o.OperationNameCallback = a.OperationNameCallback
+	}
+	baggageOption(a.Baggage).Apply(o)
+}
+
+// tracerOverrideSpan 包装了一个 Span，使它的 Tracer() 返回包装它的 Tracer，
+// 这样通过 span.Tracer() 创建的子Span也会经过包装的 Tracer。
+type tracerOverrideSpan struct {
+	Span
+	tracer Tracer
+}
+
+func (s *tracerOverrideSpan) Tracer() Tracer { return s.tracer }
+
+func (s *tracerOverrideSpan) SetOperationName(operationName string) Span {
+	s.Span.SetOperationName(operationName)
+	return s
+}
+
+func (s *tracerOverrideSpan) SetTag(key string, value interface{}) Span {
+	s.Span.SetTag(key, value)
+	return s
+}
+
+func (s *tracerOverrideSpan) SetBaggageItem(restrictedKey, value string) Span {
+	s.Span.SetBaggageItem(restrictedKey, value)
+	return s
+}
@@ -0,0 +1,100 @@
+package opentracing_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+func TestWrapTracerWithSpanFilter(t *testing.T) {
+	inner := mocktracer.New()
+	tracer := opentracing.WrapTracerWithSpanFilter(inner,
+		opentracing.SpanFilterRule{Exact: "redis.ping", Drop: true},
+		opentracing.SpanFilterRule{Regexp: regexp.MustCompile(`^GET /users/\d+$`), Rename: "GET /users/{id}"},
+		opentracing.SpanFilterRule{Prefix: "GET ", Tags: opentracing.Tags{"component": "http"}},
+	)
+
+	tracer.StartSpan("redis.ping").Finish()
+	tracer.StartSpan("GET /users/42", opentracing.Tag{Key: "user", Value: 42}).Finish()
+	tracer.StartSpan("POST /users").Finish()
+
+	spans := inner.FinishedSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "GET /users/{id}", spans[0].OperationName)
+	assert.Equal(t, map[string]interface{}{"user": 42, "component": "http"}, spans[0].Tags())
+	assert.Equal(t, "POST /users", spans[1].OperationName)
+	assert.Empty(t, spans[1].Tags())
+}
+
+func TestWrapTracerWithSpanFilterParentage(t *testing.T) {
+	inner := mocktracer.New()
+	tracer := opentracing.WrapTracerWithSpanFilter(inner,
+		opentracing.SpanFilterRule{Prefix: "internal.", Drop: true})
+
+	root := tracer.StartSpan("root")
+	dropped := tracer.StartSpan("internal.step", opentracing.ChildOf(root.Context()))
+	droppedAgain := dropped.Tracer().StartSpan("internal.substep", opentracing.ChildOf(dropped.Context()))
+	child := droppedAgain.Tracer().StartSpan("child", opentracing.ChildOf(droppedAgain.Context()))
+	assert.Equal(t, dropped, dropped.SetTag("k", "v"))
+	child.Finish()
+	droppedAgain.Finish()
+	dropped.Finish()
+	root.Finish()
+
+	spans := inner.FinishedSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "child", spans[0].OperationName)
+	assert.Equal(t, spans[1].SpanContext.SpanID, spans[0].ParentID,
+		"child must be parented to the nearest non-dropped ancestor")
+	assert.Equal(t, spans[1].SpanContext.TraceID, spans[0].SpanContext.TraceID)
+
+	// 被丢弃的 Span 仍然传播祖先的 SpanContext
+	carrier := opentracing.TextMapCarrier{}
+	require.NoError(t, tracer.Inject(dropped.Context(), opentracing.TextMap, carrier))
+	extracted, err := tracer.Extract(opentracing.TextMap, carrier)
+	require.NoError(t, err)
+	assert.Equal(t, spans[1].SpanContext.SpanID, extracted.(mocktracer.MockSpanContext).SpanID)
+}
+
+func TestWrapTracerWithSpanFilterDroppedRoot(t *testing.T) {
+	inner := mocktracer.New()
+	tracer := opentracing.WrapTracerWithSpanFilter(inner,
+		opentracing.SpanFilterRule{Exact: "cron", Drop: true})
+
+	dropped := tracer.StartSpan("cron")
+	require.NoError(t, tracer.Inject(dropped.Context(), opentracing.TextMap, opentracing.TextMapCarrier{}))
+	child := tracer.StartSpan("job", opentracing.ChildOf(dropped.Context()))
+	child.Finish()
+	dropped.Finish()
+
+	spans := inner.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, 0, spans[0].ParentID, "children of a dropped root span are root spans")
+}
+
+func TestTracerWithSamplingFilter(t *testing.T) {
+	inner := mocktracer.New()
+	tracer := opentracing.TracerWithSamplingFilter(inner, func(op string, opts opentracing.StartSpanOptions) bool {
+		return op != "GET /healthz" && opts.Tags["skip"] == nil
+	})
+
+	root := tracer.StartSpan("root")
+	dropped := tracer.StartSpan("GET /healthz", opentracing.ChildOf(root.Context()))
+	assert.Equal(t, root.Context(), dropped.Context(), "dropped span must pass its parent's context through")
+	child := dropped.Tracer().StartSpan("child", opentracing.ChildOf(dropped.Context()))
+	tracer.StartSpan("tagged", opentracing.Tag{Key: "skip", Value: true}).Finish()
+	child.Finish()
+	dropped.Finish()
+	root.Finish()
+
+	spans := inner.FinishedSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "child", spans[0].OperationName)
+	assert.Equal(t, "root", spans[1].OperationName)
+	assert.Equal(t, spans[1].SpanContext.SpanID, spans[0].ParentID)
+}
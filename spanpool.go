@@ -0,0 +1,51 @@
+package opentracing
+
+import "sync"
+
+// FinishAndRelinquish 以`FinishOptions{Relinquish: true}`结束 span，即告诉 Tracer 调用者之后不会再使用它。
+// 调用之后 span 可能已经被 Tracer 复用，对它的任何操作都是未定义行为。
+func FinishAndRelinquish(span Span) {
+	span.FinishWithOptions(FinishOptions{Relinquish: true})
+}
+
+// ReusableSpanPool 是供 Tracer 的实现复用 Span 对象的池，它的零值可以直接使用。
+//
+// 只有在调用者通过 FinishOptions.Relinquish 放弃了 Span 之后，Tracer 才能把它放回池中：
+// 普通的 Finish() 并不保证调用者不再持有该 Span（例如之后还会读取它的 Context()）。
+// 典型的用法是：
+//
+//     func (s *span) FinishWithOptions(opts opentracing.FinishOptions) {
+//         s.tracer.record(s)
+//         if opts.Relinquish {
+//             s.tracer.pool.Put(s)
+//         }
+//     }
+//
+// 注意 Recorder 等在 Span 结束后仍然持有其数据的组件必须先复制需要的数据。
+type ReusableSpanPool struct {
+	// New 在池中没有可用的 Span 时创建一个新的 Span，为 nil 时 Get 会返回 nil。
+	New func() Span
+
+	pool sync.Pool
+}
+
+// Get 从池中取出一个已经被 Retire 的 Span，池为空时调用 New。
+func (p *ReusableSpanPool) Get() Span {
+	if sp, ok := p.pool.Get().(Span); ok {
+		return sp
+	}
+	if p.New == nil {
+		return nil
+	}
+	return p.New()
+}
+
+// Put 调用 sp 的 Retire 然后把它放回池中。没有实现 Retirable 的 Span 无法被安全地重置，会被直接丢弃。
+func (p *ReusableSpanPool) Put(sp Span) {
+	r, ok := sp.(Retirable)
+	if !ok {
+		return
+	}
+	r.Retire()
+	p.pool.Put(sp)
+}
@@ -0,0 +1,54 @@
+package opentracing_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+type pooledSpan struct {
+	opentracing.Span
+	retired    int
+	relinquish bool
+}
+
+func (s *pooledSpan) FinishWithOptions(opts opentracing.FinishOptions) {
+	s.relinquish = opts.Relinquish
+}
+
+func (s *pooledSpan) Retire() {
+	s.retired++
+	s.relinquish = false
+}
+
+func TestFinishAndRelinquish(t *testing.T) {
+	span := &pooledSpan{Span: opentracing.NoopTracer{}.StartSpan("op")}
+	opentracing.FinishAndRelinquish(span)
+	assert.True(t, span.relinquish)
+}
+
+func TestReusableSpanPool(t *testing.T) {
+	var created int
+	pool := &opentracing.ReusableSpanPool{New: func() opentracing.Span {
+		created++
+		return &pooledSpan{Span: opentracing.NoopTracer{}.StartSpan("op")}
+	}}
+
+	span := pool.Get().(*pooledSpan)
+	assert.Equal(t, 1, created)
+	span.relinquish = true
+	pool.Put(span)
+	assert.Equal(t, 1, span.retired)
+	assert.False(t, span.relinquish)
+
+	// sync.Pool 不保证一定会返回放入的对象，这里只检查取出的总是被 Retire 过的或者新的 Span
+	got := pool.Get().(*pooledSpan)
+	assert.True(t, got == span || created == 2)
+
+	// 没有实现 Retirable 的 Span 会被丢弃
+	pool.Put(opentracing.NoopTracer{}.StartSpan("op"))
+
+	assert.Nil(t, (&opentracing.ReusableSpanPool{}).Get())
+}
@@ -0,0 +1,34 @@
+package opentracing
+
+// SpanWithValues 是一个 Span 的实现可以选择实现的扩展接口，它为 Span 提供了进程内的键值存储。
+//
+// 与携带数据(baggage)不同，这些值只存在于当前进程中的该 Span 上，
+// 既不会被序列化，也不会通过 Inject()/Extract() 传播到下一世代的 Span，
+// 因此可以很廉价地用于在同一个请求的不同层之间传递数据。
+// （例如：在数据库层记录计算出的分片id，然后在响应层读取它）
+//
+// 实现必须保证这些方法可以被多个goroutine并发调用。
+type SpanWithValues interface {
+	// SetValue 在 Span 上保存一个与 key 关联的值，如果 key 已存在，将会覆盖旧的值。
+	//
+	// 返回一个该 Span 的指针
+	SetValue(key string, value interface{}) Span
+
+	// Value 返回与 key 关联的值，如果没有找到 key 对应的值，将会返回nil。
+	Value(key string) interface{}
+}
+
+// SetSpanValue 在 span 实现了 SpanWithValues 时调用它的 SetValue 方法，否则该函数是空操作。
+func SetSpanValue(span Span, key string, value interface{}) {
+	if sv, ok := span.(SpanWithValues); ok {
+		sv.SetValue(key, value)
+	}
+}
+
+// SpanValue 在 span 实现了 SpanWithValues 时返回它的 Value 方法的结果，否则返回nil。
+func SpanValue(span Span, key string) interface{} {
+	if sv, ok := span.(SpanWithValues); ok {
+		return sv.Value(key)
+	}
+	return nil
+}
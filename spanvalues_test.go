@@ -0,0 +1,48 @@
+package opentracing
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type valuesSpan struct {
+	noopSpan
+	mu     sync.Mutex
+	values map[string]interface{}
+}
+
+func (s *valuesSpan) SetValue(key string, value interface{}) Span {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.values == nil {
+		s.values = make(map[string]interface{})
+	}
+	s.values[key] = value
+	return s
+}
+
+func (s *valuesSpan) Value(key string) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.values[key]
+}
+
+var _ SpanWithValues = &valuesSpan{}
+
+func TestSpanValues(t *testing.T) {
+	span := &valuesSpan{}
+	ctx := ContextWithSpan(context.Background(), span)
+
+	SetSpanValue(SpanFromContext(ctx), "shard", 7)
+	assert.Equal(t, 7, SpanValue(SpanFromContext(ctx), "shard"))
+	assert.Nil(t, SpanValue(SpanFromContext(ctx), "missing"))
+}
+
+func TestSpanValuesNotSupported(t *testing.T) {
+	span := defaultNoopSpan
+	SetSpanValue(span, "shard", 7) // 不应该panic
+	assert.Nil(t, SpanValue(span, "shard"))
+}
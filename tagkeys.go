@@ -0,0 +1,80 @@
+package opentracing
+
+// 以下是 OpenTracing 语义规范中定义的标准 tag 的键。
+// ext 包提供了带类型检查的设置方法（例如 ext.HTTPStatusCode.Set(span, 200)），
+// 这些常量则用于 Tracer 的实现和不依赖 ext 包的埋点代码，以保证双方使用完全相同的字符串。
+const (
+	// TagSpanKind 表示 Span 在一次交互中扮演的角色，取值为 "client"、"server"、
+	// "producer" 或 "consumer"。
+	TagSpanKind = "span.kind"
+
+	// TagComponent 是产生该 Span 的软件包、框架或库的名称，例如 "grpc"、"django"。
+	TagComponent = "component"
+
+	// TagError 为 true 时表示该 Span 代表的操作失败了。
+	TagError = "error"
+
+	// TagSamplingPriority 是对 Tracer 的采样提示，大于 0 表示应该采样，等于 0 表示不应该采样。
+	TagSamplingPriority = SamplingPriorityTagKey
+
+	// TagPeerService 是远端服务的名称，用于客户端一侧描述被调用的服务。
+	TagPeerService = "peer.service"
+
+	// TagPeerAddress 是远端的地址，可以是 "ip:port"、主机名或者其他格式（例如 JDBC 连接串）。
+	TagPeerAddress = "peer.address"
+
+	// TagPeerHostname 是远端的主机名。
+	TagPeerHostname = "peer.hostname"
+
+	// TagPeerHostIPv4 是远端的 IPv4 地址。
+	TagPeerHostIPv4 = "peer.ipv4"
+
+	// TagPeerHostIPv6 是远端的 IPv6 地址。
+	TagPeerHostIPv6 = "peer.ipv6"
+
+	// TagPeerPort 是远端的端口。
+	TagPeerPort = "peer.port"
+
+	// TagHTTPURL 是该 Span 处理的请求的 URL。
+	TagHTTPURL = "http.url"
+
+	// TagHTTPMethod 是请求的 HTTP 方法，例如 "GET"、"POST"。
+	TagHTTPMethod = "http.method"
+
+	// TagHTTPStatusCode 是响应的 HTTP 状态码。
+	TagHTTPStatusCode = "http.status_code"
+
+	// TagDBInstance 是数据库实例的名称，例如 MySQL 中的 database 名。
+	TagDBInstance = "db.instance"
+
+	// TagDBStatement 是在数据库上执行的语句，例如 SQL 语句或者 Redis 命令。
+	TagDBStatement = "db.statement"
+
+	// TagDBType 是数据库的类型：对于任何 SQL 数据库为 "sql"，
+	// 否则为小写的数据库类别名称，例如 "cassandra"、"hbase"、"redis"。
+	TagDBType = "db.type"
+
+	// TagDBUser 是访问数据库的用户名。
+	TagDBUser = "db.user"
+
+	// TagMessageBusDestination 是消息的生产者或消费者使用的地址，例如 Kafka 的 topic。
+	TagMessageBusDestination = "message_bus.destination"
+)
+
+// 以下是 OpenTracing 语义规范中定义的标准日志字段的键，与 log 包中的同名常量相同。
+const (
+	// LogKeyEvent 描述了一个独立的、值得记录的事件，例如 "error"、"retry"。
+	LogKeyEvent = "event"
+
+	// LogKeyMessage 是简明的、人类可读的一行文字说明。
+	LogKeyMessage = "message"
+
+	// LogKeyStack 是与当前进程相关的调用栈信息，格式由语言决定。
+	LogKeyStack = "stack"
+
+	// LogKeyErrorKind 是错误的类型或"种类"，例如 "Exception"、"OSError"。
+	LogKeyErrorKind = "error.kind"
+
+	// LogKeyErrorObject 是错误对象本身，例如一个 error 值。
+	LogKeyErrorObject = "error.object"
+)
@@ -0,0 +1,41 @@
+package opentracing_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+)
+
+func TestTagKeysMatchExt(t *testing.T) {
+	for key, want := range map[string]string{
+		opentracing.TagSpanKind:              string(ext.SpanKind),
+		opentracing.TagComponent:             string(ext.Component),
+		opentracing.TagError:                 string(ext.Error),
+		opentracing.TagSamplingPriority:      string(ext.SamplingPriority),
+		opentracing.TagPeerService:           string(ext.PeerService),
+		opentracing.TagPeerAddress:           string(ext.PeerAddress),
+		opentracing.TagPeerHostname:          string(ext.PeerHostname),
+		opentracing.TagPeerHostIPv4:          string(ext.PeerHostIPv4),
+		opentracing.TagPeerHostIPv6:          string(ext.PeerHostIPv6),
+		opentracing.TagPeerPort:              string(ext.PeerPort),
+		opentracing.TagHTTPURL:               string(ext.HTTPUrl),
+		opentracing.TagHTTPMethod:            string(ext.HTTPMethod),
+		opentracing.TagHTTPStatusCode:        string(ext.HTTPStatusCode),
+		opentracing.TagDBInstance:            string(ext.DBInstance),
+		opentracing.TagDBStatement:           string(ext.DBStatement),
+		opentracing.TagDBType:                string(ext.DBType),
+		opentracing.TagDBUser:                string(ext.DBUser),
+		opentracing.TagMessageBusDestination: string(ext.MessageBusDestination),
+		opentracing.LogKeyEvent:              log.EventKey,
+		opentracing.LogKeyMessage:            log.MessageKey,
+		opentracing.LogKeyStack:              log.StackKey,
+		opentracing.LogKeyErrorKind:          log.ErrorKindKey,
+		opentracing.LogKeyErrorObject:        log.ErrorObjectKey,
+	} {
+		assert.Equal(t, want, key)
+	}
+}
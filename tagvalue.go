@@ -0,0 +1,166 @@
+package opentracing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// NormalizeTagValue 把 v 转换为链路追踪后端普遍支持的标量 tag 值。
+//
+// 字符串、布尔值和数字（包括底层类型是它们的自定义类型，例如 ext.SpanKindEnum）以及nil会原样返回；
+// 实现了`error`或 fmt.Stringer 的值转换为 Error() 或 String() 的结果；
+// 其他的值（struct、slice、map 等）尽可能序列化为JSON字符串，否则使用`fmt.Sprintf("%v", v)`。
+//
+// Tracer 的实现可以在 SetTag 和 StartSpan 的入口调用它，以获得一致的行为。
+func NormalizeTagValue(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	switch reflect.TypeOf(v).Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return v
+	}
+	switch t := v.(type) {
+	case error:
+		return t.Error()
+	case fmt.Stringer:
+		return t.String()
+	}
+	if b, err := json.Marshal(v); err == nil {
+		return string(b)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// NormalizeTags 返回一个新的 map，其中每一个值都经过了 NormalizeTagValue 的处理。
+// 如果 tags 为nil，则返回nil。
+func NormalizeTags(tags map[string]interface{}) map[string]interface{} {
+	if tags == nil {
+		return nil
+	}
+	normalized := make(map[string]interface{}, len(tags))
+	for k, v := range tags {
+		normalized[k] = NormalizeTagValue(v)
+	}
+	return normalized
+}
+
+// ErrUnsupportedTagValue 由 NewTagValue 和 SetTagStrict 在值无法转换为 TagValue 时返回（被包装的）的错误。
+var ErrUnsupportedTagValue = errors.New("opentracing: unsupported tag value")
+
+// TagValueKind 是 TagValue 中保存的值的类型。
+type TagValueKind int
+
+const (
+	// StringTagValueKind 表示 string 类型的值
+	StringTagValueKind TagValueKind = iota
+	// BoolTagValueKind 表示 bool 类型的值
+	BoolTagValueKind
+	// Int64TagValueKind 表示 int64 类型的值
+	Int64TagValueKind
+	// Float64TagValueKind 表示 float64 类型的值
+	Float64TagValueKind
+)
+
+// TagValue 是 string、bool、int64 和 float64 之一的 tag 值，这四种类型被所有链路追踪后端一致地支持。
+// 它的零值是空字符串。
+type TagValue struct {
+	kind TagValueKind
+	str  string
+	num  int64
+	fl   float64
+}
+
+// StringTagValue 返回一个 string 类型的 TagValue。
+func StringTagValue(v string) TagValue {
+	return TagValue{kind: StringTagValueKind, str: v}
+}
+
+// BoolTagValue 返回一个 bool 类型的 TagValue。
+func BoolTagValue(v bool) TagValue {
+	var n int64
+	if v {
+		n = 1
+	}
+	return TagValue{kind: BoolTagValueKind, num: n}
+}
+
+// Int64TagValue 返回一个 int64 类型的 TagValue。
+func Int64TagValue(v int64) TagValue {
+	return TagValue{kind: Int64TagValueKind, num: v}
+}
+
+// Float64TagValue 返回一个 float64 类型的 TagValue。
+func Float64TagValue(v float64) TagValue {
+	return TagValue{kind: Float64TagValueKind, fl: v}
+}
+
+// Kind 返回值的类型。
+func (v TagValue) Kind() TagValueKind {
+	return v.kind
+}
+
+// Interface 以 string、bool、int64 或 float64 的形式返回值，可以直接传给 Span.SetTag。
+func (v TagValue) Interface() interface{} {
+	switch v.kind {
+	case BoolTagValueKind:
+		return v.num != 0
+	case Int64TagValueKind:
+		return v.num
+	case Float64TagValueKind:
+		return v.fl
+	}
+	return v.str
+}
+
+// NewTagValue 把 v 转换为一个 TagValue，类型的优先级与 NormalizeTagValue 相同：
+//
+// 底层类型是字符串、布尔值、整数或浮点数的值（包括 time.Duration 这样同时实现了 fmt.Stringer 的类型）
+// 分别转换为 string、bool、int64 和 float64，超出 int64 范围的无符号整数会返回错误；
+// 其他实现了`error`或 fmt.Stringer 的值转换为 Error() 或 String() 的结果。
+// 其余的值（nil、struct、slice、map 等）会返回一个包装了 ErrUnsupportedTagValue 的错误。
+func NewTagValue(v interface{}) (TagValue, error) {
+	if v == nil {
+		return TagValue{}, fmt.Errorf("%w: nil", ErrUnsupportedTagValue)
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		return StringTagValue(rv.String()), nil
+	case reflect.Bool:
+		return BoolTagValue(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Int64TagValue(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u := rv.Uint(); u <= math.MaxInt64 {
+			return Int64TagValue(int64(u)), nil
+		}
+		return TagValue{}, fmt.Errorf("%w: %v overflows int64", ErrUnsupportedTagValue, v)
+	case reflect.Float32, reflect.Float64:
+		return Float64TagValue(rv.Float()), nil
+	}
+	switch t := v.(type) {
+	case error:
+		return StringTagValue(t.Error()), nil
+	case fmt.Stringer:
+		return StringTagValue(t.String()), nil
+	}
+	return TagValue{}, fmt.Errorf("%w: %T", ErrUnsupportedTagValue, v)
+}
+
+// SetTagStrict 用 NewTagValue 转换 value 之后把它设置为 span 的 tag，使不同的 Tracer 对相同的值有一致的行为。
+// 如果 value 无法转换，tag 不会被设置，并返回 NewTagValue 的错误。
+func SetTagStrict(span Span, key string, value interface{}) error {
+	tv, err := NewTagValue(value)
+	if err != nil {
+		return fmt.Errorf("tag %q: %w", key, err)
+	}
+	span.SetTag(key, tv.Interface())
+	return nil
+}
@@ -0,0 +1,124 @@
+package opentracing
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type kindEnum string
+
+func TestNormalizeTagValue(t *testing.T) {
+	type point struct {
+		X, Y int
+	}
+	testCases := []struct {
+		value    interface{}
+		expected interface{}
+	}{
+		{nil, nil},
+		{"s", "s"},
+		{true, true},
+		{42, 42},
+		{uint16(8080), uint16(8080)},
+		{1.5, 1.5},
+		{kindEnum("client"), kindEnum("client")},
+		{point{1, 2}, `{"X":1,"Y":2}`},
+		{&point{1, 2}, `{"X":1,"Y":2}`},
+		{[]string{"a", "b"}, `["a","b"]`},
+		{map[string]int{"a": 1}, `{"a":1}`},
+		{errors.New("boom"), "boom"},
+		{time.Second, time.Second}, // 底层类型是 int64
+		{make(chan int), nil},
+	}
+	for _, tc := range testCases {
+		got := NormalizeTagValue(tc.value)
+		if tc.expected == nil && tc.value != nil {
+			// 无法序列化为JSON的值使用 %v
+			assert.IsType(t, "", got, "%T", tc.value)
+			continue
+		}
+		assert.Equal(t, tc.expected, got, "%T", tc.value)
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	assert.Nil(t, NormalizeTags(nil))
+
+	tags := map[string]interface{}{"n": 1, "s": []int{1, 2}}
+	assert.Equal(t, map[string]interface{}{"n": 1, "s": "[1,2]"}, NormalizeTags(tags))
+	assert.Equal(t, []int{1, 2}, tags["s"], "the input map must not be modified")
+}
+
+func TestNewTagValue(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		kind TagValueKind
+		want interface{}
+	}{
+		{"s", StringTagValueKind, "s"},
+		{kindEnum("client"), StringTagValueKind, "client"},
+		{true, BoolTagValueKind, true},
+		{int8(-3), Int64TagValueKind, int64(-3)},
+		{uint32(7), Int64TagValueKind, int64(7)},
+		{float32(1.5), Float64TagValueKind, 1.5},
+		{1500 * time.Millisecond, Int64TagValueKind, int64(1500 * time.Millisecond)},
+		{stringerStruct{}, StringTagValueKind, "stringer"},
+		{errors.New("boom"), StringTagValueKind, "boom"},
+	}
+	for _, tt := range tests {
+		tv, err := NewTagValue(tt.in)
+		if assert.NoError(t, err, "%#v", tt.in) {
+			assert.Equal(t, tt.kind, tv.Kind(), "%#v", tt.in)
+			assert.Equal(t, tt.want, tv.Interface(), "%#v", tt.in)
+		}
+	}
+
+	for _, in := range []interface{}{nil, uint64(math.MaxUint64), []int{1}, struct{}{}} {
+		_, err := NewTagValue(in)
+		assert.True(t, errors.Is(err, ErrUnsupportedTagValue), "%#v: %v", in, err)
+	}
+
+	assert.Equal(t, "", TagValue{}.Interface())
+}
+
+// stringerStruct 是一个底层类型不是标量的 fmt.Stringer
+type stringerStruct struct{}
+
+func (stringerStruct) String() string { return "stringer" }
+
+// NewTagValue 和 NormalizeTagValue 对同一个值应该得到相同的结果
+func TestNewTagValueMatchesNormalizeTagValue(t *testing.T) {
+	for _, in := range []interface{}{
+		"s", kindEnum("client"), true, int8(-3), uint32(7), 1.5,
+		1500 * time.Millisecond, stringerStruct{}, errors.New("boom"),
+	} {
+		tv, err := NewTagValue(in)
+		require.NoError(t, err, "%#v", in)
+		assert.EqualValues(t, NormalizeTagValue(in), tv.Interface(), "%#v", in)
+	}
+}
+
+func TestSetTagStrict(t *testing.T) {
+	span := &tagRecordingSpan{tags: map[string]interface{}{}}
+	assert.NoError(t, SetTagStrict(span, "timeout", 2*time.Second))
+	assert.NoError(t, SetTagStrict(span, "retries", 3))
+	err := SetTagStrict(span, "payload", map[string]int{"a": 1})
+	assert.True(t, errors.Is(err, ErrUnsupportedTagValue))
+	assert.Equal(t, map[string]interface{}{"timeout": int64(2 * time.Second), "retries": int64(3)}, span.tags)
+}
+
+// tagRecordingSpan 记录 SetTag 设置的 tag
+type tagRecordingSpan struct {
+	noopSpan
+	tags map[string]interface{}
+}
+
+func (s *tagRecordingSpan) SetTag(key string, value interface{}) Span {
+	s.tags[key] = value
+	return s
+}
@@ -1,6 +1,7 @@
 package opentracing
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -88,8 +89,12 @@ func (n testTracer) StartSpan(operationName string, opts ...StartSpanOption) Spa
 
 func (n testTracer) startSpanWithOptions(name string, opts StartSpanOptions) Span {
 	fakeID := nextFakeID()
-	if len(opts.References) > 0 {
-		fakeID = opts.References[0].ReferencedContext.(testSpanContext).FakeID
+	if !opts.IsRoot() {
+		parents := opts.ChildOfReferences()
+		if len(parents) == 0 {
+			parents = opts.FollowsFromReferences()
+		}
+		fakeID = parents[0].(testSpanContext).FakeID
 	}
 
 	return testSpan{
@@ -97,7 +102,7 @@ func (n testTracer) startSpanWithOptions(name string, opts StartSpanOptions) Spa
 		StartTime:     opts.StartTime,
 		Tags:          opts.Tags,
 		spanContext: testSpanContext{
-			HasParent: len(opts.References) > 0,
+			HasParent: !opts.IsRoot(),
 			FakeID:    fakeID,
 		},
 	}
@@ -111,7 +116,7 @@ func (n testTracer) Inject(sp SpanContext, format interface{}, carrier interface
 		carrier.(TextMapWriter).Set(testHTTPHeaderPrefix+"fakeid", strconv.Itoa(spanContext.FakeID))
 		return nil
 	}
-	return ErrUnsupportedFormat
+	return fmt.Errorf("%w: %v", ErrUnsupportedFormat, format)
 }
 
 // Extract 实现 Tracer 接口
@@ -125,7 +130,7 @@ func (n testTracer) Extract(format interface{}, carrier interface{}) (SpanContex
 			case testHTTPHeaderPrefix + "fakeid":
 				i, err := strconv.Atoi(val)
 				if err != nil {
-					return err
+					return fmt.Errorf("%w: key %q: %v", ErrSpanContextCorrupted, key, err)
 				}
 				sm.FakeID = i
 			}
@@ -1,6 +1,13 @@
 package opentracing
 
-import "time"
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
 
 // Tracer 是一个简单，轻量的用于创建 Span 和传递 SpanContext 的接口。
 type Tracer interface {
@@ -98,6 +105,15 @@ type Tracer interface {
 	Extract(format interface{}, carrier interface{}) (SpanContext, error)
 }
 
+// StartSpanE 在 tracer 实现了 ErrorableTracer 时调用它的 StartSpanE，
+// 否则调用 tracer.StartSpan 并返回一个空(nil)的错误。
+func StartSpanE(tracer Tracer, operationName string, opts ...StartSpanOption) (Span, error) {
+	if t, ok := tracer.(ErrorableTracer); ok {
+		return t.StartSpanE(operationName, opts...)
+	}
+	return tracer.StartSpan(operationName, opts...), nil
+}
+
 // StartSpanOptions 允许 Tracer.StartSpan 通过在调用中传递本结构体来实现某种机制，
 // 比如覆盖Span开始时间的时间戳，指定一个Span的关联(Span References)，以及使一个或
 // 多个Tag在Span开始时可用
@@ -125,8 +141,305 @@ type StartSpanOptions struct {
 
 	// Tags 可能包含多个值；对该 map 的值的限制与 Span.SetTag() 相同。该字段可能会为nil
 	//
+	// NewStartSpanOptions 会把少量的 tag 暂存在 StartSpanOptions 内部而不写入该字段，
+	// 所以通过它得到选项的 Tracer 应该使用 TagsMap（或者 CloneTags、SamplingPriority）读取 tag。
+	//
 	// 在StartSpan调用之后请不要在其他地方使用该值
 	Tags map[string]interface{}
+
+	// OperationNameCallback 如果不为nil，Tracer 的实现应该在 Span 结束(Finish)时以当时的操作名调用它，
+	// 并以它的返回值作为最终的操作名。见 WithOperationNameCallback 和 ResolveOperationName。
+	OperationNameCallback func(current string) string
+
+	// Baggage 包含新 Span 初始的携带数据(baggage)，该字段可能为nil。
+	// Tracer 的实现应该在创建 Span 之后把其中的每一项通过 SetBaggageItem 设置到 Span 上，见 WithBaggage 和 ApplyBaggage。
+	Baggage map[string]string
+
+	// inlineTags 暂存了 NewStartSpanOptions 中的前 numInline 个 tag，它们比 Tags 中的任何一项都更早写入，
+	// 这样只有少量 tag 时不需要创建 map。见 TagsMap。
+	inlineTags [maxInlineTags]inlineTag
+	numInline  int
+}
+
+// maxInlineTags 是 NewStartSpanOptions 不创建 Tags map 就能保存的 tag 的数量。
+const maxInlineTags = 2
+
+// inlineTag 是暂存在 StartSpanOptions 中的一个 tag。StringTag、IntTag、BoolTag 和 Float64Tag 的值
+// 以原类型保存在 str 或 num 中，直到 Value 被调用时才装箱成 interface{}。
+type inlineTag struct {
+	key   string
+	kind  inlineTagKind
+	value interface{}
+	str   string
+	num   uint64
+}
+
+type inlineTagKind uint8
+
+const (
+	interfaceInlineTag inlineTagKind = iota
+	stringInlineTag
+	intInlineTag
+	boolInlineTag
+	float64InlineTag
+)
+
+// Value 返回 tag 的值。
+func (t inlineTag) Value() interface{} {
+	switch t.kind {
+	case stringInlineTag:
+		return t.str
+	case intInlineTag:
+		return int(int64(t.num))
+	case boolInlineTag:
+		return t.num != 0
+	case float64InlineTag:
+		return math.Float64frombits(t.num)
+	}
+	return t.value
+}
+
+// NewStartSpanOptions 把 opts 依次应用到一个新的 StartSpanOptions 上并返回它，与上面的循环等价，
+// 但它会先根据 opts 中的 SpanReference、Tag 和 Tags 预估 References 和 Tags 的容量并一次性分配，
+// 避免在逐个应用时切片和 map 的多次扩容；对于这些内置的选项，StartSpanOptions 本身也不会被分配到堆上。
+//
+// 不超过 2 个的 Tag（没有 Tags 选项时）会暂存在 StartSpanOptions 内部，Tags 字段保持为nil，
+// 直到 Tracer 调用 TagsMap 时才创建 map。对于常见的“ChildOf + 2 个 Tag”，BenchmarkStartSpanOptionsApply
+// 测得逐个 Apply 为 4 次分配，使用 NewStartSpanOptions 只有 References 的底层数组这 1 次。
+//
+// Tracer 的实现应该在热路径上使用它，并通过 TagsMap 而不是 Tags 字段读取 tag：
+//
+//     func StartSpan(opName string, opts ...opentracing.StartSpanOption) {
+//         sso := opentracing.NewStartSpanOptions(opts...)
+//         tags := sso.TagsMap()
+//         ...
+//     }
+//
+func NewStartSpanOptions(opts ...StartSpanOption) StartSpanOptions {
+	var refs, tags, mapped int
+	for _, o := range opts {
+		switch o := o.(type) {
+		case SpanReference:
+			if o.ReferencedContext != nil {
+				refs++
+			}
+		case Tag, StringTag, IntTag, BoolTag, Float64Tag:
+			tags++
+		case Tags:
+			mapped += len(o)
+		}
+	}
+	sso := StartSpanOptions{}
+	if refs > 0 {
+		sso.References = make([]SpanReference, 0, refs)
+	}
+	if mapped > 0 || tags > maxInlineTags {
+		sso.Tags = make(map[string]interface{}, tags+mapped)
+	}
+	for _, o := range opts {
+		// 对常见的选项静态地调用 Apply，使 sso 不会逃逸到堆上
+		switch o := o.(type) {
+		case SpanReference:
+			o.Apply(&sso)
+		case Tag:
+			sso.addTag(inlineTag{key: o.Key, value: o.Value})
+		case StringTag:
+			sso.addTag(inlineTag{key: o.K, kind: stringInlineTag, str: o.V})
+		case IntTag:
+			sso.addTag(inlineTag{key: o.K, kind: intInlineTag, num: uint64(o.V)})
+		case BoolTag:
+			var num uint64
+			if o.V {
+				num = 1
+			}
+			sso.addTag(inlineTag{key: o.K, kind: boolInlineTag, num: num})
+		case Float64Tag:
+			sso.addTag(inlineTag{key: o.K, kind: float64InlineTag, num: math.Float64bits(o.V)})
+		case Tags:
+			o.Apply(&sso)
+		case StartTime:
+			o.Apply(&sso)
+		default:
+			applied := sso
+			o.Apply(&applied)
+			sso = applied
+		}
+	}
+	return sso
+}
+
+// addTag 在 Tags 为nil并且还有空间时把 t 暂存在 inlineTags 中，否则把它写入 Tags。
+func (o *StartSpanOptions) addTag(t inlineTag) {
+	if o.Tags == nil {
+		for i := range o.inlineTags[:o.numInline] {
+			if o.inlineTags[i].key == t.key {
+				o.inlineTags[i] = t
+				return
+			}
+		}
+		if o.numInline < maxInlineTags {
+			o.inlineTags[o.numInline] = t
+			o.numInline++
+			return
+		}
+	}
+	o.TagsMap()[t.key] = t.Value()
+}
+
+// TagsMap 把 NewStartSpanOptions 暂存在 StartSpanOptions 内部的 tag 写入 Tags 并返回 Tags，
+// 如果没有任何 tag，则返回nil。对同一个 StartSpanOptions 多次调用会返回同一个 map。
+//
+// 与直接读取 Tags 字段一样，返回的 map 可能与调用者传入的 Tags 是同一个对象，见 CloneTags。
+func (o *StartSpanOptions) TagsMap() map[string]interface{} {
+	if o.numInline == 0 {
+		return o.Tags
+	}
+	if o.Tags == nil {
+		o.Tags = make(map[string]interface{}, o.numInline)
+	}
+	for _, t := range o.inlineTags[:o.numInline] {
+		// Tags 中已有的值是在暂存的 tag 之后写入的，应该保留
+		if _, ok := o.Tags[t.key]; !ok {
+			o.Tags[t.key] = t.Value()
+		}
+	}
+	o.inlineTags = [maxInlineTags]inlineTag{}
+	o.numInline = 0
+	return o.Tags
+}
+
+// tag 返回 key 对应的 tag 值，包括暂存在 StartSpanOptions 内部的 tag。
+func (o StartSpanOptions) tag(key string) (interface{}, bool) {
+	if v, ok := o.Tags[key]; ok {
+		return v, true
+	}
+	for _, t := range o.inlineTags[:o.numInline] {
+		if t.key == key {
+			return t.Value(), true
+		}
+	}
+	return nil, false
+}
+
+// ResolveOperationName 返回以 OperationNameCallback 调整后的最终操作名，如果没有设置回调，则原样返回 current。
+//
+// 该方法用于 Tracer 的实现，应该在 Span 结束时调用。
+func (o StartSpanOptions) ResolveOperationName(current string) string {
+	if o.OperationNameCallback == nil {
+		return current
+	}
+	return o.OperationNameCallback(current)
+}
+
+// CloneTags 返回 Tags（包括暂存在 StartSpanOptions 内部的 tag，见 TagsMap）的一份独立的浅拷贝，
+// 如果没有任何 tag 并且 Tags 为nil，则返回nil。
+//
+// 当调用者直接传入 `StartSpanOptions{Tags: myMap}` 之类的值时，Tags 与调用者的 map 是同一个对象，
+// 所以需要在 StartSpan 调用之后继续持有 Tags 的 Tracer 实现应该使用该方法获取副本。
+func (o StartSpanOptions) CloneTags() map[string]interface{} {
+	if o.Tags == nil && o.numInline == 0 {
+		return nil
+	}
+	tags := make(map[string]interface{}, len(o.Tags)+o.numInline)
+	for k, v := range o.Tags {
+		tags[k] = v
+	}
+	for _, t := range o.inlineTags[:o.numInline] {
+		if _, ok := tags[t.key]; !ok {
+			tags[t.key] = t.Value()
+		}
+	}
+	return tags
+}
+
+// IsRoot 判断以该选项创建的 Span 是否是一个根Span，即 References 中没有任何非空(non-nil)的 SpanContext。
+func (o StartSpanOptions) IsRoot() bool {
+	for _, ref := range o.References {
+		if ref.ReferencedContext != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// ReferencesOfType 按顺序返回 References 中所有关联类型为 refType 的 SpanContext，空(nil)的 SpanContext 会被跳过。
+func (o StartSpanOptions) ReferencesOfType(refType SpanReferenceType) []SpanContext {
+	var scs []SpanContext
+	for _, ref := range o.References {
+		if ref.Type == refType && ref.ReferencedContext != nil {
+			scs = append(scs, ref.ReferencedContext)
+		}
+	}
+	return scs
+}
+
+// String 返回便于调试的可读摘要，包括引用的数量和各自的类型、StartTime（零值显示为`<now>`）
+// 以及按键排序后的 Tags，例如：
+//
+//    StartSpanOptions{refs=2[child_of, follows_from], start=<now>, tags={component=db, retry=3}}
+//
+func (o StartSpanOptions) String() string {
+	refTypes := make([]string, len(o.References))
+	for i, ref := range o.References {
+		refTypes[i] = ref.Type.String()
+	}
+	start := "<now>"
+	if !o.StartTime.IsZero() {
+		start = o.StartTime.Format(time.RFC3339Nano)
+	}
+	tags := make([]string, 0, len(o.Tags)+o.numInline)
+	for k, v := range o.CloneTags() {
+		tags = append(tags, fmt.Sprintf("%s=%v", k, v))
+	}
+	sort.Strings(tags)
+	return fmt.Sprintf("StartSpanOptions{refs=%d[%s], start=%s, tags={%s}}",
+		len(o.References), strings.Join(refTypes, ", "), start, strings.Join(tags, ", "))
+}
+
+// ChildOfReferences 等同于 ReferencesOfType(ChildOfRef)。
+func (o StartSpanOptions) ChildOfReferences() []SpanContext {
+	return o.ReferencesOfType(ChildOfRef)
+}
+
+// FollowsFromReferences 等同于 ReferencesOfType(FollowsFromRef)。
+func (o StartSpanOptions) FollowsFromReferences() []SpanContext {
+	return o.ReferencesOfType(FollowsFromRef)
+}
+
+// ValidateReferences 检查 References 中是否有多个关联指向了同一个 SpanContext（无论关联类型是否相同），
+// 如果有，则返回一个描述了所有重复关联的错误，否则返回nil。空(nil)的 SpanContext 会被跳过。
+func (o StartSpanOptions) ValidateReferences() error {
+	var duplicates []string
+	for i, ref := range o.References {
+		if ref.ReferencedContext == nil {
+			continue
+		}
+		for j := 0; j < i; j++ {
+			if sameSpanContext(o.References[j].ReferencedContext, ref.ReferencedContext) {
+				duplicates = append(duplicates, fmt.Sprintf("#%d duplicates #%d", i, j))
+				break
+			}
+		}
+	}
+	if len(duplicates) > 0 {
+		return fmt.Errorf("opentracing: duplicate span references: %v", duplicates)
+	}
+	return nil
+}
+
+// sameSpanContext 判断 a 和 b 是否相等。SpanContext 的实现可能是不可比较的类型（例如包含 map），
+// 这种情况下使用 reflect.DeepEqual 以避免 `==` 引起panic。
+func sameSpanContext(a, b SpanContext) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if reflect.TypeOf(a) != reflect.TypeOf(b) {
+		return false
+	}
+	if reflect.TypeOf(a).Comparable() {
+		return a == b
+	}
+	return reflect.DeepEqual(a, b)
 }
 
 // StartSpanOption 接口的实例可能会传给 Tracer.StartSpan.
@@ -184,6 +497,17 @@ const (
 	FollowsFromRef
 )
 
+// String 返回引用类型的名字：`child_of`、`follows_from`，未知的值返回`unknown(N)`。
+func (r SpanReferenceType) String() string {
+	switch r {
+	case ChildOfRef:
+		return "child_of"
+	case FollowsFromRef:
+		return "follows_from"
+	}
+	return fmt.Sprintf("unknown(%d)", int(r))
+}
+
 // SpanReference 是一个 StartSpanOption，包含了另一个 SpanContext 及该 Span 与另一个 Span 的关系。
 // 在 SpanReferenceType 的文档详见支持的关系类型。如果 SpanReference 的值为空，则该结构体不起作用。
 // 因此它允许使用一个更简单的方法来开始一个新的Span：
@@ -195,6 +519,35 @@ const (
 type SpanReference struct {
 	Type              SpanReferenceType
 	ReferencedContext SpanContext
+
+	// Attributes 是该引用本身的附加信息（类似 OpenTelemetry 中 link 的属性），例如批量消费时每条消息的 id 或分区，
+	// 可以为nil。它们描述的是引用关系而不是 Span，因此与 StartSpanOptions.Tags 相互独立。
+	//
+	// 不支持引用属性的 Tracer 可以忽略该字段。见 ChildOfWithTags 和 FollowsFromWithTags。
+	//
+	// 使用指针是为了让 SpanReference 仍然可以用 == 比较、作为 map 的键；两个 SpanReference
+	// 只有在 Attributes 指向同一个 ReferenceAttributes 时才相等。
+	Attributes *ReferenceAttributes
+}
+
+// ReferenceAttributes 是 SpanReference 的附加信息，见 SpanReference.Attributes。
+type ReferenceAttributes struct {
+	Tags map[string]interface{}
+}
+
+// String 返回引用类型和被引用的 SpanContext 的携带数据(baggage)的摘要，按键排序，
+// 例如`child_of{tenant=a, user_id=1}`。如果 ReferencedContext 为空(nil)，返回例如`child_of(nil)`。
+func (r SpanReference) String() string {
+	if r.ReferencedContext == nil {
+		return r.Type.String() + "(nil)"
+	}
+	baggage := baggageMap(r.ReferencedContext)
+	pairs := make([]string, 0, len(baggage))
+	for k, v := range baggage {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return r.Type.String() + "{" + strings.Join(pairs, ", ") + "}"
 }
 
 // Apply 实现 StartSpanOption 接口
@@ -227,6 +580,99 @@ func FollowsFrom(sc SpanContext) SpanReference {
 	}
 }
 
+// ChildOfWithTags 与 ChildOf 相同，但为该引用附加 tags 作为 SpanReference.Attributes.Tags。
+// tags 为nil时 Attributes 也为nil。tags 会被复制，调用之后修改 tags 不会影响返回的 SpanReference。
+func ChildOfWithTags(sc SpanContext, tags Tags) SpanReference {
+	return SpanReference{
+		Type:              ChildOfRef,
+		ReferencedContext: sc,
+		Attributes:        copyReferenceAttributes(tags),
+	}
+}
+
+// FollowsFromWithTags 与 FollowsFrom 相同，但为该引用附加 tags 作为 SpanReference.Attributes.Tags，
+// 例如批量消费消息时记录每条消息的 id：
+//
+//     refs = append(refs, opentracing.FollowsFromWithTags(msg.SpanContext,
+//         opentracing.Tags{"message.id": msg.ID, "partition": msg.Partition}))
+//
+// tags 为nil时 Attributes 也为nil。tags 会被复制，调用之后修改 tags 不会影响返回的 SpanReference。
+func FollowsFromWithTags(sc SpanContext, tags Tags) SpanReference {
+	return SpanReference{
+		Type:              FollowsFromRef,
+		ReferencedContext: sc,
+		Attributes:        copyReferenceAttributes(tags),
+	}
+}
+
+func copyReferenceAttributes(tags Tags) *ReferenceAttributes {
+	if tags == nil {
+		return nil
+	}
+	attrs := make(map[string]interface{}, len(tags))
+	for k, v := range tags {
+		attrs[k] = v
+	}
+	return &ReferenceAttributes{Tags: attrs}
+}
+
+// ChildOfAll 返回一个`StartSpanOption`，它一次性把所有非空(non-nil)的 sc 以 ChildOfRef 的关系加入 References，
+// 空(nil)的 sc 会被跳过。
+//
+// 可以看看 ChildOf, FollowsFromAll
+func ChildOfAll(scs ...SpanContext) StartSpanOption {
+	return newSpanReferences(ChildOfRef, scs)
+}
+
+// FollowsFromAll 返回一个`StartSpanOption`，它一次性把所有非空(non-nil)的 sc 以 FollowsFromRef 的关系加入 References，
+// 空(nil)的 sc 会被跳过。
+// 例如，一个消息队列的消费者可以用它把一条消息关联到多个上游生产者的Span上（fan-in）。
+//
+// 可以看看 FollowsFrom, ChildOfAll
+func FollowsFromAll(scs ...SpanContext) StartSpanOption {
+	return newSpanReferences(FollowsFromRef, scs)
+}
+
+// References 返回一个`StartSpanOption`，它一次性把 refs 中所有 ReferencedContext 非空(non-nil)的引用加入 References，
+// 可以混合不同的引用类型，例如批量处理消息的消费者同时引用多条消息的生产者：
+//
+//     refs := make([]opentracing.SpanReference, 0, len(msgs))
+//     for _, msg := range msgs {
+//         refs = append(refs, opentracing.FollowsFrom(msg.SpanContext))
+//     }
+//     span := tracer.StartSpan("consume.batch", opentracing.References(refs...))
+//
+// 可以看看 ChildOfAll, FollowsFromAll
+func References(refs ...SpanReference) StartSpanOption {
+	filtered := make(spanReferences, 0, len(refs))
+	for _, ref := range refs {
+		if ref.ReferencedContext != nil {
+			filtered = append(filtered, ref)
+		}
+	}
+	return filtered
+}
+
+// spanReferences 是一组 SpanReference，它作为一个整体实现了`StartSpanOption`接口。
+type spanReferences []SpanReference
+
+func newSpanReferences(refType SpanReferenceType, scs []SpanContext) spanReferences {
+	refs := make(spanReferences, 0, len(scs))
+	for _, sc := range scs {
+		if sc != nil {
+			refs = append(refs, SpanReference{Type: refType, ReferencedContext: sc})
+		}
+	}
+	return refs
+}
+
+// Apply 实现`StartSpanOption`接口.
+func (r spanReferences) Apply(o *StartSpanOptions) {
+	for _, ref := range r {
+		ref.Apply(o)
+	}
+}
+
 // StartTime 实现了`StartSpanOption`接口，用于对Span设置一个明确的开始时间
 type StartTime time.Time
 
@@ -235,6 +681,56 @@ func (t StartTime) Apply(o *StartSpanOptions) {
 	o.StartTime = time.Time(t)
 }
 
+// WithOperationNameCallback 返回一个设置 StartSpanOptions.OperationNameCallback 的 StartSpanOption。
+//
+// 这使得框架可以在路由确定之前就把 Span 传递下去，并由应用在 Span 结束时决定最终的操作名，
+// 而不是在之后调用 SetOperationName（某些后端在采样决策时无法很好地处理改名）。
+// 回调收到的是结束时的操作名，包括之前通过 SetOperationName 设置的名字。
+//
+// 空操作(no-op)的 Tracer 会忽略该选项。
+func WithOperationNameCallback(fn func(current string) string) StartSpanOption {
+	return operationNameCallback(fn)
+}
+
+type operationNameCallback func(current string) string
+
+// Apply 实现`StartSpanOption`接口.
+func (fn operationNameCallback) Apply(o *StartSpanOptions) {
+	o.OperationNameCallback = fn
+}
+
+// WithBaggage 返回一个把`items`加入 StartSpanOptions.Baggage 的 StartSpanOption，
+// 这使得新的 Span 在创建时就带有这些携带数据(baggage)，例如从消息头一次性恢复的一批携带数据。
+// 多次使用时会合并，相同的键以后面的为准。
+//
+// 不支持该字段的 Tracer 实现会忽略这些携带数据，调用者可以在 StartSpan 之后使用 ApplyBaggage 兼容它们。
+func WithBaggage(items map[string]string) StartSpanOption {
+	return baggageOption(items)
+}
+
+type baggageOption map[string]string
+
+// Apply 实现`StartSpanOption`接口.
+func (b baggageOption) Apply(o *StartSpanOptions) {
+	if len(b) == 0 {
+		return
+	}
+	if o.Baggage == nil {
+		o.Baggage = make(map[string]string, len(b))
+	}
+	for k, v := range b {
+		o.Baggage[k] = v
+	}
+}
+
+// ApplyBaggage 把`o.Baggage`中的每一项通过 SetBaggageItem 设置到 span 上。
+// Tracer 的实现可以在 StartSpan 中创建 Span 之后调用它。
+func ApplyBaggage(span Span, o StartSpanOptions) {
+	for k, v := range o.Baggage {
+		span.SetBaggageItem(k, v)
+	}
+}
+
 // Tags 是一个通用的map，是string到不透明类型值(interface)的映射，
 // 底层的链路追踪系统负责解释和序列化该Tag
 type Tags map[string]interface{}
@@ -275,3 +771,82 @@ func (t Tag) Apply(o *StartSpanOptions) {
 func (t Tag) Set(s Span) {
 	s.SetTag(t.Key, t.Value)
 }
+
+// StringTag、IntTag、BoolTag 和 Float64Tag 是值类型确定的 Tag，用法与 Tag 相同：
+//
+//     tracer.StartSpan("opName", IntTag{"retry", 3}, BoolTag{"cache.hit", true})
+//
+// 除了让编译器检查值的类型之外，NewStartSpanOptions 会把它们的值以原类型暂存在 StartSpanOptions 内部，
+// 既不创建 Tags map，也不把值装箱成 interface{}，直到 Tracer 调用 TagsMap。作为 StartSpanOption 传递时
+// 结构体本身仍会被装箱，所以在 BenchmarkTypedTag 中 NewStartSpanOptions(IntTag{...}) 为 1 次分配，
+// 而 NewStartSpanOptions(Tag{...}) 还需要装箱值，为 2 次。直接调用 Apply 或 Set 时，值与 Tag 一样会被装箱。
+type StringTag struct {
+	K string
+	V string
+}
+
+// Apply 实现`StartSpanOption`接口.
+func (t StringTag) Apply(o *StartSpanOptions) {
+	setStartSpanTag(o, t.K, t.V)
+}
+
+// Set 会在一个已有的Span上添加新的tag
+func (t StringTag) Set(s Span) {
+	s.SetTag(t.K, t.V)
+}
+
+// IntTag 是值类型为 int 的 Tag，见 StringTag。
+type IntTag struct {
+	K string
+	V int
+}
+
+// Apply 实现`StartSpanOption`接口.
+func (t IntTag) Apply(o *StartSpanOptions) {
+	setStartSpanTag(o, t.K, t.V)
+}
+
+// Set 会在一个已有的Span上添加新的tag
+func (t IntTag) Set(s Span) {
+	s.SetTag(t.K, t.V)
+}
+
+// BoolTag 是值类型为 bool 的 Tag，见 StringTag。
+type BoolTag struct {
+	K string
+	V bool
+}
+
+// Apply 实现`StartSpanOption`接口.
+func (t BoolTag) Apply(o *StartSpanOptions) {
+	setStartSpanTag(o, t.K, t.V)
+}
+
+// Set 会在一个已有的Span上添加新的tag
+func (t BoolTag) Set(s Span) {
+	s.SetTag(t.K, t.V)
+}
+
+// Float64Tag 是值类型为 float64 的 Tag，见 StringTag。
+type Float64Tag struct {
+	K string
+	V float64
+}
+
+// Apply 实现`StartSpanOption`接口.
+func (t Float64Tag) Apply(o *StartSpanOptions) {
+	setStartSpanTag(o, t.K, t.V)
+}
+
+// Set 会在一个已有的Span上添加新的tag
+func (t Float64Tag) Set(s Span) {
+	s.SetTag(t.K, t.V)
+}
+
+// setStartSpanTag 把 key=value 写入 o.Tags，o.Tags 为 nil 时先创建它。
+func setStartSpanTag(o *StartSpanOptions, key string, value interface{}) {
+	if o.Tags == nil {
+		o.Tags = make(map[string]interface{})
+	}
+	o.Tags[key] = value
+}
@@ -0,0 +1,161 @@
+package opentracing
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	// TraceParentHeader 是 W3C Trace Context 中携带 trace id、parent id 和 trace flags 的 header 名。
+	TraceParentHeader = "traceparent"
+	// TraceStateHeader 是 W3C Trace Context 中携带各个厂商自己的链路数据的 header 名。
+	TraceStateHeader = "tracestate"
+)
+
+// TraceFlagSampled 是 TraceParent.Flags 中表示调用方对该链路进行了采样的位。
+const TraceFlagSampled byte = 0x01
+
+// TraceParent 是 W3C Trace Context 中`traceparent` header 的值，形如：
+//
+//     00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+//
+// 依次是版本(version)、trace id、parent id（即调用方的 span id）和 trace flags，均为小写的十六进制。
+type TraceParent struct {
+	Version  byte
+	TraceID  [16]byte
+	ParentID [8]byte
+	Flags    byte
+}
+
+// Sampled 返回 Flags 中是否设置了 TraceFlagSampled 位。
+func (tp TraceParent) Sampled() bool {
+	return tp.Flags&TraceFlagSampled != 0
+}
+
+// String 返回`traceparent` header 的值。
+func (tp TraceParent) String() string {
+	return fmt.Sprintf("%02x-%s-%s-%02x",
+		tp.Version, hex.EncodeToString(tp.TraceID[:]), hex.EncodeToString(tp.ParentID[:]), tp.Flags)
+}
+
+// TraceParentFromBasic 返回与 sc 对应的版本 00 的 TraceParent，ParentID 为 sc 的 span id。
+// 64位的 trace id 会在高位补零。traceparent 不携带携带数据(baggage)，sc 中的携带数据会被忽略。
+func TraceParentFromBasic(sc BasicSpanContext) TraceParent {
+	var tp TraceParent
+	binary.BigEndian.PutUint64(tp.TraceID[:8], sc.TraceIDHigh)
+	binary.BigEndian.PutUint64(tp.TraceID[8:], sc.TraceID)
+	binary.BigEndian.PutUint64(tp.ParentID[:], sc.SpanID)
+	if sc.Sampled {
+		tp.Flags = TraceFlagSampled
+	}
+	return tp
+}
+
+// ToBasic 返回与 tp 对应的 BasicSpanContext，它的 span id 为 tp.ParentID，是否采样由 TraceFlagSampled 决定。
+func (tp TraceParent) ToBasic() BasicSpanContext {
+	return BasicSpanContext{
+		TraceIDHigh: binary.BigEndian.Uint64(tp.TraceID[:8]),
+		TraceID:     binary.BigEndian.Uint64(tp.TraceID[8:]),
+		SpanID:      binary.BigEndian.Uint64(tp.ParentID[:]),
+		Sampled:     tp.Sampled(),
+	}
+}
+
+// ParseTraceParent 按照 W3C Trace Context 规范解析一个`traceparent` header 的值。
+//
+// 版本 00 的值必须恰好由四个部分组成；更高的版本允许在 trace flags 之后出现以`-`分隔的额外内容，它们会被忽略。
+// 不合法的值（包括版本 ff，以及全为零的 trace id 或 parent id）会返回一个包装了 ErrSpanContextCorrupted 的错误。
+func ParseTraceParent(s string) (TraceParent, error) {
+	var tp TraceParent
+	corrupted := func(reason string) (TraceParent, error) {
+		return TraceParent{}, fmt.Errorf("%w: traceparent %q: %s", ErrSpanContextCorrupted, s, reason)
+	}
+	// 00-<32个十六进制字符>-<16个十六进制字符>-<2个十六进制字符>
+	const length = 2 + 1 + 32 + 1 + 16 + 1 + 2
+	if len(s) < length || s[2] != '-' || s[35] != '-' || s[52] != '-' {
+		return corrupted("malformed")
+	}
+	if err := decodeLowerHex(tp.TraceID[:], s[3:35]); err != nil {
+		return corrupted("invalid trace id")
+	}
+	if err := decodeLowerHex(tp.ParentID[:], s[36:52]); err != nil {
+		return corrupted("invalid parent id")
+	}
+	var b [1]byte
+	if err := decodeLowerHex(b[:], s[0:2]); err != nil || b[0] == 0xff {
+		return corrupted("invalid version")
+	}
+	tp.Version = b[0]
+	if err := decodeLowerHex(b[:], s[53:55]); err != nil {
+		return corrupted("invalid trace flags")
+	}
+	tp.Flags = b[0]
+	if len(s) > length && (tp.Version == 0 || s[length] != '-') {
+		return corrupted("malformed")
+	}
+	if tp.TraceID == [16]byte{} {
+		return corrupted("all-zero trace id")
+	}
+	if tp.ParentID == [8]byte{} {
+		return corrupted("all-zero parent id")
+	}
+	return tp, nil
+}
+
+// decodeLowerHex 把由小写十六进制字符组成的 s 解码到 dst 中，W3C Trace Context 不允许大写。
+func decodeLowerHex(dst []byte, s string) error {
+	if strings.ToLower(s) != s {
+		return hex.InvalidByteError(s[0])
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err
+}
+
+// W3CTraceContextCarrier 是 W3CTraceContext 格式的载体(carrier)，它使用 http.Header 进行存储。
+//
+// 服务端用例:
+//
+//     carrier := opentracing.W3CTraceContextCarrier(httpReq.Header)
+//     clientContext, err := tracer.Extract(opentracing.W3CTraceContext, carrier)
+//
+// 客户端用例:
+//
+//     carrier := opentracing.W3CTraceContextCarrier(httpReq.Header)
+//     err := tracer.Inject(span.Context(), opentracing.W3CTraceContext, carrier)
+//
+type W3CTraceContextCarrier http.Header
+
+// TraceParent 解析并返回`traceparent` header。如果没有该 header，返回 ErrSpanContextNotFound，
+// 如果有多个该 header 或者它不合法，返回一个包装了 ErrSpanContextCorrupted 的错误。
+func (c W3CTraceContextCarrier) TraceParent() (TraceParent, error) {
+	vals := http.Header(c)[http.CanonicalHeaderKey(TraceParentHeader)]
+	switch len(vals) {
+	case 0:
+		return TraceParent{}, ErrSpanContextNotFound
+	case 1:
+		return ParseTraceParent(strings.TrimSpace(vals[0]))
+	}
+	return TraceParent{}, fmt.Errorf("%w: multiple traceparent headers", ErrSpanContextCorrupted)
+}
+
+// SetTraceParent 设置`traceparent` header。
+func (c W3CTraceContextCarrier) SetTraceParent(tp TraceParent) {
+	http.Header(c).Set(TraceParentHeader, tp.String())
+}
+
+// TraceState 返回`tracestate` header 的值，多个该 header 会按规范以逗号合并。如果没有该 header，返回空字符串。
+func (c W3CTraceContextCarrier) TraceState() string {
+	return strings.Join(http.Header(c)[http.CanonicalHeaderKey(TraceStateHeader)], ",")
+}
+
+// SetTraceState 设置`tracestate` header，如果 state 为空则删除该 header。
+func (c W3CTraceContextCarrier) SetTraceState(state string) {
+	if state == "" {
+		http.Header(c).Del(TraceStateHeader)
+		return
+	}
+	http.Header(c).Set(TraceStateHeader, state)
+}
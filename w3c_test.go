@@ -0,0 +1,81 @@
+package opentracing
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	const valid = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	tp, err := ParseTraceParent(valid)
+	require.NoError(t, err)
+	assert.Equal(t, byte(0), tp.Version)
+	assert.Equal(t, byte(0x4b), tp.TraceID[0])
+	assert.Equal(t, byte(0xb7), tp.ParentID[7])
+	assert.True(t, tp.Sampled())
+	assert.Equal(t, valid, tp.String())
+
+	// 更高的版本允许额外的内容
+	tp, err = ParseTraceParent("cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra")
+	require.NoError(t, err)
+	assert.Equal(t, byte(0xcc), tp.Version)
+	assert.False(t, tp.Sampled())
+
+	for _, s := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0g",
+		"00_4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	} {
+		_, err := ParseTraceParent(s)
+		assert.True(t, IsSpanContextCorrupted(err), "%q: %v", s, err)
+	}
+}
+
+func TestW3CTraceContextCarrier(t *testing.T) {
+	h := http.Header{}
+	carrier := W3CTraceContextCarrier(h)
+	_, err := carrier.TraceParent()
+	assert.Equal(t, ErrSpanContextNotFound, err)
+
+	tp := TraceParent{TraceID: [16]byte{1}, ParentID: [8]byte{2}, Flags: TraceFlagSampled}
+	carrier.SetTraceParent(tp)
+	assert.Equal(t, "00-01000000000000000000000000000000-0200000000000000-01", h.Get("Traceparent"))
+	got, err := carrier.TraceParent()
+	require.NoError(t, err)
+	assert.Equal(t, tp, got)
+
+	h.Add("Traceparent", tp.String())
+	_, err = carrier.TraceParent()
+	assert.True(t, IsSpanContextCorrupted(err))
+
+	assert.Equal(t, "", carrier.TraceState())
+	h.Add("Tracestate", "a=1")
+	h.Add("Tracestate", "b=2")
+	assert.Equal(t, "a=1,b=2", carrier.TraceState())
+	carrier.SetTraceState("c=3")
+	assert.Equal(t, []string{"c=3"}, h[http.CanonicalHeaderKey("Tracestate")])
+	carrier.SetTraceState("")
+	assert.Empty(t, h[http.CanonicalHeaderKey("Tracestate")])
+}
+
+func TestTraceParentBasicConversion(t *testing.T) {
+	sc := BasicSpanContext{TraceIDHigh: 0x4bf92f3577b34da6, TraceID: 0xa3ce929d0e0e4736, SpanID: 0x00f067aa0ba902b7, Sampled: true}
+	tp := TraceParentFromBasic(sc)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", tp.String())
+	assert.Equal(t, sc, tp.ToBasic())
+
+	tp = TraceParentFromBasic(NewSpanContext(1, 2).WithBaggageItem("dropped", "x"))
+	assert.Equal(t, "00-00000000000000000000000000000001-0000000000000002-01", tp.String())
+	parsed, err := ParseTraceParent("00-00000000000000000000000000000001-0000000000000002-00")
+	require.NoError(t, err)
+	assert.Equal(t, BasicSpanContext{TraceID: 1, SpanID: 2}, parsed.ToBasic())
+}